@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_reception_alive;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID;
+
+CREATE INDEX IF NOT EXISTS idx_products_reception_alive
+    ON products (reception_id, date_time DESC)
+    WHERE deleted_at IS NULL;
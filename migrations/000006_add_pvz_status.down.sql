@@ -0,0 +1,3 @@
+ALTER TABLE pvz
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,4 @@
+ALTER TABLE pvz
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'inactive', 'archived')),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_pvz_coordinates;
+
+ALTER TABLE pvz
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS address;
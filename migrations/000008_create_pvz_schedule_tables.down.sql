@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pvz_calendar_exceptions;
+DROP TABLE IF EXISTS pvz_working_hours;
+DROP TABLE IF EXISTS pvz_schedules;
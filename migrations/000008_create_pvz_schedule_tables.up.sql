@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS pvz_schedules (
+    pvz_id UUID PRIMARY KEY REFERENCES pvz(id),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow',
+    override_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS pvz_working_hours (
+    pvz_id UUID NOT NULL REFERENCES pvz(id),
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    opens_minute SMALLINT NOT NULL CHECK (opens_minute BETWEEN 0 AND 1440),
+    closes_minute SMALLINT NOT NULL CHECK (closes_minute BETWEEN 0 AND 1440),
+    PRIMARY KEY (pvz_id, weekday),
+    CHECK (opens_minute < closes_minute)
+);
+
+CREATE TABLE IF NOT EXISTS pvz_calendar_exceptions (
+    pvz_id UUID NOT NULL REFERENCES pvz(id),
+    date DATE NOT NULL,
+    closed BOOLEAN NOT NULL,
+    opens_minute SMALLINT CHECK (opens_minute BETWEEN 0 AND 1440),
+    closes_minute SMALLINT CHECK (closes_minute BETWEEN 0 AND 1440),
+    comment VARCHAR(255),
+    PRIMARY KEY (pvz_id, date)
+);
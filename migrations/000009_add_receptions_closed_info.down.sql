@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_receptions_in_progress;
+
+ALTER TABLE receptions
+    DROP COLUMN IF EXISTS closed_by_system,
+    DROP COLUMN IF EXISTS closed_at;
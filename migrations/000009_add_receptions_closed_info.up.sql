@@ -0,0 +1,6 @@
+ALTER TABLE receptions
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS closed_by_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_receptions_in_progress
+    ON receptions (date_time) WHERE status = 'in_progress';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS pvz_settings (
+    pvz_id UUID PRIMARY KEY REFERENCES pvz(id),
+    max_products_per_reception INTEGER NOT NULL CHECK (max_products_per_reception >= 0),
+    allowed_product_types TEXT[] NOT NULL,
+    allow_empty_close BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
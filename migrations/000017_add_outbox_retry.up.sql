@@ -0,0 +1,8 @@
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending
+    ON outbox (pvz_id, id) WHERE published_at IS NULL AND failed_at IS NULL;
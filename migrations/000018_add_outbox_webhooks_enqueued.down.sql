@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outbox_webhooks_pending;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS webhooks_enqueued_at;
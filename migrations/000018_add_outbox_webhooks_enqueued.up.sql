@@ -0,0 +1,7 @@
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_enqueued_at TIMESTAMP;
+
+-- Опубликованные события уже разложены по подпискам прежним ретранслятором
+UPDATE outbox SET webhooks_enqueued_at = published_at WHERE published_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_webhooks_pending
+    ON outbox (id) WHERE webhooks_enqueued_at IS NULL;
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,17 +16,42 @@ import (
 	"avito-backend/src/internal/config"
 	"avito-backend/src/internal/delivery/http/handlers"
 	"avito-backend/src/internal/delivery/http/routes"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/health"
+	"avito-backend/src/internal/notify"
+	"avito-backend/src/internal/outbox"
+	"avito-backend/src/internal/rbac"
 	"avito-backend/src/internal/repository"
 	"avito-backend/src/internal/service"
+	"avito-backend/src/internal/stream"
+	"avito-backend/src/internal/webhook"
+	"avito-backend/src/internal/worker"
+	"avito-backend/src/pkg/cache"
 	"avito-backend/src/pkg/database"
 	"avito-backend/src/pkg/jwt"
 	"avito-backend/src/pkg/logger"
+	"avito-backend/src/pkg/metrics"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
-	logger.InitLogger()
+	printConfig := flag.Bool("print-config", false, "вывести итоговую конфигурацию со скрытыми секретами и выйти")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	logger.InitLogger(cfg.LogLevel, cfg.LogFormat, cfg.LogFile)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -33,32 +59,177 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
-	cfg, err := config.LoadConfig()
+	rolePermissions, err := rbac.LoadPolicy(cfg.RBACPolicyFile)
 	if err != nil {
-		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+		log.Fatalf("Ошибка загрузки политики доступа: %v", err)
 	}
+	models.SetRolePermissions(rolePermissions)
 
 	db, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
+	database.ConfigurePool(db, cfg.DBSQLPool())
 
 	migrationsPath := filepath.Join("migrations")
 	if err := database.RunMigrations(db, migrationsPath); err != nil {
 		log.Printf("Ошибка применения миграций: %v", err)
 	}
+	// Если миграции не применились, /readyz не пропустит трафик на этот экземпляр
+	expectedVersion, err := database.LatestMigrationVersion(migrationsPath)
+	if err != nil {
+		log.Fatalf("Ошибка чтения миграций: %v", err)
+	}
+	readiness := health.NewReadiness(db, expectedVersion, cfg.ReadinessTimeout)
+
+	// ПВЗ и пользователи работают через pgx с кешем подготовленных запросов, остальные репозитории - через database/sql.
+	// Пулы делят бюджет DB_MAX_OPEN_CONNS, см. DBPool и DBSQLPool
+	pool, err := database.OpenPgxPool(ctx, cfg.DatabaseURL, cfg.DBPool())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+	prometheus.MustRegister(metrics.NewPgxPoolCollector("primary", pool))
 
 	tokenManager := jwt.NewTokenManager(cfg.JWTSigningKey, cfg.JWTTokenDuration)
-	userRepo := repository.NewUserRepository(db)
-	authService := service.NewAuthService(userRepo, tokenManager)
+	if cfg.JWTKeysFile != "" {
+		keySet, err := jwt.LoadKeySet(cfg.JWTKeysFile, cfg.JWTKeyGracePeriod)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки ключей JWT: %v", err)
+		}
+		tokenManager = jwt.NewKeySetTokenManager(keySet, cfg.JWTTokenDuration)
+	}
+	userRepo := repository.NewUserRepository(pool)
+	auditRepo := repository.NewAuditRepository(db)
+	loginProtection := service.LoginProtection{
+		MaxFailuresPerEmail: cfg.LoginMaxFailuresPerEmail,
+		MaxFailuresPerIP:    cfg.LoginMaxFailuresPerIP,
+		FailureWindow:       cfg.LoginFailureWindow,
+		LockoutBase:         cfg.LoginLockoutBase,
+		LockoutMax:          cfg.LoginLockoutMax,
+	}
+	passwordPolicy, err := service.NewPasswordPolicy(cfg.PasswordMinLength, cfg.PasswordRequiredClasses, cfg.PasswordDenylistFile)
+	if err != nil {
+		log.Fatalf("Неверные настройки парольной политики: %v", err)
+	}
+	notifier, err := notify.NewNotifier(cfg.Notifier, cfg.NotifierFilePath)
+	if err != nil {
+		log.Fatalf("Неверные настройки отправки уведомлений: %v", err)
+	}
+	authService := service.NewAuthService(userRepo, tokenManager,
+		service.WithLoginProtection(repository.NewLoginAttemptsRepository(db), auditRepo, loginProtection),
+		service.WithPasswordPolicy(passwordPolicy),
+		service.WithPasswordReset(repository.NewPasswordResetRepository(db), notifier, cfg.PasswordResetTokenTTL))
 	authHandler := handlers.NewAuthHandler(authService)
+	userHandler := handlers.NewUserHandler(service.NewUserService(userRepo, auditRepo))
+
+	pvzRepo := repository.NewPVZRepository(pool)
+	defaultSettings, err := service.NewDefaultSettings(cfg.DefaultMaxProductsPerReception, cfg.DefaultAllowedProductTypes, cfg.DefaultAllowEmptyClose)
+	if err != nil {
+		log.Fatalf("Неверные настройки ПВЗ по умолчанию: %v", err)
+	}
+	pvzOpts := []service.PVZServiceOption{service.WithDefaultSettings(defaultSettings)}
+	if len(cfg.DBReplicaURLs) > 0 {
+		replicas := make([]database.Replica, 0, len(cfg.DBReplicaURLs))
+		for i, replicaURL := range cfg.DBReplicaURLs {
+			replicaPool, err := database.NewPgxPool(replicaURL, cfg.DBReplicaPool())
+			if err != nil {
+				log.Fatalf("Ошибка настройки реплики %d: %v", i+1, err)
+			}
+			defer replicaPool.Close()
 
-	pvzRepo := repository.NewPVZRepository(db)
-	pvzService := service.NewPVZService(pvzRepo)
+			name := fmt.Sprintf("replica-%d", i+1)
+			prometheus.MustRegister(metrics.NewPgxPoolCollector(name, replicaPool))
+			replicas = append(replicas, database.Replica{Name: name, DB: replicaPool})
+		}
+
+		replicaRouter := database.NewReplicaRouter(pool, replicas, cfg.DBReplicaMaxLag)
+		go replicaRouter.Run(ctx, cfg.DBReplicaCheckInterval)
+		pvzOpts = append(pvzOpts, service.WithReadReplica(pvzRepo.WithReplicas(replicaRouter)))
+	}
+	pvzService := service.NewPVZService(pvzRepo, pvzOpts...)
+	var pvzCache *service.CachedPVZService
+	if cfg.PVZCacheBackend == config.PVZCacheMemory {
+		cacheConfig := service.PVZCacheConfig{
+			TTL:      cfg.PVZCacheTTL,
+			StaleTTL: cfg.PVZCacheStaleTTL,
+		}
+		// Список читается с реплик, отставание которых не больше DB_REPLICA_MAX_LAG
+		if len(cfg.DBReplicaURLs) > 0 {
+			cacheConfig.ReplicaLag = cfg.DBReplicaMaxLag
+		}
+		pvzCache = service.NewCachedPVZService(pvzService, cache.NewLRU(cfg.PVZCacheSize), cacheConfig)
+		pvzService = pvzCache
+	}
 	pvzHandler := handlers.NewPVZHandler(pvzService)
 
-	router := routes.NewRouter(authHandler, pvzHandler, tokenManager)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo, pvzRepo)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	outboxRepo := repository.NewOutboxRepository(db)
+	eventHub := stream.NewHub(outboxRepo, cfg.EventStreamPollInterval, cfg.EventStreamGapTimeout)
+	eventsHandler := handlers.NewEventsHandler(eventHub, outboxRepo, cfg.EventStreamHeartbeatInterval)
+
+	rateLimits := routes.RateLimits{
+		Auth:      cfg.RateLimitAuth,
+		Moderator: cfg.RateLimitModerator,
+		Employee:  cfg.RateLimitEmployee,
+		Shared:    cfg.RateLimitShared,
+	}
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), pvzRepo, auditRepo)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+
+	jwksHandler := handlers.NewJWKSHandler(tokenManager)
+
+	routerOpts := []routes.RouterOption{routes.WithCORSOrigins(cfg.CORSAllowedOrigins), routes.WithReadiness(readiness)}
+	if cfg.IsProduction() {
+		routerOpts = append(routerOpts, routes.WithoutDummyLogin())
+	}
+	router := routes.NewRouter(authHandler, pvzHandler, webhookHandler, eventsHandler, userHandler, apiKeyHandler, jwksHandler,
+		tokenManager, authService, apiKeyService, rateLimits, routerOpts...)
+
+	workerCtx, workerCancel := context.WithCancel(ctx)
+	// Остановка хаба закрывает открытые потоки событий, иначе Shutdown ждал бы их до таймаута
+	go eventHub.Run(workerCtx)
+	if pvzCache != nil {
+		if cfg.EventStreamPollInterval <= 0 {
+			log.Printf("EVENT_STREAM_POLL_INTERVAL=0: изменения с других экземпляров попадут в кеш ПВЗ только через PVZ_CACHE_TTL")
+		}
+		go worker.NewPVZCacheInvalidator(eventHub, pvzCache).Run(workerCtx)
+	}
+
+	staleReceptionCloser := worker.NewStaleReceptionCloser(pvzService, cfg.StaleReceptionTimeout, cfg.StaleReceptionCheckInterval)
+	go staleReceptionCloser.Run(workerCtx)
+
+	outboxPublisher, err := outbox.NewPublisher(cfg.OutboxPublisher, cfg.OutboxFilePath, cfg.OutboxHTTPURL, cfg.OutboxHTTPTimeout)
+	if err != nil {
+		log.Fatalf("Неверные настройки доставки событий: %v", err)
+	}
+	outboxRetryPolicy := webhook.RetryPolicy{
+		MaxAttempts: cfg.OutboxMaxAttempts,
+		BaseDelay:   cfg.OutboxRetryBaseDelay,
+		MaxDelay:    cfg.OutboxRetryMaxDelay,
+	}
+	// События пачки отправляются по очереди, поэтому аренда рассчитана на таймаут каждого из них
+	outboxLease := cfg.OutboxHTTPTimeout*time.Duration(cfg.OutboxBatchSize) + time.Minute
+	outboxRelay := worker.NewOutboxRelay(outboxRepo, outboxPublisher, outboxRetryPolicy,
+		cfg.OutboxPollInterval, cfg.OutboxBatchSize, outboxLease)
+	go outboxRelay.Run(workerCtx)
+
+	// Вебхуки читают outbox сами, чтобы не зависеть от доставки событий публикатору
+	webhookFanOut := worker.NewWebhookFanOut(webhookRepo, cfg.WebhookFanOutInterval, cfg.OutboxBatchSize)
+	go webhookFanOut.Run(workerCtx)
+
+	retryPolicy := webhook.RetryPolicy{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseDelay:   cfg.WebhookRetryBaseDelay,
+		MaxDelay:    cfg.WebhookRetryMaxDelay,
+	}
+	webhookDispatcher := worker.NewWebhookDispatcher(webhookRepo, webhook.NewSender(cfg.WebhookTimeout), retryPolicy,
+		cfg.WebhookDispatchInterval, cfg.WebhookBatchSize, cfg.WebhookTimeout+time.Minute)
+	go webhookDispatcher.Run(workerCtx)
 
 	metricsServer := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.MetricsPort),
@@ -66,28 +237,44 @@ func main() {
 	}
 
 	mainServer := &http.Server{
-		Addr:    fmt.Sprintf(":%s", cfg.ServerPort),
-		Handler: router.InitRoutes(),
+		Addr:              fmt.Sprintf(":%s", cfg.ServerPort),
+		Handler:           router.InitRoutes(),
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
 	}
 
 	go func() {
-		log.Printf("Сервер метрик запущен на порту :9000")
+		log.Printf("Сервер метрик запущен на порту :%s", cfg.MetricsPort)
 		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Printf("Ошибка сервера метрик: %v", err)
 		}
 	}()
 
 	go func() {
-		log.Printf("Основной сервер запущен на порту %s", cfg.ServerPort)
-		if err := mainServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("Основной сервер запущен на порту %s (APP_ENV=%s)", cfg.ServerPort, cfg.AppEnv)
+		serve := mainServer.ListenAndServe
+		if cfg.TLSCertFile != "" {
+			serve = func() error { return mainServer.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile) }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			log.Printf("Ошибка основного сервера: %v", err)
 		}
 	}()
 
 	<-quit
+	readiness.Drain()
+	log.Printf("Получен сигнал остановки, ждем %s, пока балансировщик выведет экземпляр из ротации", cfg.ShutdownDrainDelay)
+	select {
+	case <-time.After(cfg.ShutdownDrainDelay):
+	case <-quit:
+		// Повторный сигнал - остановиться без ожидания
+	}
+
 	log.Println("Начинаем завершение работы серверов...")
+	workerCancel()
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 5*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
 	defer shutdownCancel()
 
 	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
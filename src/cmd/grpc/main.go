@@ -1,36 +1,113 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"net"
+	"os"
 
 	"avito-backend/src/internal/config"
 	grpcdelivery "avito-backend/src/internal/delivery/grpc"
 	"avito-backend/src/internal/delivery/grpc/pb"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/rbac"
 	"avito-backend/src/internal/repository"
 	"avito-backend/src/internal/service"
+	"avito-backend/src/pkg/database"
+	"avito-backend/src/pkg/jwt"
+	"avito-backend/src/pkg/ratelimit"
 
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "вывести итоговую конфигурацию со скрытыми секретами и выйти")
+	checkConfig := flag.Bool("check-config", false, "проверить конфигурацию и файл политики доступа и выйти")
+	flag.Parse()
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
 	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	rolePermissions, err := rbac.LoadPolicy(cfg.RBACPolicyFile)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки политики доступа: %v", err)
+	}
+	models.SetRolePermissions(rolePermissions)
+	if *checkConfig {
+		return
+	}
 
 	db, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("Ошибка подключения к базе данных: %v", err)
 	}
 	defer db.Close()
+	database.ConfigurePool(db, cfg.DBSQLPool())
 
-	pvzRepo := repository.NewPVZRepository(db)
-	pvzService := service.NewPVZService(pvzRepo)
+	pool, err := database.OpenPgxPool(context.Background(), cfg.DatabaseURL, cfg.DBPool())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
 
-	grpcServer := grpc.NewServer()
+	pvzRepo := repository.NewPVZRepository(pool)
+	var pvzOpts []service.PVZServiceOption
+	if len(cfg.DBReplicaURLs) > 0 {
+		replicas := make([]database.Replica, 0, len(cfg.DBReplicaURLs))
+		for i, replicaURL := range cfg.DBReplicaURLs {
+			replicaPool, err := database.NewPgxPool(replicaURL, cfg.DBReplicaPool())
+			if err != nil {
+				log.Fatalf("Ошибка настройки реплики %d: %v", i+1, err)
+			}
+			defer replicaPool.Close()
+			replicas = append(replicas, database.Replica{Name: fmt.Sprintf("replica-%d", i+1), DB: replicaPool})
+		}
+
+		replicaRouter := database.NewReplicaRouter(pool, replicas, cfg.DBReplicaMaxLag)
+		go replicaRouter.Run(context.Background(), cfg.DBReplicaCheckInterval)
+		pvzOpts = append(pvzOpts, service.WithReadReplica(pvzRepo.WithReplicas(replicaRouter)))
+	}
+	pvzService := service.NewPVZService(pvzRepo, pvzOpts...)
+
+	tokenManager := jwt.NewTokenManager(cfg.JWTSigningKey, cfg.JWTTokenDuration)
+	if cfg.JWTKeysFile != "" {
+		keySet, err := jwt.LoadKeySet(cfg.JWTKeysFile, cfg.JWTKeyGracePeriod)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки ключей JWT: %v", err)
+		}
+		tokenManager = jwt.NewKeySetTokenManager(keySet, cfg.JWTTokenDuration)
+	}
+	authService := service.NewAuthService(repository.NewUserRepository(pool), tokenManager)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), pvzRepo, nil)
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			grpcdelivery.ErrorInterceptor(),
+			grpcdelivery.RateLimitInterceptor(ratelimit.NewLimiter(cfg.RateLimitGRPC)),
+			grpcdelivery.AuthInterceptor(tokenManager, authService, apiKeyService, grpcdelivery.MethodPermissions),
+		),
+	}
+	if cfg.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки TLS-сертификата: %v", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	pb.RegisterPVZServiceServer(grpcServer, grpcdelivery.NewPVZGrpcServer(pvzService))
 
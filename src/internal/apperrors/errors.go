@@ -1,22 +1,224 @@
 package apperrors
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"avito-backend/src/internal/i18n"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Ошибка приложения со стабильным машиночитаемым кодом. По коду ошибка одинаково переводится
+// в HTTP-ответ и статус gRPC, а сообщение для клиента берется из каталога i18n по MessageKey
+type Error struct {
+	Code       string
+	MessageKey string
+	HTTPStatus int
+	GRPCCode   codes.Code
+	Details    map[string]any
+	cause      error
+}
+
+// Все ошибки, созданные через New, для проверки полноты каталогов сообщений
+var registry []*Error
+
+// Ключ сообщения совпадает с кодом ошибки
+func New(code string, httpStatus int, grpcCode codes.Code) *Error {
+	err := &Error{Code: code, MessageKey: code, HTTPStatus: httpStatus, GRPCCode: grpcCode}
+	registry = append(registry, err)
+	return err
+}
+
+// Ошибки, объявленные в пакете
+func All() []*Error {
+	return append([]*Error(nil), registry...)
+}
+
+// Сообщение на языке по умолчанию; используется в логах
+func (e *Error) Error() string {
+	return e.Message(i18n.DefaultLanguage)
+}
+
+// Сообщение для клиента на языке lang; Details подставляются в текст каталога
+func (e *Error) Message(lang string) string {
+	return i18n.Translate(lang, e.MessageKey, e.Details)
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Ошибки с одинаковым кодом считаются одной ошибкой, поэтому errors.Is находит
+// исходную ошибку и после WithMessageKey, WithDetails и Wrap
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Копия ошибки с уточненным сообщением для клиента; key — ключ каталога i18n
+func (e *Error) WithMessageKey(key string) *Error {
+	err := *e
+	err.MessageKey = key
+	return &err
+}
+
+// Копия ошибки с дополнительными сведениями; существующие ключи перезаписываются
+func (e *Error) WithDetails(details map[string]any) *Error {
+	err := *e
+	err.Details = make(map[string]any, len(e.Details)+len(details))
+	for key, value := range e.Details {
+		err.Details[key] = value
+	}
+	for key, value := range details {
+		err.Details[key] = value
+	}
+	return &err
+}
+
+// Копия ошибки с другим HTTP-статусом, например для маршрута, где swagger объявляет иной ответ
+func (e *Error) WithHTTPStatus(status int) *Error {
+	err := *e
+	err.HTTPStatus = status
+	return &err
+}
+
+// Копия ошибки с исходной причиной; причина пишется в лог, но не отдается клиенту
+func (e *Error) Wrap(cause error) *Error {
+	err := *e
+	err.cause = cause
+	return &err
+}
+
+// Приводит любую ошибку к ошибке приложения. Неизвестные ошибки становятся ErrInternal
+// с исходной ошибкой в качестве причины
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
 
 var (
-	ErrUserAlreadyExists      = errors.New("пользователь уже существует")
-	ErrInvalidCredentials     = errors.New("неверные учетные данные")
-	ErrInvalidRole            = errors.New("недопустимая роль")
-	ErrValidationFailed       = errors.New("неверные данные")
-	ErrInvalidCity            = errors.New("недопустимый город")
-	ErrActiveReceptionExists  = errors.New("уже есть активная приемка")
-	ErrNoActiveReception      = errors.New("нет активной приемки")
-	ErrInvalidProductType     = errors.New("недопустимый тип товара")
-	ErrPVZNotFound            = errors.New("ПВЗ не найден")
-	ErrReceptionClosed        = errors.New("приемка закрыта")
-	ErrProductNotLast         = errors.New("можно удалить только последний добавленный товар")
-	ErrNoProductsToDelete     = errors.New("нет товаров для удаления")
-	ErrNoProductsInReception  = errors.New("нет товаров в приемке")
-	ErrReceptionAlreadyClosed = errors.New("приемка уже закрыта")
-	ErrInvalidDateRange       = errors.New("неверный диапазон дат")
-	ErrInvalidPagination      = errors.New("неверные параметры пагинации")
+	ErrInvalidRequest     = New("invalid_request", http.StatusBadRequest, codes.InvalidArgument)
+	ErrMissingToken       = New("missing_token", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrInvalidToken       = New("invalid_token", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrForbidden          = New("forbidden", http.StatusForbidden, codes.PermissionDenied)
+	ErrPreconditionFailed = New("precondition_failed", http.StatusPreconditionFailed, codes.FailedPrecondition)
+	ErrTooManyRequests    = New("too_many_requests", http.StatusTooManyRequests, codes.ResourceExhausted)
+	ErrInternal           = New("internal", http.StatusInternalServerError, codes.Internal)
 )
+
+var (
+	ErrUserAlreadyExists         = New("user_already_exists", http.StatusBadRequest, codes.AlreadyExists)
+	ErrInvalidCredentials        = New("invalid_credentials", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrInvalidRole               = New("invalid_role", http.StatusBadRequest, codes.InvalidArgument)
+	ErrValidationFailed          = New("validation_failed", http.StatusBadRequest, codes.InvalidArgument)
+	ErrInvalidCity               = New("invalid_city", http.StatusBadRequest, codes.InvalidArgument)
+	ErrActiveReceptionExists     = New("active_reception_exists", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrNoActiveReception         = New("no_active_reception", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrInvalidProductType        = New("invalid_product_type", http.StatusBadRequest, codes.InvalidArgument)
+	ErrPVZNotFound               = New("pvz_not_found", http.StatusNotFound, codes.NotFound)
+	ErrReceptionClosed           = New("reception_closed", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrProductNotLast            = New("product_not_last", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrNoProductsToDelete        = New("no_products_to_delete", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrNoProductsInReception     = New("no_products_in_reception", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrReceptionAlreadyClosed    = New("reception_already_closed", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrInvalidDateRange          = New("invalid_date_range", http.StatusBadRequest, codes.InvalidArgument)
+	ErrInvalidPagination         = New("invalid_pagination", http.StatusBadRequest, codes.InvalidArgument)
+	ErrPVZInactive               = New("pvz_inactive", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrPVZArchived               = New("pvz_archived", http.StatusConflict, codes.FailedPrecondition)
+	ErrPVZHasActiveReception     = New("pvz_has_active_reception", http.StatusConflict, codes.FailedPrecondition)
+	ErrInvalidCoordinates        = New("invalid_coordinates", http.StatusBadRequest, codes.InvalidArgument)
+	ErrInvalidRadius             = New("invalid_radius", http.StatusBadRequest, codes.InvalidArgument)
+	ErrPVZDuplicateLocation      = New("pvz_duplicate_location", http.StatusConflict, codes.AlreadyExists)
+	ErrPVZClosed                 = New("pvz_closed", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrInvalidSchedule           = New("invalid_schedule", http.StatusBadRequest, codes.InvalidArgument)
+	ErrCalendarExceptionNotFound = New("calendar_exception_not_found", http.StatusNotFound, codes.NotFound)
+	ErrProductTypeNotAllowed     = New("product_type_not_allowed", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrReceptionProductLimit     = New("reception_product_limit", http.StatusBadRequest, codes.FailedPrecondition)
+	ErrWebhookNotFound           = New("webhook_not_found", http.StatusNotFound, codes.NotFound)
+	ErrInvalidWebhook            = New("invalid_webhook", http.StatusBadRequest, codes.InvalidArgument)
+	ErrAccountLocked             = New("account_locked", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrWeakPassword              = New("weak_password", http.StatusBadRequest, codes.InvalidArgument)
+	ErrInvalidCurrentPassword    = New("invalid_current_password", http.StatusBadRequest, codes.InvalidArgument)
+	ErrTokenRevoked              = New("token_revoked", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrInvalidResetToken         = New("invalid_reset_token", http.StatusBadRequest, codes.InvalidArgument)
+	ErrUserNotFound              = New("user_not_found", http.StatusNotFound, codes.NotFound)
+	ErrAccountDisabled           = New("account_disabled", http.StatusForbidden, codes.PermissionDenied)
+	ErrSelfModification          = New("self_modification", http.StatusConflict, codes.FailedPrecondition)
+	ErrInvalidAPIKey             = New("invalid_api_key", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrAPIKeyNotFound            = New("api_key_not_found", http.StatusNotFound, codes.NotFound)
+	ErrInvalidAPIKeyScope        = New("invalid_api_key_scope", http.StatusBadRequest, codes.InvalidArgument)
+)
+
+// Уточненные ключи сообщений для WithMessageKey; код ошибки при этом не меняется
+const (
+	KeyInvalidRequestPVZID               = "invalid_request.pvz_id"
+	KeyInvalidRequestPVZIDRequired       = "invalid_request.pvz_id_required"
+	KeyInvalidRequestUserID              = "invalid_request.user_id"
+	KeyInvalidRequestWebhookID           = "invalid_request.webhook_id"
+	KeyInvalidRequestAPIKeyID            = "invalid_request.api_key_id"
+	KeyInvalidRequestMissingFields       = "invalid_request.missing_fields"
+	KeyInvalidRequestEmail               = "invalid_request.email"
+	KeyInvalidRequestIP                  = "invalid_request.ip"
+	KeyInvalidRequestCityRequired        = "invalid_request.city_required"
+	KeyInvalidRequestProductTypeRequired = "invalid_request.product_type_required"
+	KeyInvalidRequestStartDate           = "invalid_request.start_date"
+	KeyInvalidRequestEndDate             = "invalid_request.end_date"
+	KeyInvalidRequestPage                = "invalid_request.page"
+	KeyInvalidRequestPageSize            = "invalid_request.page_size"
+	KeyInvalidRequestIncludeArchived     = "invalid_request.include_archived"
+	KeyInvalidRequestIncludeDeleted      = "invalid_request.include_deleted"
+	KeyInvalidRequestLastEventID         = "invalid_request.last_event_id"
+	KeyInvalidRequestUserUpdate          = "invalid_request.user_update"
+	KeyInvalidTokenFormat                = "invalid_token.format"
+	KeyInvalidCredentialsMissing         = "invalid_credentials.missing"
+	KeyValidationFailedUnlockTarget      = "validation_failed.unlock_target"
+	KeyWeakPasswordTooLong               = "weak_password.too_long"
+	KeyWeakPasswordTooShort              = "weak_password.too_short"
+	KeyWeakPasswordLower                 = "weak_password.lower"
+	KeyWeakPasswordUpper                 = "weak_password.upper"
+	KeyWeakPasswordDigit                 = "weak_password.digit"
+	KeyWeakPasswordSpecial               = "weak_password.special"
+	KeyWeakPasswordCommon                = "weak_password.common"
+)
+
+// Все ключи сообщений ошибок: коды из New и уточненные ключи выше
+func MessageKeys() []string {
+	keys := make([]string, 0, len(registry))
+	for _, err := range registry {
+		keys = append(keys, err.MessageKey)
+	}
+	return append(keys,
+		KeyInvalidRequestPVZID,
+		KeyInvalidRequestPVZIDRequired,
+		KeyInvalidRequestUserID,
+		KeyInvalidRequestWebhookID,
+		KeyInvalidRequestAPIKeyID,
+		KeyInvalidRequestMissingFields,
+		KeyInvalidRequestEmail,
+		KeyInvalidRequestIP,
+		KeyInvalidRequestCityRequired,
+		KeyInvalidRequestProductTypeRequired,
+		KeyInvalidRequestStartDate,
+		KeyInvalidRequestEndDate,
+		KeyInvalidRequestPage,
+		KeyInvalidRequestPageSize,
+		KeyInvalidRequestIncludeArchived,
+		KeyInvalidRequestIncludeDeleted,
+		KeyInvalidRequestLastEventID,
+		KeyInvalidRequestUserUpdate,
+		KeyInvalidTokenFormat,
+		KeyInvalidCredentialsMissing,
+		KeyValidationFailedUnlockTarget,
+		KeyWeakPasswordTooLong,
+		KeyWeakPasswordTooShort,
+		KeyWeakPasswordLower,
+		KeyWeakPasswordUpper,
+		KeyWeakPasswordDigit,
+		KeyWeakPasswordSpecial,
+		KeyWeakPasswordCommon,
+	)
+}
@@ -0,0 +1,52 @@
+package apperrors_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/i18n"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("connection refused")
+	derived := apperrors.ErrWeakPassword.
+		WithMessageKey(apperrors.KeyWeakPasswordTooShort).
+		WithDetails(map[string]any{"minLength": 8}).
+		Wrap(cause)
+
+	assert.ErrorIs(t, derived, apperrors.ErrWeakPassword)
+	assert.ErrorIs(t, derived, cause)
+	assert.NotErrorIs(t, derived, apperrors.ErrInvalidRequest)
+	assert.Equal(t, "weak_password", apperrors.ErrWeakPassword.MessageKey, "исходная ошибка не меняется")
+	assert.Nil(t, apperrors.ErrWeakPassword.Details)
+}
+
+func TestError_Message(t *testing.T) {
+	err := apperrors.ErrWeakPassword.WithMessageKey(apperrors.KeyWeakPasswordTooShort).WithDetails(map[string]any{"minLength": 8})
+
+	assert.Equal(t, "Пароль не соответствует требованиям: пароль короче 8 символов", err.Error())
+	assert.Equal(t, "Password does not meet the requirements: password is shorter than 8 characters", err.Message("en"))
+	assert.Equal(t, "ПВЗ не найден", apperrors.ErrPVZNotFound.Message("de"), "неизвестный язык получает язык по умолчанию")
+}
+
+func TestFrom(t *testing.T) {
+	assert.Same(t, apperrors.ErrPVZNotFound, apperrors.From(fmt.Errorf("загрузка ПВЗ: %w", apperrors.ErrPVZNotFound)))
+
+	cause := errors.New("connection refused")
+	appErr := apperrors.From(cause)
+	assert.Equal(t, "internal", appErr.Code)
+	assert.ErrorIs(t, appErr, cause)
+}
+
+func TestAll_Translated(t *testing.T) {
+	assert.NotEmpty(t, apperrors.All())
+	for _, lang := range i18n.Languages() {
+		for _, key := range apperrors.MessageKeys() {
+			_, ok := i18n.Lookup(lang, key)
+			assert.True(t, ok, "нет перевода %s для языка %s", key, lang)
+		}
+	}
+}
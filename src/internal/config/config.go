@@ -1,45 +1,448 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"avito-backend/src/pkg/database"
+	"avito-backend/src/pkg/ratelimit"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	EnvDev     = "dev"
+	EnvStaging = "staging"
+	EnvProd    = "prod"
+
+	PVZCacheMemory = "memory"
+	PVZCacheNone   = "none"
+
+	defaultJWTSigningKey    = "default-secret-key"
+	defaultPostgresPassword = "wasted"
+)
+
 type Config struct {
+	// Окружение: dev, staging или prod. В prod запрещены значения по умолчанию для секретов
+	// и обязателен TLS
+	AppEnv string
+
 	ServerPort       string
 	JWTSigningKey    string
-	JWTTokenDuration string
+	JWTTokenDuration time.Duration
 	DatabaseURL      string
 	MetricsPort      string
+
+	// Соединения экземпляра с primary. DBMaxOpenConns - общий бюджет: DBSQLMaxOpenConns из него
+	// получает пул database/sql, остальное - пул pgx. 0 в DBMaxOpenConns снимает ограничение
+	// у database/sql и оставляет pgx размер по умолчанию
+	DBMaxOpenConns    int
+	DBSQLMaxOpenConns int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBConnMaxIdleTime time.Duration
+	// Только для пула pgx: минимум открытых соединений и размер кеша подготовленных запросов на соединении
+	DBMinConns               int
+	DBStatementCacheCapacity int
+
+	// Реплики для тяжелого чтения; реплика с отставанием больше DBReplicaMaxLag не используется,
+	// пока не догонит primary. DBReplicaMaxOpenConns - размер пула pgx к каждой реплике
+	DBReplicaURLs          []string
+	DBReplicaMaxOpenConns  int
+	DBReplicaMaxLag        time.Duration
+	DBReplicaCheckInterval time.Duration
+
+	// Таймауты HTTP-сервера. Таймаута записи нет, иначе обрывались бы потоки SSE
+	HTTPReadHeaderTimeout time.Duration
+	HTTPReadTimeout       time.Duration
+	HTTPIdleTimeout       time.Duration
+	ShutdownTimeout       time.Duration
+
+	// Сколько /readyz отвечает ошибкой после SIGTERM до остановки сервера
+	ShutdownDrainDelay time.Duration
+	ReadinessTimeout   time.Duration
+
+	CORSAllowedOrigins []string
+
+	LogLevel  slog.Level
+	LogFormat string
+	LogFile   string
+
+	// Если файл ключей задан, токены подписываются RS256/EdDSA, иначе общим секретом HS256
+	JWTKeysFile       string
+	JWTKeyGracePeriod time.Duration
+
+	// Если заданы, HTTP и gRPC серверы принимают только TLS-соединения
+	TLSCertFile string
+	TLSKeyFile  string
+
+	StaleReceptionTimeout       time.Duration
+	StaleReceptionCheckInterval time.Duration
+
+	DefaultMaxProductsPerReception int
+	DefaultAllowedProductTypes     []string
+	DefaultAllowEmptyClose         bool
+
+	OutboxPublisher      string
+	OutboxFilePath       string
+	OutboxHTTPURL        string
+	OutboxHTTPTimeout    time.Duration
+	OutboxPollInterval   time.Duration
+	OutboxBatchSize      int
+	OutboxMaxAttempts    int
+	OutboxRetryBaseDelay time.Duration
+	OutboxRetryMaxDelay  time.Duration
+
+	WebhookFanOutInterval   time.Duration
+	WebhookDispatchInterval time.Duration
+	WebhookBatchSize        int
+	WebhookTimeout          time.Duration
+	WebhookMaxAttempts      int
+	WebhookRetryBaseDelay   time.Duration
+	WebhookRetryMaxDelay    time.Duration
+
+	EventStreamPollInterval      time.Duration
+	EventStreamHeartbeatInterval time.Duration
+	EventStreamGapTimeout        time.Duration
+
+	// Кеш выдачи GET /pvz: memory - LRU в памяти процесса, none - без кеша. После PVZCacheTTL
+	// или инвалидации страница отдается еще PVZCacheStaleTTL, но только если БД недоступна
+	PVZCacheBackend  string
+	PVZCacheSize     int
+	PVZCacheTTL      time.Duration
+	PVZCacheStaleTTL time.Duration
+
+	RateLimitAuth      ratelimit.Rule
+	RateLimitModerator ratelimit.Rule
+	RateLimitEmployee  ratelimit.Rule
+	RateLimitShared    ratelimit.Rule
+	RateLimitGRPC      ratelimit.Rule
+
+	LoginMaxFailuresPerEmail int
+	LoginMaxFailuresPerIP    int
+	LoginFailureWindow       time.Duration
+	LoginLockoutBase         time.Duration
+	LoginLockoutMax          time.Duration
+
+	PasswordMinLength       int
+	PasswordRequiredClasses []string
+	PasswordDenylistFile    string
+	PasswordResetTokenTTL   time.Duration
+
+	Notifier         string
+	NotifierFilePath string
+
+	RBACPolicyFile string
+
+	settings map[string]Setting
 }
 
+// Загружает настройки по возрастанию приоритета: значения по умолчанию, YAML-файл из CONFIG_FILE,
+// переменные окружения (в том числе из необязательного .env) и файлы секретов KEY_FILE
 func LoadConfig() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("error loading .env file: %w", err)
 	}
 
-	dbURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		getEnvVar("POSTGRES_USER", "myuser"),
-		getEnvVar("POSTGRES_PASSWORD", "wasted"),
-		getEnvVar("POSTGRES_HOST", "localhost"),
-		getEnvVar("POSTGRES_PORT", "5432"),
-		getEnvVar("POSTGRES_DB", "Avito-backend"),
-	)
+	var file map[string]string
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		var err error
+		if file, err = loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	l := newLoader(file)
+
+	dbURL := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(l.string("POSTGRES_USER", "myuser"), l.string("POSTGRES_PASSWORD", defaultPostgresPassword)),
+		Host:     net.JoinHostPort(l.string("POSTGRES_HOST", "localhost"), l.string("POSTGRES_PORT", "5432")),
+		Path:     "/" + l.string("POSTGRES_DB", "Avito-backend"),
+		RawQuery: "sslmode=" + url.QueryEscape(l.string("POSTGRES_SSLMODE", "disable")),
+	}).String()
 
-	return &Config{
-		ServerPort:       getEnvVar("SERVER_PORT", "8080"),
-		JWTSigningKey:    getEnvVar("JWT_SIGNING_KEY", "default-secret-key"),
-		JWTTokenDuration: getEnvVar("JWT_TOKEN_DURATION", "24h"),
-		MetricsPort:      getEnvVar("METRICS_PORT", "9000"),
+	cfg := &Config{
+		AppEnv: l.string("APP_ENV", EnvDev),
+
+		ServerPort:       l.string("SERVER_PORT", "8080"),
+		JWTSigningKey:    l.string("JWT_SIGNING_KEY", defaultJWTSigningKey),
+		JWTTokenDuration: l.duration("JWT_TOKEN_DURATION", 24*time.Hour),
+		MetricsPort:      l.string("METRICS_PORT", "9000"),
 		DatabaseURL:      dbURL,
-	}, nil
+
+		DBMaxOpenConns:    l.int("DB_MAX_OPEN_CONNS", 25),
+		DBSQLMaxOpenConns: l.int("DB_SQL_MAX_OPEN_CONNS", 5),
+		DBMaxIdleConns:    l.int("DB_MAX_IDLE_CONNS", 25),
+		DBConnMaxLifetime: l.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime: l.duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+
+		DBMinConns:               l.int("DB_MIN_CONNS", 0),
+		DBStatementCacheCapacity: l.int("DB_STATEMENT_CACHE_CAPACITY", 512),
+
+		DBReplicaURLs:          l.list("DB_REPLICA_URLS", nil),
+		DBReplicaMaxOpenConns:  l.int("DB_REPLICA_MAX_OPEN_CONNS", 10),
+		DBReplicaMaxLag:        l.duration("DB_REPLICA_MAX_LAG", 5*time.Second),
+		DBReplicaCheckInterval: l.duration("DB_REPLICA_CHECK_INTERVAL", 5*time.Second),
+
+		HTTPReadHeaderTimeout: l.duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		HTTPReadTimeout:       l.duration("HTTP_READ_TIMEOUT", 30*time.Second),
+		HTTPIdleTimeout:       l.duration("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout:       l.duration("SHUTDOWN_TIMEOUT", 5*time.Second),
+
+		ShutdownDrainDelay: l.duration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+		ReadinessTimeout:   l.duration("READINESS_TIMEOUT", 2*time.Second),
+
+		CORSAllowedOrigins: l.list("CORS_ALLOWED_ORIGINS", []string{"*"}),
+
+		LogLevel:  l.logLevel("LOG_LEVEL", slog.LevelDebug),
+		LogFormat: l.string("LOG_FORMAT", "json"),
+		LogFile:   l.string("LOG_FILE", "logs/app.log"),
+
+		JWTKeysFile:       l.string("JWT_KEYS_FILE", ""),
+		JWTKeyGracePeriod: l.duration("JWT_KEY_GRACE_PERIOD", 24*time.Hour),
+
+		TLSCertFile: l.string("TLS_CERT_FILE", ""),
+		TLSKeyFile:  l.string("TLS_KEY_FILE", ""),
+
+		StaleReceptionTimeout:       l.duration("STALE_RECEPTION_TIMEOUT", 4*time.Hour),
+		StaleReceptionCheckInterval: l.duration("STALE_RECEPTION_CHECK_INTERVAL", 5*time.Minute),
+
+		DefaultMaxProductsPerReception: l.int("DEFAULT_MAX_PRODUCTS_PER_RECEPTION", 1000),
+		DefaultAllowedProductTypes:     l.list("DEFAULT_ALLOWED_PRODUCT_TYPES", []string{"электроника", "одежда", "обувь"}),
+		DefaultAllowEmptyClose:         l.bool("DEFAULT_ALLOW_EMPTY_RECEPTION_CLOSE", false),
+
+		OutboxPublisher:      l.string("OUTBOX_PUBLISHER", "log"),
+		OutboxFilePath:       l.string("OUTBOX_FILE_PATH", "logs/outbox.jsonl"),
+		OutboxHTTPURL:        l.string("OUTBOX_HTTP_URL", ""),
+		OutboxHTTPTimeout:    l.duration("OUTBOX_HTTP_TIMEOUT", 5*time.Second),
+		OutboxPollInterval:   l.duration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:      l.int("OUTBOX_BATCH_SIZE", 100),
+		OutboxMaxAttempts:    l.int("OUTBOX_MAX_ATTEMPTS", 20),
+		OutboxRetryBaseDelay: l.duration("OUTBOX_RETRY_BASE_DELAY", time.Second),
+		OutboxRetryMaxDelay:  l.duration("OUTBOX_RETRY_MAX_DELAY", 5*time.Minute),
+
+		WebhookFanOutInterval:   l.duration("WEBHOOK_FANOUT_INTERVAL", time.Second),
+		WebhookDispatchInterval: l.duration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second),
+		WebhookBatchSize:        l.int("WEBHOOK_BATCH_SIZE", 50),
+		WebhookTimeout:          l.duration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts:      l.int("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryBaseDelay:   l.duration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second),
+		WebhookRetryMaxDelay:    l.duration("WEBHOOK_RETRY_MAX_DELAY", time.Hour),
+
+		EventStreamPollInterval:      l.duration("EVENT_STREAM_POLL_INTERVAL", 500*time.Millisecond),
+		EventStreamHeartbeatInterval: l.duration("EVENT_STREAM_HEARTBEAT_INTERVAL", 15*time.Second),
+		EventStreamGapTimeout:        l.duration("EVENT_STREAM_GAP_TIMEOUT", 5*time.Second),
+
+		PVZCacheBackend:  l.string("PVZ_CACHE_BACKEND", PVZCacheMemory),
+		PVZCacheSize:     l.int("PVZ_CACHE_SIZE", 1000),
+		PVZCacheTTL:      l.duration("PVZ_CACHE_TTL", 30*time.Second),
+		PVZCacheStaleTTL: l.duration("PVZ_CACHE_STALE_TTL", 5*time.Minute),
+
+		RateLimitAuth:      l.rateLimit("RATE_LIMIT_AUTH", ratelimit.Rule{Requests: 20, Per: time.Minute}),
+		RateLimitModerator: l.rateLimit("RATE_LIMIT_MODERATOR", ratelimit.Rule{Requests: 300, Per: time.Minute}),
+		RateLimitEmployee:  l.rateLimit("RATE_LIMIT_EMPLOYEE", ratelimit.Rule{Requests: 120, Per: time.Minute}),
+		RateLimitShared:    l.rateLimit("RATE_LIMIT_SHARED", ratelimit.Rule{Requests: 600, Per: time.Minute}),
+		RateLimitGRPC:      l.rateLimit("RATE_LIMIT_GRPC", ratelimit.Rule{Requests: 600, Per: time.Minute}),
+
+		LoginMaxFailuresPerEmail: l.int("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		LoginMaxFailuresPerIP:    l.int("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 20),
+		LoginFailureWindow:       l.duration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutBase:         l.duration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:          l.duration("LOGIN_LOCKOUT_MAX", time.Hour),
+
+		PasswordMinLength:       l.int("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequiredClasses: l.list("PASSWORD_REQUIRED_CLASSES", []string{"lower", "digit"}),
+		PasswordDenylistFile:    l.string("PASSWORD_DENYLIST_FILE", "config/common_passwords.txt"),
+		PasswordResetTokenTTL:   l.duration("PASSWORD_RESET_TOKEN_TTL", 30*time.Minute),
+
+		Notifier:         l.string("NOTIFIER", "log"),
+		NotifierFilePath: l.string("NOTIFIER_FILE_PATH", "logs/notifications.jsonl"),
+
+		RBACPolicyFile: l.string("RBAC_POLICY_FILE", "config/rbac.yaml"),
+	}
+	l.checkUnknownFileKeys()
+	cfg.settings = l.settings
+
+	if problems := append(l.problems, cfg.validate()...); len(problems) > 0 {
+		return nil, cfg.invalid(problems)
+	}
+	return cfg, nil
+}
+
+// Пул pgx к primary: бюджет DB_MAX_OPEN_CONNS за вычетом доли database/sql
+func (c *Config) DBPool() database.PoolConfig {
+	maxOpen := c.DBMaxOpenConns
+	if maxOpen > 0 {
+		maxOpen -= c.DBSQLMaxOpenConns
+	}
+	return database.PoolConfig{
+		MaxOpenConns:    maxOpen,
+		MaxIdleConns:    c.DBMaxIdleConns,
+		ConnMaxLifetime: c.DBConnMaxLifetime,
+		ConnMaxIdleTime: c.DBConnMaxIdleTime,
+
+		MinConns:               c.DBMinConns,
+		StatementCacheCapacity: c.DBStatementCacheCapacity,
+	}
+}
+
+// Пул pgx к одной реплике. Соединения не держатся открытыми заранее: с реплики читают
+// только часть запросов, и при ее отставании пул простаивает
+func (c *Config) DBReplicaPool() database.PoolConfig {
+	return database.PoolConfig{
+		MaxOpenConns:           c.DBReplicaMaxOpenConns,
+		ConnMaxLifetime:        c.DBConnMaxLifetime,
+		ConnMaxIdleTime:        c.DBConnMaxIdleTime,
+		StatementCacheCapacity: c.DBStatementCacheCapacity,
+	}
+}
+
+// Пул database/sql к primary для миграций и репозиториев, которые еще не переведены на pgx
+func (c *Config) DBSQLPool() database.PoolConfig {
+	pool := database.PoolConfig{
+		MaxIdleConns:    c.DBMaxIdleConns,
+		ConnMaxLifetime: c.DBConnMaxLifetime,
+		ConnMaxIdleTime: c.DBConnMaxIdleTime,
+	}
+	if c.DBMaxOpenConns > 0 {
+		pool.MaxOpenConns = c.DBSQLMaxOpenConns
+		pool.MaxIdleConns = min(c.DBMaxIdleConns, c.DBSQLMaxOpenConns)
+	}
+	return pool
 }
 
-func getEnvVar(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == EnvProd
+}
+
+// Проверяет согласованность настроек и требования окружения. Возвращает все нарушения сразу,
+// чтобы при развертывании не исправлять их по одному
+func (c *Config) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return c.invalid(problems)
+	}
+	return nil
+}
+
+func (c *Config) invalid(problems []string) error {
+	sort.Strings(problems)
+	return fmt.Errorf("invalid configuration for APP_ENV=%s: %s", c.AppEnv, strings.Join(problems, "; "))
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+
+	switch c.AppEnv {
+	case EnvDev, EnvStaging, EnvProd:
+	default:
+		problems = append(problems, fmt.Sprintf("APP_ENV: unknown environment %q, expected dev, staging or prod", c.AppEnv))
+	}
+
+	for name, port := range map[string]string{"SERVER_PORT": c.ServerPort, "METRICS_PORT": c.MetricsPort} {
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			problems = append(problems, fmt.Sprintf("%s: invalid port %q", name, port))
+		}
+	}
+
+	if c.JWTTokenDuration == 0 {
+		problems = append(problems, "JWT_TOKEN_DURATION: must be positive")
 	}
-	return defaultValue
+	if c.JWTKeysFile != "" && c.JWTKeyGracePeriod < c.JWTTokenDuration {
+		problems = append(problems, "JWT_KEY_GRACE_PERIOD: must not be shorter than JWT_TOKEN_DURATION")
+	}
+
+	if c.ReadinessTimeout == 0 {
+		problems = append(problems, "READINESS_TIMEOUT: must be positive")
+	}
+
+	if c.DBMaxOpenConns > 0 && c.DBMaxIdleConns > c.DBMaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS: must not exceed DB_MAX_OPEN_CONNS")
+	}
+	if c.DBMaxOpenConns > 0 && (c.DBSQLMaxOpenConns <= 0 || c.DBSQLMaxOpenConns >= c.DBMaxOpenConns) {
+		problems = append(problems, "DB_SQL_MAX_OPEN_CONNS: must be positive and less than DB_MAX_OPEN_CONNS")
+	}
+	if c.DBMaxOpenConns > 0 && c.DBMinConns > c.DBMaxOpenConns-c.DBSQLMaxOpenConns {
+		problems = append(problems, "DB_MIN_CONNS: must not exceed DB_MAX_OPEN_CONNS - DB_SQL_MAX_OPEN_CONNS")
+	}
+	if c.DBStatementCacheCapacity == 0 {
+		problems = append(problems, "DB_STATEMENT_CACHE_CAPACITY: must be positive")
+	}
+	// Адрес реплики в ошибке не выводится: в нем пароль
+	for i, replicaURL := range c.DBReplicaURLs {
+		parsed, err := url.Parse(replicaURL)
+		if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+			problems = append(problems, fmt.Sprintf("DB_REPLICA_URLS: replica %d must be a postgres:// URL", i+1))
+		} else if c.IsProduction() && parsed.Query().Get("sslmode") == "disable" {
+			problems = append(problems, fmt.Sprintf("DB_REPLICA_URLS: replica %d: sslmode=disable is not allowed in prod", i+1))
+		}
+	}
+	if c.DBReplicaMaxOpenConns < 0 {
+		problems = append(problems, "DB_REPLICA_MAX_OPEN_CONNS: must not be negative")
+	}
+	if len(c.DBReplicaURLs) > 0 && c.DBReplicaMaxLag == 0 {
+		problems = append(problems, "DB_REPLICA_MAX_LAG: must be positive")
+	}
+	if len(c.DBReplicaURLs) > 0 && c.DBReplicaCheckInterval == 0 {
+		problems = append(problems, "DB_REPLICA_CHECK_INTERVAL: must be positive")
+	}
+
+	if c.OutboxPollInterval > 0 && c.OutboxMaxAttempts <= 0 {
+		problems = append(problems, "OUTBOX_MAX_ATTEMPTS: must be positive")
+	}
+
+	switch c.PVZCacheBackend {
+	case PVZCacheNone:
+	case PVZCacheMemory:
+		if c.PVZCacheSize == 0 {
+			problems = append(problems, "PVZ_CACHE_SIZE: must be positive")
+		}
+		if c.PVZCacheTTL == 0 {
+			problems = append(problems, "PVZ_CACHE_TTL: must be positive")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("PVZ_CACHE_BACKEND: unknown backend %q, expected memory or none", c.PVZCacheBackend))
+	}
+
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		problems = append(problems, fmt.Sprintf("LOG_FORMAT: unknown format %q, expected json or text", c.LogFormat))
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		problems = append(problems, "TLS_CERT_FILE, TLS_KEY_FILE: must be set together")
+	}
+
+	if c.IsProduction() {
+		if c.JWTKeysFile == "" && c.JWTSigningKey == defaultJWTSigningKey {
+			problems = append(problems, "JWT_SIGNING_KEY: default value is not allowed in prod")
+		}
+
+		dbURL, err := url.Parse(c.DatabaseURL)
+		if err == nil {
+			if password, _ := dbURL.User.Password(); password == defaultPostgresPassword {
+				problems = append(problems, "POSTGRES_PASSWORD: default value is not allowed in prod")
+			}
+			if dbURL.Query().Get("sslmode") == "disable" {
+				problems = append(problems, "POSTGRES_SSLMODE: disable is not allowed in prod")
+			}
+		}
+
+		for name, path := range map[string]string{"TLS_CERT_FILE": c.TLSCertFile, "TLS_KEY_FILE": c.TLSKeyFile} {
+			if path == "" {
+				problems = append(problems, name+": required in prod")
+			} else if _, err := os.Stat(path); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+	}
+
+	return problems
 }
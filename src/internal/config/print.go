@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+const redacted = "******"
+
+// Итоговые настройки по ключам с указанием слоя; значения секретов скрыты
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, 0, len(c.settings))
+	for _, setting := range c.settings {
+		if secretKeys[setting.Key] && setting.Value != "" {
+			setting.Value = redacted
+		}
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// Печатает настройки для --print-config в формате KEY=value, который можно вставить в .env
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, setting := range c.Settings() {
+		fmt.Fprintf(tw, "%s=%s\t# %s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"avito-backend/src/pkg/ratelimit"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Слои, из которых берется значение настройки, по возрастанию приоритета
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceSecret  = "secret"
+)
+
+// Секреты можно передать файлом через KEY_FILE (Docker secrets); в --print-config они скрыты
+var secretKeys = map[string]bool{
+	"JWT_SIGNING_KEY":   true,
+	"POSTGRES_PASSWORD": true,
+	// Адреса реплик содержат пароль
+	"DB_REPLICA_URLS": true,
+}
+
+// Итоговое значение настройки и слой, из которого оно взято
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Собирает значения из YAML-файла, переменных окружения и файлов секретов и копит ошибки
+// разбора, чтобы сообщить обо всех неверных настройках сразу
+type loader struct {
+	file     map[string]string
+	settings map[string]Setting
+	problems []string
+}
+
+func newLoader(file map[string]string) *loader {
+	return &loader{file: file, settings: make(map[string]Setting)}
+}
+
+// Читает YAML-файл настроек. Ключи совпадают с именами переменных окружения в любом регистре,
+// вложенные секции склеиваются через "_", списки - через запятую
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if len(root.Content) == 0 {
+		return values, nil
+	}
+	if err := flattenNode(root.Content[0], "", values); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flattenNode(node *yaml.Node, prefix string, values map[string]string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := strings.ToUpper(node.Content[i].Value)
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+			if err := flattenNode(node.Content[i+1], key, values); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: %s must be a list of values", item.Line, prefix)
+			}
+			items = append(items, item.Value)
+		}
+		values[prefix] = strings.Join(items, ",")
+	case yaml.ScalarNode:
+		if prefix == "" {
+			return fmt.Errorf("line %d: expected a mapping of settings", node.Line)
+		}
+		if node.Tag != "!!null" {
+			values[prefix] = node.Value
+		}
+	default:
+		return fmt.Errorf("line %d: unsupported value for %s", node.Line, prefix)
+	}
+	return nil
+}
+
+// Пустое значение в любом слое означает "не задано"
+func (l *loader) lookup(key string) (string, string) {
+	if secretKeys[key] {
+		if path, _ := l.lookup(key + "_FILE"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				l.fail(key+"_FILE", "%v", err)
+				return "", SourceSecret
+			}
+			return strings.TrimRight(string(data), "\r\n"), SourceSecret
+		}
+	}
+	if value := os.Getenv(key); value != "" {
+		return value, SourceEnv
+	}
+	if value := l.file[key]; value != "" {
+		return value, SourceFile
+	}
+	return "", SourceDefault
+}
+
+func (l *loader) fail(key, format string, args ...interface{}) {
+	l.problems = append(l.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+// Возвращает заданное значение или "" и запоминает итог для --print-config
+func (l *loader) raw(key string, defaultValue string) string {
+	value, source := l.lookup(key)
+	shown := value
+	if value == "" {
+		shown = defaultValue
+	}
+	l.settings[key] = Setting{Key: key, Value: shown, Source: source}
+	return value
+}
+
+func (l *loader) string(key, defaultValue string) string {
+	if value := l.raw(key, defaultValue); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (l *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	value := l.raw(key, defaultValue.String())
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		l.fail(key, "invalid value %q, expected a non-negative duration", value)
+		return defaultValue
+	}
+	return duration
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	value := l.raw(key, strconv.Itoa(defaultValue))
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		l.fail(key, "invalid value %q, expected a non-negative integer", value)
+		return defaultValue
+	}
+	return number
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	value := l.raw(key, strconv.FormatBool(defaultValue))
+	if value == "" {
+		return defaultValue
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		l.fail(key, "invalid value %q, expected a boolean", value)
+		return defaultValue
+	}
+	return flag
+}
+
+func (l *loader) rateLimit(key string, defaultValue ratelimit.Rule) ratelimit.Rule {
+	value := l.raw(key, defaultValue.String())
+	if value == "" {
+		return defaultValue
+	}
+
+	rule, err := ratelimit.ParseRule(value)
+	if err != nil {
+		l.fail(key, "%v", err)
+		return defaultValue
+	}
+	return rule
+}
+
+func (l *loader) list(key string, defaultValue []string) []string {
+	value := l.raw(key, strings.Join(defaultValue, ","))
+	if value == "" {
+		return defaultValue
+	}
+
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Ключи из файла, которые не прочитала ни одна настройка, скорее всего опечатки
+func (l *loader) checkUnknownFileKeys() {
+	for key := range l.file {
+		if _, ok := l.settings[key]; !ok && !l.isSecretFileKey(key) {
+			l.fail(key, "unknown setting in config file")
+		}
+	}
+}
+
+func (l *loader) isSecretFileKey(key string) bool {
+	name, ok := strings.CutSuffix(key, "_FILE")
+	return ok && secretKeys[name]
+}
+
+func (l *loader) logLevel(key string, defaultValue slog.Level) slog.Level {
+	value := l.raw(key, strings.ToLower(defaultValue.String()))
+	if value == "" {
+		return defaultValue
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		l.fail(key, "invalid value %q, expected debug, info, warn or error", value)
+		return defaultValue
+	}
+	return level
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/grpc/pb"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/jwt"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Права, необходимые для вызова методов gRPC API
+var MethodPermissions = map[string]models.Permission{
+	pb.PVZService_GetPVZList_FullMethodName: models.PermPVZRead,
+}
+
+// Проверяет, что токен пользователя не отозван и учетная запись не отключена
+type SessionValidator interface {
+	ValidateSession(userID string, tokenVersion int) error
+}
+
+// Проверяет API-ключ межсервисного клиента
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(rawKey string) (*models.APIKey, error)
+}
+
+type apiKeyContextKey struct{}
+
+// Проверяет токен из метаданных authorization или ключ из x-api-key и право роли на вызов метода.
+// Методы, отсутствующие в methodPermissions, запрещены. sessions и apiKeys могут быть nil
+func AuthInterceptor(tokenManager *jwt.TokenManager, sessions SessionValidator, apiKeys APIKeyAuthenticator, methodPermissions map[string]models.Permission) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if rawKey := metadataValue(ctx, "x-api-key"); rawKey != "" && apiKeys != nil {
+			key, err := apiKeys.AuthenticateAPIKey(rawKey)
+			if err != nil {
+				return nil, toStatus(ctx, err)
+			}
+
+			permission, ok := methodPermissions[info.FullMethod]
+			if !ok || !key.Allows(permission) {
+				return nil, toStatus(ctx, apperrors.ErrForbidden.WithDetails(map[string]any{"method": info.FullMethod}))
+			}
+			return handler(context.WithValue(ctx, apiKeyContextKey{}, key), req)
+		}
+
+		token, ok := bearerToken(ctx)
+		if !ok {
+			return nil, toStatus(ctx, apperrors.ErrMissingToken)
+		}
+
+		claims, err := tokenManager.ParseToken(token)
+		if err != nil {
+			return nil, toStatus(ctx, apperrors.ErrInvalidToken.Wrap(err))
+		}
+
+		if sessions != nil && claims.UserID != "" {
+			if err := sessions.ValidateSession(claims.UserID, claims.TokenVersion); err != nil {
+				return nil, toStatus(ctx, err)
+			}
+		}
+
+		permission, ok := methodPermissions[info.FullMethod]
+		if !ok || !models.Role(claims.Role).Can(permission) {
+			return nil, toStatus(ctx, apperrors.ErrForbidden.WithDetails(map[string]any{"method": info.FullMethod}))
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ПВЗ, которыми ограничен API-ключ вызова; nil — ограничений нет
+func pvzScopeFromContext(ctx context.Context) []uuid.UUID {
+	if key, ok := ctx.Value(apiKeyContextKey{}).(*models.APIKey); ok && len(key.PVZIDs) > 0 {
+		return key.PVZIDs
+	}
+	return nil
+}
+
+func metadataValue(ctx context.Context, name string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	token, found := strings.CutPrefix(metadataValue(ctx, "authorization"), "Bearer ")
+	if !found || token == "" {
+		return "", false
+	}
+	return token, true
+}
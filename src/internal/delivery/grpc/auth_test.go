@@ -0,0 +1,116 @@
+package grpc_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/grpc"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/jwt"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type stubSessions struct {
+	err error
+}
+
+func (s stubSessions) ValidateSession(userID string, tokenVersion int) error {
+	return s.err
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	info := &grpclib.UnaryServerInfo{FullMethod: "/pvz.v1.PVZService/GetPVZList"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	tokenFor := func(role string) string {
+		token, err := tokenManager.GenerateUserToken("user-1", role, 0)
+		require.NoError(t, err)
+		return token
+	}
+
+	interceptor := grpc.AuthInterceptor(tokenManager, nil, nil, grpc.MethodPermissions)
+
+	t.Run("Роль с правом pvz:read", func(t *testing.T) {
+		resp, err := interceptor(withToken(tokenFor("employee")), nil, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+
+	t.Run("Без токена", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Неверный токен", func(t *testing.T) {
+		_, err := interceptor(withToken("invalid"), nil, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Роль без права", func(t *testing.T) {
+		models.SetRolePermissions(map[models.Role][]models.Permission{
+			"auditor": {models.PermEventsRead},
+		})
+		t.Cleanup(func() { models.SetRolePermissions(models.DefaultRolePermissions()) })
+
+		_, err := interceptor(withToken(tokenFor("auditor")), nil, info, handler)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Метод без описанного права", func(t *testing.T) {
+		_, err := interceptor(withToken(tokenFor("moderator")), nil, &grpclib.UnaryServerInfo{FullMethod: "/pvz.v1.PVZService/Unknown"}, handler)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Отозванный токен", func(t *testing.T) {
+		interceptor := grpc.AuthInterceptor(tokenManager, stubSessions{err: apperrors.ErrTokenRevoked}, nil, grpc.MethodPermissions)
+		_, err := interceptor(withToken(tokenFor("employee")), nil, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+type stubAPIKeys struct {
+	key *models.APIKey
+}
+
+func (s stubAPIKeys) AuthenticateAPIKey(rawKey string) (*models.APIKey, error) {
+	if rawKey != "pvzk_valid" {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+	return s.key, nil
+}
+
+func TestAuthInterceptor_APIKey(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	info := &grpclib.UnaryServerInfo{FullMethod: "/pvz.v1.PVZService/GetPVZList"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))
+	}
+
+	interceptor := grpc.AuthInterceptor(tokenManager, nil, stubAPIKeys{key: &models.APIKey{Role: models.EmployeeRole}}, grpc.MethodPermissions)
+	resp, err := interceptor(withKey("pvzk_valid"), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(withKey("pvzk_revoked"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	narrowed := &models.APIKey{Role: models.EmployeeRole, Permissions: []models.Permission{models.PermEventsRead}}
+	interceptor = grpc.AuthInterceptor(tokenManager, nil, stubAPIKeys{key: narrowed}, grpc.MethodPermissions)
+	_, err = interceptor(withKey("pvzk_valid"), nil, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "право роли вне списка ключа")
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/i18n"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Домен в ErrorInfo, по которому клиент отличает ошибки сервиса от ошибок транспорта
+const errorDomain = "avito-backend"
+
+// Переводит ошибки обработчиков в статусы gRPC. Должен стоять первым в цепочке, чтобы
+// обработать ошибки и остальных перехватчиков
+func ErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(ctx, err)
+		}
+		return resp, nil
+	}
+}
+
+// Статус gRPC по коду apperrors с ErrorInfo, где Reason — код ошибки, а Metadata — ее сведения.
+// Сообщение переводится на язык из метаданных accept-language. Готовые статусы возвращаются без изменений
+func toStatus(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	appErr := apperrors.From(err)
+	if appErr.HTTPStatus >= http.StatusInternalServerError {
+		cause := errors.Unwrap(appErr)
+		if cause == nil {
+			cause = appErr
+		}
+		slog.ErrorContext(ctx, "внутренняя ошибка сервера", "code", appErr.Code, "error", cause)
+	} else {
+		slog.WarnContext(ctx, "ошибка запроса", "code", appErr.Code, "message", appErr.Error())
+	}
+
+	st := status.New(appErr.GRPCCode, appErr.Message(i18n.Negotiate(metadataValue(ctx, "accept-language"))))
+	errorInfo := &errdetails.ErrorInfo{Reason: appErr.Code, Domain: errorDomain}
+	if len(appErr.Details) > 0 {
+		errorInfo.Metadata = make(map[string]string, len(appErr.Details))
+		for key, value := range appErr.Details {
+			errorInfo.Metadata[key] = fmt.Sprint(value)
+		}
+	}
+	if withDetails, detailsErr := st.WithDetails(errorInfo); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
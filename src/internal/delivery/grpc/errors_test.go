@@ -0,0 +1,69 @@
+package grpc_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/grpc"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorInterceptor(t *testing.T) {
+	interceptor := grpc.ErrorInterceptor()
+	info := &grpclib.UnaryServerInfo{FullMethod: "/pvz.v1.PVZService/GetPVZList"}
+	call := func(err error) error {
+		_, callErr := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+		return callErr
+	}
+
+	t.Run("Application Error", func(t *testing.T) {
+		st, ok := status.FromError(call(apperrors.ErrTooManyRequests.WithDetails(map[string]any{"retryAfter": 30})))
+		require.True(t, ok)
+		assert.Equal(t, codes.ResourceExhausted, st.Code())
+		assert.Equal(t, "Слишком много запросов", st.Message())
+
+		require.Len(t, st.Details(), 1)
+		errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "too_many_requests", errorInfo.Reason)
+		assert.Equal(t, "30", errorInfo.Metadata["retryAfter"])
+	})
+
+	t.Run("English Message", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "en"))
+		_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, apperrors.ErrPVZNotFound
+		})
+
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "PVZ not found", st.Message())
+	})
+
+	t.Run("Wrapped Application Error", func(t *testing.T) {
+		err := call(errors.Join(errors.New("load pvz"), apperrors.ErrPVZNotFound))
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Unknown Error Is Hidden", func(t *testing.T) {
+		st, _ := status.FromError(call(errors.New("connection refused")))
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "Внутренняя ошибка сервера", st.Message())
+	})
+
+	t.Run("Status Passes Through", func(t *testing.T) {
+		st, _ := status.FromError(call(status.Error(codes.Unavailable, "shutting down")))
+		assert.Equal(t, codes.Unavailable, st.Code())
+		assert.Equal(t, "shutting down", st.Message())
+	})
+}
@@ -2,9 +2,9 @@ package grpc
 
 import (
 	"context"
-	"time"
 
 	pb "avito-backend/src/internal/delivery/grpc/pb"
+	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/service"
 
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -22,7 +22,7 @@ func NewPVZGrpcServer(pvzService service.PVZServiceInterface) *PVZGrpcServer {
 }
 
 func (s *PVZGrpcServer) GetPVZList(ctx context.Context, req *pb.GetPVZListRequest) (*pb.GetPVZListResponse, error) {
-	pvzList, err := s.pvzService.GetPVZsWithReceptions(time.Time{}, time.Time{}, 0, 1000)
+	pvzList, err := s.pvzService.GetPVZsWithReceptions(models.PVZFilter{Limit: 1000, PVZIDs: pvzScopeFromContext(ctx)})
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"avito-backend/src/internal/delivery/grpc"
 	pb "avito-backend/src/internal/delivery/grpc/pb"
 	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
 	"context"
 	"database/sql"
 	"testing"
@@ -19,18 +20,70 @@ type MockPVZService struct {
 	mock.Mock
 }
 
-func (m *MockPVZService) GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error) {
-	args := m.Called(startDate, endDate, offset, limit)
+func (m *MockPVZService) GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error) {
+	args := m.Called(filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.PVZWithReceptions), args.Error(1)
 }
 
-func (m *MockPVZService) Create(city string) (*models.PVZ, error) {
+func (m *MockPVZService) Create(input models.PVZCreate) (*models.PVZ, error) {
 	return nil, nil
 }
 
+func (m *MockPVZService) FindNearby(lat, lon, radiusKm float64) ([]*models.PVZWithDistance, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) Update(pvzID uuid.UUID, update models.PVZUpdate) (*models.PVZ, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) ChangeStatus(pvzID uuid.UUID, status models.PVZStatus) (*models.PVZ, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) GetPVZ(pvzID uuid.UUID) (*models.PVZWithReceptions, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) CurrentVersion(pvzID uuid.UUID, resource service.PVZResource) (string, error) {
+	return "", nil
+}
+
+func (m *MockPVZService) MutateIfVersion(pvzID uuid.UUID, resource service.PVZResource, matches func(version string) bool, mutate func(service.PVZServiceInterface) error) error {
+	return mutate(m)
+}
+
+func (m *MockPVZService) UpdateSettings(pvzID uuid.UUID, update models.PVZSettingsUpdate) (*models.PVZSettings, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) GetSchedule(pvzID uuid.UUID) (*models.PVZSchedule, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) UpdateSchedule(pvzID uuid.UUID, update models.PVZScheduleUpdate) (*models.PVZSchedule, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) SetCalendarException(pvzID uuid.UUID, exception models.CalendarException) (*models.PVZSchedule, error) {
+	return nil, nil
+}
+
+func (m *MockPVZService) DeleteCalendarException(pvzID uuid.UUID, date string) error {
+	return nil
+}
+
 func (m *MockPVZService) CreateReception(pvzID uuid.UUID) (*models.Reception, error) {
 	return nil, nil
 }
@@ -43,7 +96,7 @@ func (m *MockPVZService) CloseLastReception(pvzID uuid.UUID) (*models.Reception,
 	return nil, nil
 }
 
-func (m *MockPVZService) DeleteLastProduct(pvzID uuid.UUID) error {
+func (m *MockPVZService) DeleteLastProduct(pvzID uuid.UUID, deletedBy uuid.UUID) error {
 	return nil
 }
 
@@ -69,12 +122,7 @@ func TestPVZGrpcServer_GetPVZList(t *testing.T) {
 						Receptions: []models.ReceptionWithProducts{},
 					},
 				}
-				s.On("GetPVZsWithReceptions",
-					time.Time{},
-					time.Time{},
-					0,
-					1000,
-				).Return(pvzList, nil)
+				s.On("GetPVZsWithReceptions", models.PVZFilter{Limit: 1000}).Return(pvzList, nil)
 			},
 			request: &pb.GetPVZListRequest{},
 			checkResult: func(t *testing.T, response *pb.GetPVZListResponse, err error) {
@@ -89,12 +137,7 @@ func TestPVZGrpcServer_GetPVZList(t *testing.T) {
 		{
 			name: "Empty Result",
 			mockBehavior: func(s *MockPVZService) {
-				s.On("GetPVZsWithReceptions",
-					time.Time{},
-					time.Time{},
-					0,
-					1000,
-				).Return([]*models.PVZWithReceptions{}, nil)
+				s.On("GetPVZsWithReceptions", models.PVZFilter{Limit: 1000}).Return([]*models.PVZWithReceptions{}, nil)
 			},
 			request: &pb.GetPVZListRequest{},
 			checkResult: func(t *testing.T, response *pb.GetPVZListResponse, err error) {
@@ -106,12 +149,7 @@ func TestPVZGrpcServer_GetPVZList(t *testing.T) {
 		{
 			name: "Service Error",
 			mockBehavior: func(s *MockPVZService) {
-				s.On("GetPVZsWithReceptions",
-					time.Time{},
-					time.Time{},
-					0,
-					1000,
-				).Return(nil, sql.ErrConnDone)
+				s.On("GetPVZsWithReceptions", models.PVZFilter{Limit: 1000}).Return(nil, sql.ErrConnDone)
 			},
 			request: &pb.GetPVZListRequest{},
 			checkResult: func(t *testing.T, response *pb.GetPVZListResponse, err error) {
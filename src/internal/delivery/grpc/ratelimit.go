@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/pkg/metrics"
+	"avito-backend/src/pkg/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Ограничивает частоту вызовов по IP клиента. При отказе возвращает ResourceExhausted
+// и заголовок retry-after в секундах, как Retry-After в HTTP
+func RateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		allowed, wait := limiter.Allow("ip:" + peerIP(ctx))
+		if !allowed {
+			metrics.RateLimitRejectedTotal.WithLabelValues("grpc").Inc()
+			retryAfter := ratelimit.RetryAfterSeconds(wait)
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+			return nil, toStatus(ctx, apperrors.ErrTooManyRequests.WithDetails(map[string]any{"group": "grpc", "retryAfter": retryAfter}))
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,39 @@
+package grpc_test
+
+import (
+	"avito-backend/src/internal/delivery/grpc"
+	"avito-backend/src/pkg/ratelimit"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	interceptor := grpc.RateLimitInterceptor(ratelimit.NewLimiter(ratelimit.Rule{Requests: 1, Per: time.Minute}))
+	info := &grpclib.UnaryServerInfo{FullMethod: "/pvz.v1.PVZService/GetPVZList"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	ctxFrom := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 4000}})
+	}
+
+	resp, err := interceptor(ctxFrom("10.0.0.1"), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(ctxFrom("10.0.0.1"), nil, info, handler)
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = interceptor(ctxFrom("10.0.0.2"), nil, info, handler)
+	assert.NoError(t, err, "другой клиент ограничивается отдельно")
+}
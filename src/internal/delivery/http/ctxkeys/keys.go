@@ -2,4 +2,9 @@ package ctxkeys
 
 type contextKey string
 
-const UserRoleKey contextKey = "userRole"
+const (
+	UserRoleKey contextKey = "userRole"
+	UserIDKey   contextKey = "userID"
+	// *models.APIKey, если запрос авторизован API-ключом
+	APIKeyKey contextKey = "apiKey"
+)
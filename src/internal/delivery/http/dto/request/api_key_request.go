@@ -0,0 +1,11 @@
+package request
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name"`
+	Role        string     `json:"role"`
+	Permissions []string   `json:"permissions"`
+	PVZIDs      []string   `json:"pvzIds"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
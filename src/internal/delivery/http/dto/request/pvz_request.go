@@ -1,7 +1,18 @@
 package request
 
 type CreatePVZRequest struct {
-	City string `json:"city"`
+	City      string   `json:"city"`
+	Address   string   `json:"address"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Force     bool     `json:"force"`
+}
+
+type UpdatePVZRequest struct {
+	City      *string  `json:"city"`
+	Address   *string  `json:"address"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
 }
 
 type GetPVZsRequest struct {
@@ -0,0 +1,22 @@
+package request
+
+import "time"
+
+type WorkingHoursRequest struct {
+	Weekday int    `json:"weekday"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
+type UpdateScheduleRequest struct {
+	Timezone      string                `json:"timezone"`
+	WorkingHours  []WorkingHoursRequest `json:"workingHours"`
+	OverrideUntil *time.Time            `json:"overrideUntil"`
+}
+
+type CalendarExceptionRequest struct {
+	Closed  bool    `json:"closed"`
+	Opens   *string `json:"opens"`
+	Closes  *string `json:"closes"`
+	Comment string  `json:"comment"`
+}
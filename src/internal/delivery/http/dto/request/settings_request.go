@@ -0,0 +1,7 @@
+package request
+
+type UpdatePVZSettingsRequest struct {
+	MaxProductsPerReception *int     `json:"maxProductsPerReception"`
+	AllowedProductTypes     []string `json:"allowedProductTypes"`
+	AllowEmptyClose         *bool    `json:"allowEmptyClose"`
+}
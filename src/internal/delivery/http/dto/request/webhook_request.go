@@ -0,0 +1,9 @@
+package request
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"eventTypes"`
+	PVZID      *string  `json:"pvzId"`
+	City       *string  `json:"city"`
+}
@@ -0,0 +1,9 @@
+package response
+
+import "avito-backend/src/internal/domain/models"
+
+// Ответ на создание ключа: Key возвращается только один раз
+type CreatedAPIKey struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
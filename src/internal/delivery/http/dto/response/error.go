@@ -1,5 +1,18 @@
 package response
 
 type ErrorResponse struct {
-	Message string `json:"message"`
+	Message string         `json:"message"`
+	Code    string         `json:"code,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Ответ об ошибке в формате RFC 7807. code и details — расширения, совпадающие с ErrorResponse
+type ProblemDetails struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code"`
+	Details  map[string]any `json:"details,omitempty"`
 }
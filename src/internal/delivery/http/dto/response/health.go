@@ -0,0 +1,6 @@
+package response
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	Check  string `json:"check,omitempty"`
+}
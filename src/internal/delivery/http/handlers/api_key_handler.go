@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/dto/response"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyAPIKey(ctx, w, r) {
+		return
+	}
+
+	var req request.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	input := models.APIKey{
+		Name:        req.Name,
+		Role:        models.Role(req.Role),
+		Permissions: make([]models.Permission, 0, len(req.Permissions)),
+		PVZIDs:      make([]uuid.UUID, 0, len(req.PVZIDs)),
+		ExpiresAt:   req.ExpiresAt,
+	}
+	for _, permission := range req.Permissions {
+		input.Permissions = append(input.Permissions, models.Permission(permission))
+	}
+	for _, value := range req.PVZIDs {
+		pvzID, err := uuid.Parse(value)
+		if err != nil {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
+			return
+		}
+		input.PVZIDs = append(input.PVZIDs, pvzID)
+	}
+	if userID := userIDFromContext(ctx); userID != uuid.Nil {
+		input.CreatedBy = &userID
+	}
+
+	slog.InfoContext(ctx, "создание API-ключа", "name", req.Name, "role", req.Role)
+
+	key, rawKey, err := h.apiKeyService.CreateKey(input)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "API-ключ создан", "api_key_id", key.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response.CreatedAPIKey{APIKey: key, Key: rawKey})
+}
+
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyAPIKey(ctx, w, r) {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys()
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyAPIKey(ctx, w, r) {
+		return
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyId"))
+	if err != nil {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestAPIKeyID))
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(keyID, userIDFromContext(ctx)); err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "API-ключ отозван", "api_key_id", keyID)
+	w.WriteHeader(http.StatusNoContent)
+}
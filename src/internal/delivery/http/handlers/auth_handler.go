@@ -2,15 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/mail"
 
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/delivery/http/dto/request"
-	"avito-backend/src/internal/delivery/http/dto/response"
+	"avito-backend/src/internal/delivery/http/httperror"
+	appmiddleware "avito-backend/src/internal/delivery/http/middleware"
 	"avito-backend/src/internal/service"
 	"avito-backend/src/pkg/logger"
+
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -28,8 +33,7 @@ func (h *AuthHandler) DummyLogin(w http.ResponseWriter, r *http.Request) {
 
 	var req request.DummyLoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.ErrorContext(ctx, "ошибка декодирования запроса", "error", err)
-		h.sendError(w, "Неверный формат запроса", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
@@ -37,14 +41,7 @@ func (h *AuthHandler) DummyLogin(w http.ResponseWriter, r *http.Request) {
 
 	token, err := h.authService.GenerateToken(req.Role)
 	if err != nil {
-		switch err {
-		case apperrors.ErrInvalidRole:
-			slog.WarnContext(ctx, "указана недопустимая роль", "role", req.Role)
-			h.sendError(w, "Недопустимая роль", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка генерации токена", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err)
 		return
 	}
 
@@ -58,8 +55,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	var req request.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.ErrorContext(ctx, "ошибка декодирования запроса", "error", err)
-		h.sendError(w, "Неверный формат запроса", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
@@ -68,31 +64,19 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		"role", req.Role)
 
 	if req.Email == "" || req.Role == "" || req.Password == "" {
-		slog.WarnContext(ctx, "отсутствуют обязательные поля")
-		h.sendError(w, "Отсутствуют обязательные поля", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestMissingFields))
 		return
 	}
 
 	if _, err := mail.ParseAddress(req.Email); err != nil {
-		slog.WarnContext(ctx, "неверный формат email", "email", req.Email)
-		h.sendError(w, "Неверный формат email", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestEmail))
 		return
 	}
 
 	ctx = logger.WithEmail(ctx, req.Email)
 	user, err := h.authService.Register(req.Email, req.Password, req.Role)
 	if err != nil {
-		switch err {
-		case apperrors.ErrUserAlreadyExists:
-			slog.WarnContext(ctx, "пользователь уже существует")
-			h.sendError(w, "Пользователь уже существует", http.StatusBadRequest)
-		case apperrors.ErrInvalidRole:
-			slog.WarnContext(ctx, "недопустимая роль", "role", req.Role)
-			h.sendError(w, "Недопустимая роль", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка регистрации", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err)
 		return
 	}
 
@@ -107,30 +91,25 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	var req request.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.ErrorContext(ctx, "ошибка декодирования запроса", "error", err)
-		h.sendError(w, "Неверный формат запроса", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
 	slog.InfoContext(ctx, "попытка входа", "email", req.Email)
 
 	if req.Email == "" || req.Password == "" {
-		slog.WarnContext(ctx, "отсутствуют учетные данные")
-		h.sendError(w, "Отсутствуют учетные данные", http.StatusUnauthorized)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidCredentials.WithMessageKey(apperrors.KeyInvalidCredentialsMissing))
 		return
 	}
 
 	ctx = logger.WithEmail(ctx, req.Email)
-	token, err := h.authService.Login(req.Email, req.Password)
+	token, err := h.authService.Login(req.Email, req.Password, appmiddleware.ClientIP(r))
 	if err != nil {
-		switch err {
-		case apperrors.ErrInvalidCredentials:
-			slog.WarnContext(ctx, "неверные учетные данные")
-			h.sendError(w, "Неверные учетные данные", http.StatusUnauthorized)
-		default:
-			slog.ErrorContext(ctx, "ошибка авторизации", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
+		if errors.Is(err, apperrors.ErrAccountLocked) {
+			// Ответ не отличается от неверного пароля, чтобы блокировка не выдавала существование учетной записи
+			err = apperrors.ErrInvalidCredentials.Wrap(err)
 		}
+		httperror.Write(ctx, w, r, err)
 		return
 	}
 
@@ -139,10 +118,116 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(token)
 }
 
-func (h *AuthHandler) sendError(w http.ResponseWriter, message string, code int) {
+func (h *AuthHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.UnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if req.IP != "" && net.ParseIP(req.IP) == nil {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestIP))
+		return
+	}
+
+	slog.InfoContext(ctx, "снятие блокировки входа", "email", req.Email, "ip", req.IP)
+
+	if err := h.authService.Unlock(req.Email, req.IP, userIDFromContext(ctx)); err != nil {
+		if errors.Is(err, apperrors.ErrValidationFailed) {
+			err = apperrors.ErrValidationFailed.WithMessageKey(apperrors.KeyValidationFailedUnlockTarget)
+		}
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "блокировка входа снята")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := userIDFromContext(ctx)
+	if userID == uuid.Nil {
+		httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+		return
+	}
+
+	var req request.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if req.OldPassword == "" || req.NewPassword == "" {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestMissingFields))
+		return
+	}
+
+	slog.InfoContext(ctx, "смена пароля", "user_id", userID)
+
+	token, err := h.authService.ChangePassword(userID, req.OldPassword, req.NewPassword)
+	if err != nil {
+		// Неверный текущий пароль не должен выглядеть как недействительный токен
+		if errors.Is(err, apperrors.ErrInvalidCredentials) {
+			err = apperrors.ErrInvalidCurrentPassword
+		}
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "пароль изменен")
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(response.ErrorResponse{
-		Message: message,
-	})
+	json.NewEncoder(w).Encode(token)
+}
+
+// Всегда отвечает 202, чтобы по ответу нельзя было узнать, зарегистрирован ли email
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestEmail))
+		return
+	}
+
+	ctx = logger.WithEmail(ctx, req.Email)
+	slog.InfoContext(ctx, "запрос сброса пароля")
+
+	if err := h.authService.RequestPasswordReset(req.Email); err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req request.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestMissingFields))
+		return
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "пароль сброшен")
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"net/http"
+)
+
+// Для приемок и товаров swagger объявляет только 400 и 403, поэтому несуществующий ПВЗ
+// на этих маршрутах отдается как 400
+var pvzNotFoundAsBadRequest = httperror.Override{Err: apperrors.ErrPVZNotFound, Status: http.StatusBadRequest}
+
+// Ошибка разбора запроса с сообщением о конкретном поле; key — ключ каталога i18n
+func invalidRequest(key string) error {
+	return apperrors.ErrInvalidRequest.WithMessageKey(key)
+}
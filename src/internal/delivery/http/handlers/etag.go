@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/service"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func quoteETag(version string) string {
+	return `"` + version + `"`
+}
+
+// Выставляет ETag и отвечает 304, если у клиента та же версия. Для If-None-Match версии
+// сравниваются слабо, то есть W/"x" совпадает с "x"
+func notModified(w http.ResponseWriter, r *http.Request, version string) bool {
+	etag := quoteETag(version)
+	w.Header().Set("ETag", etag)
+
+	if !etagListMatches(r.Header.Get("If-None-Match"), etag, false) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// Выполняет изменение ресурса ПВЗ. Без If-Match изменение безусловное, с ним версия проверяется
+// в той же транзакции, что и запись; если ПВЗ не найден, условие не выполняется
+func (h *PVZHandler) mutateIfMatch(ctx context.Context, r *http.Request, pvzID uuid.UUID, resource service.PVZResource, mutate func(service.PVZServiceInterface) error) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return mutate(h.pvzService)
+	}
+
+	err := h.pvzService.MutateIfVersion(pvzID, resource, func(version string) bool {
+		return etagListMatches(ifMatch, quoteETag(version), true)
+	}, mutate)
+	if errors.Is(err, apperrors.ErrPreconditionFailed) {
+		slog.WarnContext(ctx, "версия ресурса изменилась", "resource", resource, "if_match", ifMatch)
+	}
+	return err
+}
+
+// Разбирает список из заголовка If-Match или If-None-Match. При строгом сравнении слабые
+// версии W/"..." не совпадают ни с чем
+func etagListMatches(header, etag string, strong bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak, ok := strings.CutPrefix(candidate, "W/"); ok {
+			if strong {
+				continue
+			}
+			candidate = weak
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
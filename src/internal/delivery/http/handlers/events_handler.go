@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/stream"
+	"avito-backend/src/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	replayBatchSize = 500
+	// Через сколько миллисекунд браузер переподключается после обрыва
+	streamRetryMs = 3000
+)
+
+type EventsHandler struct {
+	hub       *stream.Hub
+	source    stream.EventSource
+	heartbeat time.Duration
+}
+
+func NewEventsHandler(hub *stream.Hub, source stream.EventSource, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{
+		hub:       hub,
+		source:    source,
+		heartbeat: heartbeat,
+	}
+}
+
+func (h *EventsHandler) StreamPVZ(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, err := uuid.Parse(chi.URLParam(r, "pvzId"))
+	if err != nil {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	h.serve(ctx, w, r, models.EventFilter{PVZID: &pvzID})
+}
+
+func (h *EventsHandler) StreamCity(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	city := models.City(r.URL.Query().Get("city"))
+	if !city.IsValid() {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidCity)
+		return
+	}
+
+	// Поток города включает чужие ПВЗ, поэтому ключу с ограничением по ПВЗ он недоступен
+	if pvzScopeFromContext(ctx) != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+		return
+	}
+
+	h.serve(ctx, w, r, models.EventFilter{City: &city})
+}
+
+func (h *EventsHandler) serve(ctx context.Context, w http.ResponseWriter, r *http.Request, filter models.EventFilter) {
+	lastEventID, hasLastEventID, err := parseLastEventID(r)
+	if err != nil {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestLastEventID))
+		return
+	}
+
+	// Подписываемся до чтения истории, чтобы не потерять события между чтением и подпиской
+	subscription := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(subscription)
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMs)
+	if err := controller.Flush(); err != nil {
+		slog.ErrorContext(ctx, "соединение не поддерживает потоковую передачу", "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "открыт поток событий", "last_event_id", lastEventID)
+
+	replayed := make(map[int64]bool)
+	if hasLastEventID {
+		afterID := lastEventID
+		for {
+			events, err := h.source.ListEventsAfter(afterID, filter, replayBatchSize)
+			if err != nil {
+				slog.ErrorContext(ctx, "ошибка чтения пропущенных событий", "error", err)
+				return
+			}
+			for _, event := range events {
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+				replayed[event.ID] = true
+				afterID = event.ID
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+			if len(events) < replayBatchSize {
+				break
+			}
+		}
+	}
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "поток событий закрыт клиентом")
+			return
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-subscription.Events():
+			if !ok {
+				slog.InfoContext(ctx, "поток событий закрыт сервером")
+				return
+			}
+			if replayed[event.ID] {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// Браузер передает Last-Event-ID в заголовке при переподключении, параметр запроса нужен для первого подключения
+func parseLastEventID(r *http.Request) (int64, bool, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("invalid event id %q", value)
+	}
+	return id, true, nil
+}
+
+func writeEvent(w http.ResponseWriter, event *models.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/delivery/http/dto/response"
+	"avito-backend/src/internal/health"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+type ReadinessChecker interface {
+	Ready(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	readiness ReadinessChecker
+}
+
+func NewHealthHandler(readiness ReadinessChecker) *HealthHandler {
+	return &HealthHandler{
+		readiness: readiness,
+	}
+}
+
+// Процесс жив и обслуживает запросы; внешние зависимости не проверяются, чтобы
+// недоступность базы не приводила к перезапуску всех экземпляров
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	h.send(w, http.StatusOK, response.HealthResponse{Status: "ok"})
+}
+
+// Эндпоинт открыт без авторизации, поэтому клиенту отдается только имя непройденной
+// проверки, а причина пишется в лог
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if err := h.readiness.Ready(r.Context()); err != nil {
+		check := "readiness"
+		var checkErr *health.CheckError
+		if errors.As(err, &checkErr) {
+			check = checkErr.Check
+		}
+		slog.WarnContext(r.Context(), "экземпляр не готов принимать трафик", "check", check, "error", err)
+		h.send(w, http.StatusServiceUnavailable, response.HealthResponse{Status: "unavailable", Check: check})
+		return
+	}
+	h.send(w, http.StatusOK, response.HealthResponse{Status: "ok"})
+}
+
+func (h *HealthHandler) send(w http.ResponseWriter, code int, body response.HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
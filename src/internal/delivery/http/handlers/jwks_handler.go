@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"avito-backend/src/pkg/jwt"
+	"encoding/json"
+	"net/http"
+)
+
+type JWKSHandler struct {
+	tokenManager *jwt.TokenManager
+}
+
+func NewJWKSHandler(tokenManager *jwt.TokenManager) *JWKSHandler {
+	return &JWKSHandler{
+		tokenManager: tokenManager,
+	}
+}
+
+// Открытые ключи для проверки наших токенов другими сервисами
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.tokenManager.JWKS())
+}
@@ -3,6 +3,9 @@ package handlers
 import (
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
 	"avito-backend/src/pkg/logger"
 	"avito-backend/src/pkg/metrics"
 	"encoding/json"
@@ -19,50 +22,41 @@ func (h *PVZHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 
 	var req request.CreateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.ErrorContext(ctx, "ошибка декодирования запроса", "error", err)
-		h.sendError(w, "Неверный формат запроса", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
 	if req.PVZID == "" {
-		slog.WarnContext(ctx, "ID ПВЗ не указан")
-		h.sendError(w, "ID ПВЗ обязателен", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZIDRequired))
 		return
 	}
 
 	if req.Type == "" {
-		slog.WarnContext(ctx, "тип товара не указан")
-		h.sendError(w, "Тип товара обязателен", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestProductTypeRequired))
 		return
 	}
 
 	pvzID, err := uuid.Parse(req.PVZID)
 	if err != nil {
-		slog.WarnContext(ctx, "неверный формат ID ПВЗ")
-		h.sendError(w, "Неверный формат ID ПВЗ", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
 		return
 	}
 
-	ctx = logger.WithPVZID(ctx, pvzID.String())
+	if !pvzAllowed(ctx, pvzID) {
+		httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+		return
+	}
 
+	ctx = logger.WithPVZID(ctx, pvzID.String())
 	slog.InfoContext(ctx, "создание товара", "type", req.Type)
 
-	product, err := h.pvzService.CreateProduct(pvzID, req.Type)
+	var product *models.Product
+	err = h.mutateIfMatch(ctx, r, pvzID, service.ResourcePVZ, func(svc service.PVZServiceInterface) (err error) {
+		product, err = svc.CreateProduct(pvzID, req.Type)
+		return err
+	})
 	if err != nil {
-		switch err {
-		case apperrors.ErrNoActiveReception:
-			slog.WarnContext(ctx, "нет активной приемки")
-			h.sendError(w, "Нет активной приемки", http.StatusBadRequest)
-		case apperrors.ErrPVZNotFound:
-			slog.WarnContext(ctx, "ПВЗ не найден")
-			h.sendError(w, "ПВЗ не найден", http.StatusBadRequest)
-		case apperrors.ErrInvalidProductType:
-			slog.WarnContext(ctx, "недопустимый тип товара")
-			h.sendError(w, "Недопустимый тип товара", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка создания товара", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err, pvzNotFoundAsBadRequest)
 		return
 	}
 
@@ -79,41 +73,24 @@ func (h *PVZHandler) DeleteLastProduct(w http.ResponseWriter, r *http.Request) {
 
 	pvzIDStr := chi.URLParam(r, "pvzId")
 	if pvzIDStr == "" {
-		slog.WarnContext(ctx, "не указан ID ПВЗ")
-		h.sendError(w, "ID ПВЗ обязателен", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZIDRequired))
 		return
 	}
 
 	pvzID, err := uuid.Parse(pvzIDStr)
 	if err != nil {
-		slog.WarnContext(ctx, "неверный формат ID ПВЗ")
-		h.sendError(w, "Неверный формат ID ПВЗ", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
 		return
 	}
 
 	ctx = logger.WithPVZID(ctx, pvzID.String())
-
 	slog.InfoContext(ctx, "удаление последнего товара")
 
-	err = h.pvzService.DeleteLastProduct(pvzID)
+	err = h.mutateIfMatch(ctx, r, pvzID, service.ResourcePVZ, func(svc service.PVZServiceInterface) error {
+		return svc.DeleteLastProduct(pvzID, userIDFromContext(ctx))
+	})
 	if err != nil {
-		switch err {
-		case apperrors.ErrPVZNotFound:
-			slog.WarnContext(ctx, "ПВЗ не найден")
-			h.sendError(w, "ПВЗ не найден", http.StatusBadRequest)
-		case apperrors.ErrNoActiveReception:
-			slog.WarnContext(ctx, "нет активной приемки")
-			h.sendError(w, "Нет активной приемки", http.StatusBadRequest)
-		case apperrors.ErrReceptionClosed:
-			slog.WarnContext(ctx, "приемка закрыта")
-			h.sendError(w, "Приемка уже закрыта", http.StatusBadRequest)
-		case apperrors.ErrNoProductsToDelete:
-			slog.WarnContext(ctx, "нет товаров для удаления")
-			h.sendError(w, "Нет товаров для удаления", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка удаления товара", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err, pvzNotFoundAsBadRequest)
 		return
 	}
 
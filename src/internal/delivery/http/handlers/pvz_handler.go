@@ -2,14 +2,22 @@ package handlers
 
 import (
 	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
 	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/service"
+	"avito-backend/src/pkg/logger"
 	"avito-backend/src/pkg/metrics"
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type PVZHandler struct {
@@ -27,29 +35,26 @@ func (h *PVZHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	var req request.CreatePVZRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.ErrorContext(ctx, "ошибка декодирования запроса", "error", err)
-		h.sendError(w, "Неверный формат запроса", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
 	if req.City == "" {
-		slog.WarnContext(ctx, "город не указан")
-		h.sendError(w, "Город не может быть пустым", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestCityRequired))
 		return
 	}
 
 	slog.InfoContext(ctx, "создание ПВЗ", "city", req.City)
 
-	pvz, err := h.pvzService.Create(req.City)
+	pvz, err := h.pvzService.Create(models.PVZCreate{
+		City:      models.City(req.City),
+		Address:   req.Address,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Force:     req.Force,
+	})
 	if err != nil {
-		switch err {
-		case apperrors.ErrInvalidCity:
-			slog.WarnContext(ctx, "недопустимый город", "city", req.City)
-			h.sendError(w, "Недопустимый город", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка создания ПВЗ", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err)
 		return
 	}
 
@@ -72,8 +77,7 @@ func (h *PVZHandler) GetPVZs(w http.ResponseWriter, r *http.Request) {
 		var err error
 		startDate, err = time.Parse(time.RFC3339, startDateStr)
 		if err != nil {
-			slog.WarnContext(ctx, "неверный формат начальной даты", "start_date", startDateStr)
-			h.sendError(w, "Неверный формат начальной даты", http.StatusBadRequest)
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestStartDate))
 			return
 		}
 	}
@@ -82,8 +86,7 @@ func (h *PVZHandler) GetPVZs(w http.ResponseWriter, r *http.Request) {
 		var err error
 		endDate, err = time.Parse(time.RFC3339, endDateStr)
 		if err != nil {
-			slog.WarnContext(ctx, "неверный формат конечной даты", "end_date", endDateStr)
-			h.sendError(w, "Неверный формат конечной даты", http.StatusBadRequest)
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestEndDate))
 			return
 		}
 	}
@@ -93,8 +96,7 @@ func (h *PVZHandler) GetPVZs(w http.ResponseWriter, r *http.Request) {
 		var err error
 		page, err = strconv.Atoi(pageStr)
 		if err != nil || page < 1 {
-			slog.WarnContext(ctx, "неверный номер страницы", "page", pageStr)
-			h.sendError(w, "Неверный номер страницы", http.StatusBadRequest)
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPage))
 			return
 		}
 	}
@@ -104,45 +106,301 @@ func (h *PVZHandler) GetPVZs(w http.ResponseWriter, r *http.Request) {
 		var err error
 		limit, err = strconv.Atoi(limitStr)
 		if err != nil || limit < 1 || limit > 30 {
-			slog.WarnContext(ctx, "неверное количество элементов на странице", "limit", limitStr)
-			h.sendError(w, "Неверное количество элементов на странице", http.StatusBadRequest)
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPageSize))
 			return
 		}
 	}
 
+	includeArchived := false
+	if includeArchivedStr := r.URL.Query().Get("includeArchived"); includeArchivedStr != "" {
+		var err error
+		includeArchived, err = strconv.ParseBool(includeArchivedStr)
+		if err != nil {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestIncludeArchived))
+			return
+		}
+	}
+
+	includeDeleted := false
+	if includeDeletedStr := r.URL.Query().Get("includeDeleted"); includeDeletedStr != "" {
+		var err error
+		includeDeleted, err = strconv.ParseBool(includeDeletedStr)
+		if err != nil {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestIncludeDeleted))
+			return
+		}
+	}
+
+	if includeDeleted && !hasPermission(ctx, models.PermProductReadDeleted) {
+		httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+		return
+	}
+
 	offset := (page - 1) * limit
 
 	slog.InfoContext(ctx, "получение списка ПВЗ",
 		"start_date", startDateStr,
 		"end_date", endDateStr,
 		"page", page,
-		"limit", limit)
+		"limit", limit,
+		"include_deleted", includeDeleted,
+		"include_archived", includeArchived)
 
-	pvzs, err := h.pvzService.GetPVZsWithReceptions(startDate, endDate, offset, limit)
+	pvzs, err := h.pvzService.GetPVZsWithReceptions(models.PVZFilter{
+		StartDate:       startDate,
+		EndDate:         endDate,
+		Offset:          offset,
+		Limit:           limit,
+		IncludeDeleted:  includeDeleted,
+		IncludeArchived: includeArchived,
+		PVZIDs:          pvzScopeFromContext(ctx),
+	})
 	if err != nil {
-		switch err {
-		case apperrors.ErrInvalidDateRange:
-			slog.WarnContext(ctx, "неверный диапазон дат")
-			h.sendError(w, "Неверный диапазон дат", http.StatusBadRequest)
-		case apperrors.ErrInvalidPagination:
-			slog.WarnContext(ctx, "неверные параметры пагинации")
-			h.sendError(w, "Неверные параметры пагинации", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка получения списка ПВЗ", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err)
 		return
 	}
 
 	slog.InfoContext(ctx, "список ПВЗ получен", "count", len(pvzs))
 
+	if notModified(w, r, models.PVZListVersion(pvzs)) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pvzs)
+}
+
+func (h *PVZHandler) GetPVZ(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	if !pvzAllowed(ctx, pvzID) {
+		httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+		return
+	}
+
+	pvz, err := h.pvzService.GetPVZ(pvzID)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	if notModified(w, r, pvz.Version()) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pvz)
+}
+
+func (h *PVZHandler) GetNearby(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+	if errLat != nil || errLon != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidCoordinates)
+		return
+	}
+
+	radiusKm := 1.0
+	if radiusStr := query.Get("radiusKm"); radiusStr != "" {
+		var err error
+		radiusKm, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil {
+			httperror.Write(ctx, w, r, apperrors.ErrInvalidRadius)
+			return
+		}
+	}
+
+	slog.InfoContext(ctx, "поиск ближайших ПВЗ", "lat", lat, "lon", lon, "radius_km", radiusKm)
+
+	pvzs, err := h.pvzService.FindNearby(lat, lon, radiusKm)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	if scope := pvzScopeFromContext(ctx); scope != nil {
+		allowed := pvzs[:0]
+		for _, pvz := range pvzs {
+			if pvzAllowed(ctx, pvz.PVZ.ID) {
+				allowed = append(allowed, pvz)
+			}
+		}
+		pvzs = allowed
+	}
+
+	slog.InfoContext(ctx, "ближайшие ПВЗ найдены", "count", len(pvzs))
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(pvzs)
 }
 
-func (h *PVZHandler) sendError(w http.ResponseWriter, message string, code int) {
+func (h *PVZHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	var req request.UpdatePVZRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	update := models.PVZUpdate{}
+	if req.City != nil {
+		if *req.City == "" {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestCityRequired))
+			return
+		}
+		city := models.City(*req.City)
+		update.City = &city
+	}
+	update.Address = req.Address
+	update.Latitude = req.Latitude
+	update.Longitude = req.Longitude
+
+	slog.InfoContext(ctx, "обновление ПВЗ")
+
+	var pvz *models.PVZ
+	err := h.mutateIfMatch(ctx, r, pvzID, service.ResourcePVZ, func(svc service.PVZServiceInterface) (err error) {
+		pvz, err = svc.Update(pvzID, update)
+		return err
+	})
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "ПВЗ обновлен")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pvz)
+}
+
+func (h *PVZHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.PVZInactive)
+}
+
+func (h *PVZHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.PVZActive)
+}
+
+func (h *PVZHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, models.PVZArchived)
+}
+
+func (h *PVZHandler) changeStatus(w http.ResponseWriter, r *http.Request, status models.PVZStatus) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	slog.InfoContext(ctx, "изменение статуса ПВЗ", "status", status)
+
+	var pvz *models.PVZ
+	err := h.mutateIfMatch(ctx, r, pvzID, service.ResourcePVZ, func(svc service.PVZServiceInterface) (err error) {
+		pvz, err = svc.ChangeStatus(pvzID, status)
+		return err
+	})
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "статус ПВЗ изменен", "status", pvz.Status)
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"message": message})
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pvz)
+}
+
+func (h *PVZHandler) parsePVZID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	pvzIDStr := chi.URLParam(r, "pvzId")
+	if pvzIDStr == "" {
+		httperror.Write(r.Context(), w, r, invalidRequest(apperrors.KeyInvalidRequestPVZIDRequired))
+		return uuid.Nil, false
+	}
+
+	pvzID, err := uuid.Parse(pvzIDStr)
+	if err != nil {
+		httperror.Write(r.Context(), w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
+		return uuid.Nil, false
+	}
+
+	return pvzID, true
+}
+
+func userRoleFromContext(ctx context.Context) models.Role {
+	role, _ := ctx.Value(ctxkeys.UserRoleKey).(string)
+	return models.Role(role)
+}
+
+// Право роли с учетом ограничений API-ключа, если запрос авторизован ключом
+func hasPermission(ctx context.Context, permission models.Permission) bool {
+	if key, ok := ctx.Value(ctxkeys.APIKeyKey).(*models.APIKey); ok {
+		return key.Allows(permission)
+	}
+	return userRoleFromContext(ctx).Can(permission)
+}
+
+// ПВЗ, которыми ограничен API-ключ; nil — ограничений нет
+func pvzScopeFromContext(ctx context.Context) []uuid.UUID {
+	if key, ok := ctx.Value(ctxkeys.APIKeyKey).(*models.APIKey); ok && len(key.PVZIDs) > 0 {
+		return key.PVZIDs
+	}
+	return nil
+}
+
+func pvzAllowed(ctx context.Context, pvzID uuid.UUID) bool {
+	key, ok := ctx.Value(ctxkeys.APIKeyKey).(*models.APIKey)
+	return !ok || key.AllowsPVZ(pvzID)
+}
+
+// Управлять API-ключами можно только по токену пользователя, иначе ключ мог бы
+// выпустить ключ с правами шире своих. Отвечает 403 и возвращает true для API-ключа
+func denyAPIKey(ctx context.Context, w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := ctx.Value(ctxkeys.APIKeyKey).(*models.APIKey); !ok {
+		return false
+	}
+	httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+	return true
+}
+
+// Вебхуки и пользователи не привязаны к ПВЗ, поэтому ключу с ограничением по ПВЗ
+// они недоступны. Отвечает 403 и возвращает true для такого ключа
+func denyPVZScopedKey(ctx context.Context, w http.ResponseWriter, r *http.Request) bool {
+	if pvzScopeFromContext(ctx) == nil {
+		return false
+	}
+	httperror.Write(ctx, w, r, apperrors.ErrForbidden)
+	return true
+}
+
+// Для токенов из dummyLogin идентификатора нет, тогда возвращается uuid.Nil
+func userIDFromContext(ctx context.Context) uuid.UUID {
+	userID, _ := ctx.Value(ctxkeys.UserIDKey).(string)
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
 }
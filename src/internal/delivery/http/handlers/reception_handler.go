@@ -3,6 +3,9 @@ package handlers
 import (
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
 	"avito-backend/src/pkg/metrics"
 	"encoding/json"
 	"net/http"
@@ -18,40 +21,36 @@ func (h *PVZHandler) CreateReception(w http.ResponseWriter, r *http.Request) {
 
 	var req request.CreateReceptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.ErrorContext(ctx, "ошибка декодирования запроса", "error", err)
-		h.sendError(w, "Неверный формат запроса", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
 		return
 	}
 
 	if req.PVZID == "" {
-		slog.WarnContext(ctx, "ID ПВЗ не указан")
-		h.sendError(w, "ID ПВЗ обязателен", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZIDRequired))
 		return
 	}
 
 	pvzID, err := uuid.Parse(req.PVZID)
 	if err != nil {
-		slog.WarnContext(ctx, "неверный формат ID ПВЗ")
-		h.sendError(w, "Неверный формат ID ПВЗ", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
+		return
+	}
+
+	if !pvzAllowed(ctx, pvzID) {
+		httperror.Write(ctx, w, r, apperrors.ErrForbidden)
 		return
 	}
 
 	ctx = logger.WithPVZID(ctx, pvzID.String())
 	slog.InfoContext(ctx, "создание приемки")
 
-	reception, err := h.pvzService.CreateReception(pvzID)
+	var reception *models.Reception
+	err = h.mutateIfMatch(ctx, r, pvzID, service.ResourcePVZ, func(svc service.PVZServiceInterface) (err error) {
+		reception, err = svc.CreateReception(pvzID)
+		return err
+	})
 	if err != nil {
-		switch err {
-		case apperrors.ErrPVZNotFound:
-			slog.WarnContext(ctx, "ПВЗ не найден")
-			h.sendError(w, "ПВЗ не найден", http.StatusBadRequest)
-		case apperrors.ErrActiveReceptionExists:
-			slog.WarnContext(ctx, "уже есть активная приемка")
-			h.sendError(w, "Уже есть активная приемка", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка создания приемки", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err, pvzNotFoundAsBadRequest)
 		return
 	}
 
@@ -68,37 +67,26 @@ func (h *PVZHandler) CloseLastReception(w http.ResponseWriter, r *http.Request)
 
 	pvzIDStr := chi.URLParam(r, "pvzId")
 	if pvzIDStr == "" {
-		slog.WarnContext(ctx, "не указан ID ПВЗ")
-		h.sendError(w, "ID ПВЗ обязателен", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZIDRequired))
 		return
 	}
 
 	pvzID, err := uuid.Parse(pvzIDStr)
 	if err != nil {
-		slog.WarnContext(ctx, "неверный формат ID ПВЗ")
-		h.sendError(w, "Неверный формат ID ПВЗ", http.StatusBadRequest)
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
 		return
 	}
 
 	ctx = logger.WithPVZID(ctx, pvzID.String())
 	slog.InfoContext(ctx, "закрытие последней приемки")
 
-	reception, err := h.pvzService.CloseLastReception(pvzID)
+	var reception *models.Reception
+	err = h.mutateIfMatch(ctx, r, pvzID, service.ResourcePVZ, func(svc service.PVZServiceInterface) (err error) {
+		reception, err = svc.CloseLastReception(pvzID)
+		return err
+	})
 	if err != nil {
-		switch err {
-		case apperrors.ErrPVZNotFound:
-			slog.WarnContext(ctx, "ПВЗ не найден")
-			h.sendError(w, "ПВЗ не найден", http.StatusBadRequest)
-		case apperrors.ErrNoActiveReception:
-			slog.WarnContext(ctx, "нет активной приемки")
-			h.sendError(w, "Нет активной приемки", http.StatusBadRequest)
-		case apperrors.ErrReceptionAlreadyClosed:
-			slog.WarnContext(ctx, "приемка уже закрыта")
-			h.sendError(w, "Приемка уже закрыта", http.StatusBadRequest)
-		default:
-			slog.ErrorContext(ctx, "ошибка закрытия приемки", "error", err)
-			h.sendError(w, "Внутренняя ошибка сервера", http.StatusInternalServerError)
-		}
+		httperror.Write(ctx, w, r, err, pvzNotFoundAsBadRequest)
 		return
 	}
 
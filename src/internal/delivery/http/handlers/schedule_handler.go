@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"avito-backend/src/pkg/logger"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *PVZHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	schedule, err := h.pvzService.GetSchedule(pvzID)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	if notModified(w, r, schedule.Version()) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *PVZHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	var req request.UpdateScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	update := models.PVZScheduleUpdate{
+		Timezone:      req.Timezone,
+		WorkingHours:  make([]models.WorkingHours, 0, len(req.WorkingHours)),
+		OverrideUntil: req.OverrideUntil,
+	}
+	for _, item := range req.WorkingHours {
+		opens, openErr := models.ParseClockTime(item.Opens)
+		closes, closeErr := models.ParseClockTime(item.Closes)
+		if openErr != nil || closeErr != nil {
+			httperror.Write(ctx, w, r, apperrors.ErrInvalidSchedule)
+			return
+		}
+		update.WorkingHours = append(update.WorkingHours, models.WorkingHours{
+			Weekday: time.Weekday(item.Weekday),
+			Opens:   opens,
+			Closes:  closes,
+		})
+	}
+
+	slog.InfoContext(ctx, "обновление графика работы ПВЗ", "timezone", req.Timezone)
+
+	var schedule *models.PVZSchedule
+	err := h.mutateIfMatch(ctx, r, pvzID, service.ResourceSchedule, func(svc service.PVZServiceInterface) (err error) {
+		schedule, err = svc.UpdateSchedule(pvzID, update)
+		return err
+	})
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "график работы ПВЗ обновлен")
+
+	w.Header().Set("ETag", quoteETag(schedule.Version()))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *PVZHandler) SetCalendarException(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+	date := chi.URLParam(r, "date")
+
+	var req request.CalendarExceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	exception := models.CalendarException{
+		Date:    date,
+		Closed:  req.Closed,
+		Comment: req.Comment,
+	}
+	for _, field := range []struct {
+		value  *string
+		target **models.ClockTime
+	}{{req.Opens, &exception.Opens}, {req.Closes, &exception.Closes}} {
+		if field.value == nil {
+			continue
+		}
+		clock, err := models.ParseClockTime(*field.value)
+		if err != nil {
+			httperror.Write(ctx, w, r, apperrors.ErrInvalidSchedule)
+			return
+		}
+		*field.target = &clock
+	}
+
+	slog.InfoContext(ctx, "изменение календаря ПВЗ", "date", date, "closed", req.Closed)
+
+	var schedule *models.PVZSchedule
+	err := h.mutateIfMatch(ctx, r, pvzID, service.ResourceSchedule, func(svc service.PVZServiceInterface) (err error) {
+		schedule, err = svc.SetCalendarException(pvzID, exception)
+		return err
+	})
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(schedule.Version()))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *PVZHandler) DeleteCalendarException(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+	date := chi.URLParam(r, "date")
+
+	slog.InfoContext(ctx, "удаление исключения из календаря ПВЗ", "date", date)
+
+	err := h.mutateIfMatch(ctx, r, pvzID, service.ResourceSchedule, func(svc service.PVZServiceInterface) error {
+		return svc.DeleteCalendarException(pvzID, date)
+	})
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"avito-backend/src/pkg/logger"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+func (h *PVZHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	settings, err := h.pvzService.GetSettings(pvzID)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	if notModified(w, r, settings.Version()) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settings)
+}
+
+func (h *PVZHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pvzID, ok := h.parsePVZID(w, r)
+	if !ok {
+		return
+	}
+	ctx = logger.WithPVZID(ctx, pvzID.String())
+
+	var req request.UpdatePVZSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	update := models.PVZSettingsUpdate{
+		MaxProductsPerReception: req.MaxProductsPerReception,
+		AllowEmptyClose:         req.AllowEmptyClose,
+	}
+	if req.AllowedProductTypes != nil {
+		update.AllowedProductTypes = make([]models.ProductType, 0, len(req.AllowedProductTypes))
+		for _, productType := range req.AllowedProductTypes {
+			update.AllowedProductTypes = append(update.AllowedProductTypes, models.ProductType(productType))
+		}
+	}
+
+	slog.InfoContext(ctx, "обновление настроек ПВЗ")
+
+	var settings *models.PVZSettings
+	err := h.mutateIfMatch(ctx, r, pvzID, service.ResourceSettings, func(svc service.PVZServiceInterface) (err error) {
+		settings, err = svc.UpdateSettings(pvzID, update)
+		return err
+	})
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "настройки ПВЗ обновлены")
+
+	w.Header().Set("ETag", quoteETag(settings.Version()))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settings)
+}
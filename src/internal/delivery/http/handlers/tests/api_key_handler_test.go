@@ -0,0 +1,210 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateKey(input models.APIKey) (*models.APIKey, string, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*models.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) ListKeys() ([]*models.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeKey(id, actorID uuid.UUID) error {
+	args := m.Called(id, actorID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(rawKey string) (*models.APIKey, error) {
+	args := m.Called(rawKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func TestAPIKeyHandler_Create(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockAPIKeyService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Success",
+			body: `{"name":"etl","role":"employee","permissions":["pvz:read"],"pvzIds":["` + pvzID.String() + `"]}`,
+			mockBehavior: func(s *MockAPIKeyService) {
+				s.On("CreateKey", models.APIKey{
+					Name:        "etl",
+					Role:        models.EmployeeRole,
+					Permissions: []models.Permission{models.PermPVZRead},
+					PVZIDs:      []uuid.UUID{pvzID},
+				}).Return(&models.APIKey{ID: uuid.New(), Name: "etl"}, "pvzk_secret", nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Invalid PVZ ID",
+			body:         `{"name":"etl","role":"employee","pvzIds":["123"]}`,
+			mockBehavior: func(s *MockAPIKeyService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
+		},
+		{
+			name: "Invalid Scope",
+			body: `{"name":"etl","role":"employee","permissions":["user:manage"]}`,
+			mockBehavior: func(s *MockAPIKeyService) {
+				s.On("CreateKey", mock.AnythingOfType("models.APIKey")).Return(nil, "", apperrors.ErrInvalidAPIKeyScope)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверные параметры API-ключа\",\"code\":\"invalid_api_key_scope\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewAPIKeyHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/api-keys", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Create(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), `"key":"pvzk_secret"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyHandler_Revoke(t *testing.T) {
+	keyID := uuid.New()
+
+	tests := []struct {
+		name         string
+		keyID        string
+		mockBehavior func(s *MockAPIKeyService)
+		expectedCode int
+	}{
+		{
+			name:  "Success",
+			keyID: keyID.String(),
+			mockBehavior: func(s *MockAPIKeyService) {
+				s.On("RevokeKey", keyID, uuid.Nil).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:  "Not Found",
+			keyID: keyID.String(),
+			mockBehavior: func(s *MockAPIKeyService) {
+				s.On("RevokeKey", keyID, uuid.Nil).Return(apperrors.ErrAPIKeyNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid ID",
+			keyID:        "abc",
+			mockBehavior: func(s *MockAPIKeyService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewAPIKeyHandler(mockService)
+
+			req := httptest.NewRequest("DELETE", "/api-keys/"+tt.keyID, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("keyId", tt.keyID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			handler.Revoke(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyHandler_RejectsAPIKey(t *testing.T) {
+	keys := map[string]*models.APIKey{
+		"Scoped Key": {
+			ID:          uuid.New(),
+			Role:        models.ModeratorRole,
+			Permissions: []models.Permission{models.PermAPIKeyManage},
+			PVZIDs:      []uuid.UUID{uuid.New()},
+		},
+		"Unscoped Key": {ID: uuid.New(), Role: models.ModeratorRole},
+	}
+
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			handler := handlers.NewAPIKeyHandler(mockService)
+			keyID := uuid.NewString()
+
+			for _, call := range []struct {
+				method string
+				path   string
+				body   string
+				serve  http.HandlerFunc
+			}{
+				{"POST", "/api-keys", `{"name":"wide","role":"moderator"}`, handler.Create},
+				{"GET", "/api-keys", "", handler.List},
+				{"DELETE", "/api-keys/" + keyID, "", handler.Revoke},
+			} {
+				req := httptest.NewRequest(call.method, call.path, bytes.NewBufferString(call.body))
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("keyId", keyID)
+				ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+				ctx = context.WithValue(ctx, ctxkeys.APIKeyKey, key)
+				w := httptest.NewRecorder()
+
+				call.serve(w, req.WithContext(ctx))
+
+				assert.Equal(t, http.StatusForbidden, w.Code, call.method+" "+call.path)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
 	"avito-backend/src/internal/delivery/http/dto/request"
 	"avito-backend/src/internal/delivery/http/handlers"
 	"avito-backend/src/internal/domain/models"
@@ -30,8 +32,8 @@ func (m *MockAuthService) Register(email, password, role string) (*models.User,
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockAuthService) Login(email, password string) (string, error) {
-	args := m.Called(email, password)
+func (m *MockAuthService) Login(email, password, clientIP string) (string, error) {
+	args := m.Called(email, password, clientIP)
 	return args.String(0), args.Error(1)
 }
 
@@ -40,6 +42,31 @@ func (m *MockAuthService) GenerateToken(role string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthService) Unlock(email, clientIP string, actorID uuid.UUID) error {
+	args := m.Called(email, clientIP, actorID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ChangePassword(userID uuid.UUID, oldPassword, newPassword string) (string, error) {
+	args := m.Called(userID, oldPassword, newPassword)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthService) RequestPasswordReset(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(token, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ValidateSession(userID string, tokenVersion int) error {
+	args := m.Called(userID, tokenVersion)
+	return args.Error(0)
+}
+
 func TestAuthHandler_Register(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -87,7 +114,7 @@ func TestAuthHandler_Register(t *testing.T) {
 				s.On("Register", "test@example.com", "password123", "employee").Return(nil, errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 		{
 			name: "Empty Email",
@@ -99,7 +126,7 @@ func TestAuthHandler_Register(t *testing.T) {
 			mockBehavior: func(s *MockAuthService) {
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Отсутствуют обязательные поля\"}\n",
+			expectedBody: "{\"message\":\"Отсутствуют обязательные поля\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name: "Empty Password",
@@ -111,7 +138,7 @@ func TestAuthHandler_Register(t *testing.T) {
 			mockBehavior: func(s *MockAuthService) {
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Отсутствуют обязательные поля\"}\n",
+			expectedBody: "{\"message\":\"Отсутствуют обязательные поля\",\"code\":\"invalid_request\"}\n",
 		},
 	}
 
@@ -148,7 +175,7 @@ func TestAuthHandler_Login(t *testing.T) {
 				Password: "password123",
 			},
 			mockBehavior: func(s *MockAuthService) {
-				s.On("Login", "test@example.com", "password123").Return("test-token", nil)
+				s.On("Login", "test@example.com", "password123", "192.0.2.1").Return("test-token", nil)
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: "\"test-token\"\n",
@@ -160,10 +187,34 @@ func TestAuthHandler_Login(t *testing.T) {
 				Password: "wrongpass",
 			},
 			mockBehavior: func(s *MockAuthService) {
-				s.On("Login", "wrong@example.com", "wrongpass").Return("", apperrors.ErrInvalidCredentials)
+				s.On("Login", "wrong@example.com", "wrongpass", "192.0.2.1").Return("", apperrors.ErrInvalidCredentials)
 			},
 			expectedCode: http.StatusUnauthorized,
 		},
+		{
+			name: "Account Locked",
+			input: request.LoginRequest{
+				Email:    "locked@example.com",
+				Password: "password123",
+			},
+			mockBehavior: func(s *MockAuthService) {
+				s.On("Login", "locked@example.com", "password123", "192.0.2.1").Return("", apperrors.ErrAccountLocked)
+			},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "{\"message\":\"Неверные учетные данные\",\"code\":\"invalid_credentials\"}\n",
+		},
+		{
+			name: "Account Disabled",
+			input: request.LoginRequest{
+				Email:    "disabled@example.com",
+				Password: "password123",
+			},
+			mockBehavior: func(s *MockAuthService) {
+				s.On("Login", "disabled@example.com", "password123", "192.0.2.1").Return("", apperrors.ErrAccountDisabled)
+			},
+			expectedCode: http.StatusForbidden,
+			expectedBody: "{\"message\":\"Учетная запись отключена\",\"code\":\"account_disabled\"}\n",
+		},
 		{
 			name: "Service Error",
 			input: request.LoginRequest{
@@ -171,10 +222,10 @@ func TestAuthHandler_Login(t *testing.T) {
 				Password: "password123",
 			},
 			mockBehavior: func(s *MockAuthService) {
-				s.On("Login", "test@example.com", "password123").Return("", errors.New("service error"))
+				s.On("Login", "test@example.com", "password123", "192.0.2.1").Return("", errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 		{
 			name: "Empty Email",
@@ -185,7 +236,7 @@ func TestAuthHandler_Login(t *testing.T) {
 			mockBehavior: func(s *MockAuthService) {
 			},
 			expectedCode: http.StatusUnauthorized,
-			expectedBody: "{\"message\":\"Отсутствуют учетные данные\"}\n",
+			expectedBody: "{\"message\":\"Отсутствуют учетные данные\",\"code\":\"invalid_credentials\"}\n",
 		},
 		{
 			name: "Empty Password",
@@ -196,7 +247,7 @@ func TestAuthHandler_Login(t *testing.T) {
 			mockBehavior: func(s *MockAuthService) {
 			},
 			expectedCode: http.StatusUnauthorized,
-			expectedBody: "{\"message\":\"Отсутствуют учетные данные\"}\n",
+			expectedBody: "{\"message\":\"Отсутствуют учетные данные\",\"code\":\"invalid_credentials\"}\n",
 		},
 	}
 
@@ -260,7 +311,7 @@ func TestAuthHandler_DummyLogin(t *testing.T) {
 				s.On("GenerateToken", role).Return("", apperrors.ErrInvalidRole)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Недопустимая роль\"}\n",
+			expectedBody: "{\"message\":\"Недопустимая роль\",\"code\":\"invalid_role\"}\n",
 		},
 		{
 			name: "Empty Role",
@@ -271,7 +322,7 @@ func TestAuthHandler_DummyLogin(t *testing.T) {
 				s.On("GenerateToken", role).Return("", apperrors.ErrInvalidRole)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Недопустимая роль\"}\n",
+			expectedBody: "{\"message\":\"Недопустимая роль\",\"code\":\"invalid_role\"}\n",
 		},
 		{
 			name: "Service Error",
@@ -282,7 +333,7 @@ func TestAuthHandler_DummyLogin(t *testing.T) {
 				s.On("GenerateToken", role).Return("", errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 	}
 
@@ -317,7 +368,7 @@ func TestAuthHandler_DummyLogin_InvalidJSON(t *testing.T) {
 	handler.DummyLogin(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "{\"message\":\"Неверный формат запроса\"}\n", w.Body.String())
+	assert.Equal(t, "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n", w.Body.String())
 }
 
 func TestAuthHandler_Register_InvalidJSON(t *testing.T) {
@@ -330,7 +381,7 @@ func TestAuthHandler_Register_InvalidJSON(t *testing.T) {
 	handler.Register(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "{\"message\":\"Неверный формат запроса\"}\n", w.Body.String())
+	assert.Equal(t, "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n", w.Body.String())
 }
 
 func TestAuthHandler_Login_InvalidJSON(t *testing.T) {
@@ -343,5 +394,252 @@ func TestAuthHandler_Login_InvalidJSON(t *testing.T) {
 	handler.Login(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "{\"message\":\"Неверный формат запроса\"}\n", w.Body.String())
+	assert.Equal(t, "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n", w.Body.String())
+}
+
+func TestAuthHandler_Unlock(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockAuthService)
+		expectedCode int
+	}{
+		{
+			name: "Unlock Email",
+			body: `{"email":"user@example.com"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("Unlock", "user@example.com", "", uuid.Nil).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "Unlock IP",
+			body: `{"ip":"10.0.0.7"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("Unlock", "", "10.0.0.7", uuid.Nil).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Invalid IP",
+			body:         `{"ip":"not-an-ip"}`,
+			mockBehavior: func(s *MockAuthService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Nothing To Unlock",
+			body: `{}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("Unlock", "", "", uuid.Nil).Return(apperrors.ErrValidationFailed)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Service Error",
+			body: `{"email":"user@example.com"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("Unlock", "user@example.com", "", uuid.Nil).Return(errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewAuthHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/auth/unlock", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Unlock(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_ChangePassword(t *testing.T) {
+	userID := uuid.New()
+	weak := apperrors.ErrWeakPassword.WithMessageKey("weak_password.too_short").WithDetails(map[string]any{"minLength": 8})
+
+	tests := []struct {
+		name         string
+		userID       string
+		body         string
+		mockBehavior func(s *MockAuthService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "Success",
+			userID: userID.String(),
+			body:   `{"oldPassword":"old-pass1","newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("ChangePassword", userID, "old-pass1", "new-pass1").Return("new-token", nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"new-token"`,
+		},
+		{
+			name:         "Dummy Token",
+			body:         `{"oldPassword":"old-pass1","newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Missing Fields",
+			userID:       userID.String(),
+			body:         `{"oldPassword":"old-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Wrong Old Password",
+			userID: userID.String(),
+			body:   `{"oldPassword":"wrong","newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("ChangePassword", userID, "wrong", "new-pass1").Return("", apperrors.ErrInvalidCredentials)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Weak Password",
+			userID: userID.String(),
+			body:   `{"oldPassword":"old-pass1","newPassword":"short"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("ChangePassword", userID, "old-pass1", "short").Return("", weak)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"Пароль не соответствует требованиям: пароль короче 8 символов","code":"weak_password","details":{"minLength":8}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewAuthHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/me/password", bytes.NewBufferString(tt.body))
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserIDKey, tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ChangePassword(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_RequestPasswordReset(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockAuthService)
+		expectedCode int
+	}{
+		{
+			name: "Accepted",
+			body: `{"email":"user@example.com"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("RequestPasswordReset", "user@example.com").Return(nil)
+			},
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "Invalid Email",
+			body:         `{"email":"not-an-email"}`,
+			mockBehavior: func(s *MockAuthService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Service Error",
+			body: `{"email":"user@example.com"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("RequestPasswordReset", "user@example.com").Return(errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewAuthHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/password/reset-request", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.RequestPasswordReset(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockAuthService)
+		expectedCode int
+	}{
+		{
+			name: "Success",
+			body: `{"token":"abc","newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("ResetPassword", "abc", "new-pass1").Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Missing Token",
+			body:         `{"newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid Token",
+			body: `{"token":"used","newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("ResetPassword", "used", "new-pass1").Return(apperrors.ErrInvalidResetToken)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Service Error",
+			body: `{"token":"abc","newPassword":"new-pass1"}`,
+			mockBehavior: func(s *MockAuthService) {
+				s.On("ResetPassword", "abc", "new-pass1").Return(errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewAuthHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ResetPassword(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
 }
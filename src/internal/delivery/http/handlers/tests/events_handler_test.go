@@ -0,0 +1,172 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/stream"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubEventSource struct {
+	mu       sync.Mutex
+	events   []*models.OutboxEvent
+	replayed chan struct{}
+}
+
+func (s *stubEventSource) LatestEventID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) == 0 {
+		return 0, nil
+	}
+	return s.events[len(s.events)-1].ID, nil
+}
+
+func (s *stubEventSource) ListEventsAfter(afterID int64, filter models.EventFilter, limit int) ([]*models.OutboxEvent, error) {
+	s.mu.Lock()
+	result := make([]*models.OutboxEvent, 0)
+	for _, event := range s.events {
+		if event.ID > afterID && filter.Matches(event) && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	s.mu.Unlock()
+
+	// Запросы хаба идут без фильтра, с фильтром — чтение истории обработчиком
+	if filter != (models.EventFilter{}) && s.replayed != nil {
+		s.replayed <- struct{}{}
+	}
+	return result, nil
+}
+
+func (s *stubEventSource) add(event *models.OutboxEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func TestEventsHandler_StreamPVZ(t *testing.T) {
+	pvzID := uuid.New()
+	otherPVZ := uuid.New()
+	source := &stubEventSource{replayed: make(chan struct{}, 1)}
+	source.add(&models.OutboxEvent{ID: 1, Type: models.ReceptionCreated, PVZID: pvzID})
+	source.add(&models.OutboxEvent{ID: 2, Type: models.ProductAdded, PVZID: pvzID})
+	source.add(&models.OutboxEvent{ID: 3, Type: models.ProductAdded, PVZID: otherPVZ})
+
+	// Хаб еще не видел событие 2, поэтому оно придет и из истории, и из подписки
+	hub := stream.NewHub(source, time.Second, time.Second)
+	source.mu.Lock()
+	events := source.events
+	source.events = events[:1]
+	source.mu.Unlock()
+	require.NoError(t, hub.Poll(time.Now()))
+	source.mu.Lock()
+	source.events = events
+	source.mu.Unlock()
+
+	handler := handlers.NewEventsHandler(hub, source, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("pvzId", pvzID.String())
+	req := httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/events", nil).
+		WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	req.Header.Set("Last-Event-ID", "1")
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		handler.StreamPVZ(rec, req)
+		close(done)
+	}()
+
+	<-source.replayed
+	source.add(&models.OutboxEvent{ID: 4, Type: models.ProductRemoved, PVZID: pvzID})
+	require.NoError(t, hub.Poll(time.Now()))
+
+	// Даем обработчику дочитать подписку до отмены запроса
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := rec.Body.String()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.True(t, rec.Flushed)
+	assert.True(t, strings.HasPrefix(body, "retry: 3000\n\n"))
+	assert.Equal(t, 1, strings.Count(body, "id: 2\nevent: ProductAdded\n"), "событие 2 должно прийти один раз")
+	assert.Contains(t, body, "id: 4\nevent: ProductRemoved\ndata: {")
+	assert.NotContains(t, body, "id: 1\n")
+	assert.NotContains(t, body, "id: 3\n")
+}
+
+func TestEventsHandler_InvalidRequests(t *testing.T) {
+	hub := stream.NewHub(&stubEventSource{}, time.Second, time.Second)
+	handler := handlers.NewEventsHandler(hub, &stubEventSource{}, time.Hour)
+
+	tests := []struct {
+		name    string
+		pvzID   string
+		url     string
+		header  string
+		handler func(http.ResponseWriter, *http.Request)
+	}{
+		{
+			name:    "Invalid PVZ ID",
+			pvzID:   "invalid",
+			url:     "/pvz/invalid/events",
+			handler: handler.StreamPVZ,
+		},
+		{
+			name:    "Invalid Last-Event-ID",
+			pvzID:   uuid.New().String(),
+			url:     "/pvz/x/events",
+			header:  "abc",
+			handler: handler.StreamPVZ,
+		},
+		{
+			name:    "Invalid lastEventId query",
+			pvzID:   uuid.New().String(),
+			url:     "/pvz/x/events?lastEventId=-5",
+			handler: handler.StreamPVZ,
+		},
+		{
+			name:    "Invalid city",
+			url:     "/pvz/events?city=Берлин",
+			handler: handler.StreamCity,
+		},
+		{
+			name:    "Missing city",
+			url:     "/pvz/events",
+			handler: handler.StreamCity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("pvzId", tt.pvzID)
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			if tt.header != "" {
+				req.Header.Set("Last-Event-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/health"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubReadiness struct {
+	err error
+}
+
+func (s stubReadiness) Ready(ctx context.Context) error {
+	return s.err
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	handler := handlers.NewHealthHandler(stubReadiness{err: errors.New("database unreachable")})
+
+	w := httptest.NewRecorder()
+	handler.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code, "живость не зависит от базы")
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "Ready",
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"ok"}`,
+		},
+		{
+			name:     "Database unreachable",
+			err:      &health.CheckError{Check: "database", Err: errors.New("dial tcp 10.0.0.5:5432: connection refused")},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"unavailable","check":"database"}`,
+		},
+		{
+			name:     "Unnamed check",
+			err:      errors.New("pq: password authentication failed for user \"app\""),
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"unavailable","check":"readiness"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := handlers.NewHealthHandler(stubReadiness{err: tt.err})
+
+			w := httptest.NewRecorder()
+			handler.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func withPVZID(req *http.Request, pvzID string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("pvzId", pvzID)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestPVZHandler_GetPVZ_IfNoneMatch(t *testing.T) {
+	pvzID := uuid.New()
+	receptionAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	pvz := &models.PVZWithReceptions{
+		PVZ: &models.PVZ{ID: pvzID, City: models.Moscow, Status: models.PVZActive, RegistrationDate: receptionAt.AddDate(0, -1, 0)},
+		Receptions: []models.ReceptionWithProducts{{
+			Reception: &models.Reception{ID: uuid.New(), PVZID: pvzID, DateTime: receptionAt, Status: models.InProgress},
+			Products:  []models.Product{{ID: uuid.New(), DateTime: receptionAt.Add(time.Minute), Type: models.Electronics}},
+		}},
+	}
+	mockService := new(MockPVZService)
+	mockService.On("GetPVZ", pvzID).Return(pvz, nil)
+	handler := handlers.NewPVZHandler(mockService)
+
+	w := httptest.NewRecorder()
+	handler.GetPVZ(w, withPVZID(httptest.NewRequest("GET", "/pvz/"+pvzID.String(), nil), pvzID.String()))
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	for name, ifNoneMatch := range map[string]string{"Same": etag, "Weak": "W/" + etag, "List": `"other", ` + etag, "Any": "*"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/pvz/"+pvzID.String(), nil)
+			req.Header.Set("If-None-Match", ifNoneMatch)
+			w := httptest.NewRecorder()
+			handler.GetPVZ(w, withPVZID(req, pvzID.String()))
+
+			assert.Equal(t, http.StatusNotModified, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Empty(t, w.Body.String())
+		})
+	}
+
+	// Новый товар меняет версию
+	pvz.Receptions[0].Products = append(pvz.Receptions[0].Products,
+		models.Product{ID: uuid.New(), DateTime: receptionAt.Add(2 * time.Minute), Type: models.Shoes})
+	req := httptest.NewRequest("GET", "/pvz/"+pvzID.String(), nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.GetPVZ(w, withPVZID(req, pvzID.String()))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestPVZHandler_GetPVZs_IfNoneMatch(t *testing.T) {
+	pvzs := []*models.PVZWithReceptions{{
+		PVZ:        &models.PVZ{ID: uuid.New(), City: models.Moscow, Status: models.PVZActive, RegistrationDate: time.Now()},
+		Receptions: make([]models.ReceptionWithProducts, 0),
+	}}
+	mockService := new(MockPVZService)
+	mockService.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(pvzs, nil)
+	handler := handlers.NewPVZHandler(mockService)
+
+	w := httptest.NewRecorder()
+	handler.GetPVZs(w, httptest.NewRequest("GET", "/pvz", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/pvz", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.GetPVZs(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestPVZHandler_GetSettings_IfNoneMatch(t *testing.T) {
+	pvzID := uuid.New()
+	settings := &models.PVZSettings{PVZID: pvzID, MaxProductsPerReception: 50, AllowedProductTypes: models.AllProductTypes()}
+	mockService := new(MockPVZService)
+	mockService.On("GetSettings", pvzID).Return(settings, nil)
+	handler := handlers.NewPVZHandler(mockService)
+
+	req := httptest.NewRequest("GET", "/pvz/"+pvzID.String()+"/settings", nil)
+	req.Header.Set("If-None-Match", `"`+settings.Version()+`"`)
+	w := httptest.NewRecorder()
+	handler.GetSettings(w, withPVZID(req, pvzID.String()))
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestPVZHandler_IfMatch(t *testing.T) {
+	pvzID := uuid.New()
+	current := "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name         string
+		ifMatch      string
+		mockBehavior func(s *MockPVZService)
+		expectedCode int
+	}{
+		{
+			name: "Without Header",
+			mockBehavior: func(s *MockPVZService) {
+				s.On("ChangeStatus", pvzID, models.PVZInactive).Return(&models.PVZ{ID: pvzID, Status: models.PVZInactive}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "Current Version",
+			ifMatch: `"` + current + `"`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("CurrentVersion", pvzID, service.ResourcePVZ).Return(current, nil)
+				s.On("ChangeStatus", pvzID, models.PVZInactive).Return(&models.PVZ{ID: pvzID, Status: models.PVZInactive}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "Stale Version",
+			ifMatch: `"stale"`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("CurrentVersion", pvzID, service.ResourcePVZ).Return(current, nil)
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Weak Version",
+			ifMatch: `W/"` + current + `"`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("CurrentVersion", pvzID, service.ResourcePVZ).Return(current, nil)
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "Any Version Of Missing PVZ",
+			ifMatch: "*",
+			mockBehavior: func(s *MockPVZService) {
+				s.On("CurrentVersion", pvzID, service.ResourcePVZ).Return("", apperrors.ErrPVZNotFound)
+			},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/pvz/"+pvzID.String()+"/deactivate", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			handler.Deactivate(w, withPVZID(req, pvzID.String()))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_UpdateSettings_IfMatch(t *testing.T) {
+	pvzID := uuid.New()
+	mockService := new(MockPVZService)
+	mockService.On("CurrentVersion", pvzID, service.ResourceSettings).Return("fresh", nil)
+	handler := handlers.NewPVZHandler(mockService)
+
+	req := httptest.NewRequest("PATCH", "/pvz/"+pvzID.String()+"/settings", bytes.NewBufferString(`{"maxProductsPerReception":10}`))
+	req.Header.Set("If-Match", `"stale"`)
+	w := httptest.NewRecorder()
+	handler.UpdateSettings(w, withPVZID(req, pvzID.String()))
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertNotCalled(t, "UpdateSettings", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPVZHandler_Update(t *testing.T) {
+	kazan := models.Kazan
+
+	tests := []struct {
+		name         string
+		pvzID        string
+		body         string
+		mockBehavior func(s *MockPVZService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:  "Success",
+			pvzID: uuid.New().String(),
+			body:  `{"city":"Казань"}`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("Update", mock.AnythingOfType("uuid.UUID"), models.PVZUpdate{City: &kazan}).Return(&models.PVZ{
+					City:   models.Kazan,
+					Status: models.PVZActive,
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Empty City",
+			pvzID:        uuid.New().String(),
+			body:         `{"city":""}`,
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Город не может быть пустым\",\"code\":\"invalid_request\"}\n",
+		},
+		{
+			name:         "Invalid PVZ ID",
+			pvzID:        "invalid-uuid",
+			body:         `{"city":"Казань"}`,
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
+		},
+		{
+			name:  "PVZ Not Found",
+			pvzID: uuid.New().String(),
+			body:  `{"city":"Казань"}`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("Update", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("models.PVZUpdate")).Return(nil, apperrors.ErrPVZNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "{\"message\":\"ПВЗ не найден\",\"code\":\"pvz_not_found\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("PATCH", "/pvz/"+tt.pvzID, bytes.NewBufferString(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("pvzId", tt.pvzID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			handler.Update(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		call         func(h *handlers.PVZHandler, w http.ResponseWriter, r *http.Request)
+		status       models.PVZStatus
+		serviceErr   error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Deactivate",
+			call:         (*handlers.PVZHandler).Deactivate,
+			status:       models.PVZInactive,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Activate",
+			call:         (*handlers.PVZHandler).Activate,
+			status:       models.PVZActive,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Archive",
+			call:         (*handlers.PVZHandler).Archive,
+			status:       models.PVZArchived,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Activate Archived",
+			call:         (*handlers.PVZHandler).Activate,
+			status:       models.PVZActive,
+			serviceErr:   apperrors.ErrPVZArchived,
+			expectedCode: http.StatusConflict,
+			expectedBody: "{\"message\":\"ПВЗ в архиве\",\"code\":\"pvz_archived\"}\n",
+		},
+		{
+			name:         "Archive With Active Reception",
+			call:         (*handlers.PVZHandler).Archive,
+			status:       models.PVZArchived,
+			serviceErr:   apperrors.ErrPVZHasActiveReception,
+			expectedCode: http.StatusConflict,
+			expectedBody: "{\"message\":\"Есть незакрытая приемка\",\"code\":\"pvz_has_active_reception\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvzID := uuid.New()
+			mockService := new(MockPVZService)
+			if tt.serviceErr != nil {
+				mockService.On("ChangeStatus", pvzID, tt.status).Return(nil, tt.serviceErr)
+			} else {
+				mockService.On("ChangeStatus", pvzID, tt.status).Return(&models.PVZ{ID: pvzID, Status: tt.status}, nil)
+			}
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/pvz/"+pvzID.String(), nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("pvzId", pvzID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			tt.call(handler, w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPVZHandler_GetNearby(t *testing.T) {
+	tests := []struct {
+		name         string
+		queryParams  string
+		mockBehavior func(s *MockPVZService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:        "Success",
+			queryParams: "lat=55.7558&lon=37.6173&radiusKm=2",
+			mockBehavior: func(s *MockPVZService) {
+				s.On("FindNearby", 55.7558, 37.6173, 2.0).Return([]*models.PVZWithDistance{
+					{PVZ: &models.PVZ{ID: uuid.New(), City: models.Moscow}, DistanceKm: 0.4},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "Default Radius",
+			queryParams: "lat=55.7558&lon=37.6173",
+			mockBehavior: func(s *MockPVZService) {
+				s.On("FindNearby", 55.7558, 37.6173, 1.0).Return([]*models.PVZWithDistance{}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "[]\n",
+		},
+		{
+			name:         "Missing Coordinates",
+			queryParams:  "lat=55.7558",
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверные координаты\",\"code\":\"invalid_coordinates\"}\n",
+		},
+		{
+			name:        "Radius Too Large",
+			queryParams: "lat=55.7558&lon=37.6173&radiusKm=500",
+			mockBehavior: func(s *MockPVZService) {
+				s.On("FindNearby", 55.7558, 37.6173, 500.0).Return(nil, apperrors.ErrInvalidRadius)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный радиус поиска\",\"code\":\"invalid_radius\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/pvz/nearby?"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetNearby(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_Create_DuplicateLocation(t *testing.T) {
+	mockService := new(MockPVZService)
+	mockService.On("Create", mock.MatchedBy(func(input models.PVZCreate) bool {
+		return input.Latitude != nil && !input.Force
+	})).Return(nil, apperrors.ErrPVZDuplicateLocation)
+	handler := handlers.NewPVZHandler(mockService)
+
+	body := `{"city":"Москва","address":"Тверская, 1","latitude":55.7558,"longitude":37.6173}`
+	req := httptest.NewRequest("POST", "/pvz", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "{\"message\":\"Рядом уже зарегистрирован ПВЗ, для подтверждения передайте force=true\",\"code\":\"pvz_duplicate_location\"}\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
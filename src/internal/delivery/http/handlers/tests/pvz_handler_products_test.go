@@ -57,7 +57,7 @@ func TestPVZHandler_CreateProduct(t *testing.T) {
 			},
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ID ПВЗ обязателен\"}\n",
+			expectedBody: "{\"message\":\"ID ПВЗ обязателен\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name: "Empty Product Type",
@@ -67,7 +67,7 @@ func TestPVZHandler_CreateProduct(t *testing.T) {
 			},
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Тип товара обязателен\"}\n",
+			expectedBody: "{\"message\":\"Тип товара обязателен\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name: "Invalid PVZ ID Format",
@@ -77,7 +77,7 @@ func TestPVZHandler_CreateProduct(t *testing.T) {
 			},
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\"}\n",
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name: "PVZ Not Found",
@@ -92,7 +92,7 @@ func TestPVZHandler_CreateProduct(t *testing.T) {
 				).Return(nil, apperrors.ErrPVZNotFound)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ПВЗ не найден\"}\n",
+			expectedBody: "{\"message\":\"ПВЗ не найден\",\"code\":\"pvz_not_found\"}\n",
 		},
 		{
 			name: "No Active Reception",
@@ -107,7 +107,7 @@ func TestPVZHandler_CreateProduct(t *testing.T) {
 				).Return(nil, apperrors.ErrNoActiveReception)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Нет активной приемки\"}\n",
+			expectedBody: "{\"message\":\"Нет активной приемки\",\"code\":\"no_active_reception\"}\n",
 		},
 		{
 			name: "Invalid Product Type",
@@ -122,7 +122,7 @@ func TestPVZHandler_CreateProduct(t *testing.T) {
 				).Return(nil, apperrors.ErrInvalidProductType)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Недопустимый тип товара\"}\n",
+			expectedBody: "{\"message\":\"Недопустимый тип товара\",\"code\":\"invalid_product_type\"}\n",
 		},
 	}
 
@@ -163,7 +163,7 @@ func TestPVZHandler_CreateProduct_InvalidJSON(t *testing.T) {
 	handler.CreateProduct(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "{\"message\":\"Неверный формат запроса\"}\n", w.Body.String())
+	assert.Equal(t, "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n", w.Body.String())
 }
 
 func TestPVZHandler_DeleteLastProduct(t *testing.T) {
@@ -178,7 +178,7 @@ func TestPVZHandler_DeleteLastProduct(t *testing.T) {
 			name:  "Success",
 			pvzID: uuid.New().String(),
 			mockBehavior: func(s *MockPVZService) {
-				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID")).Return(nil)
+				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID"), uuid.Nil).Return(nil)
 			},
 			expectedCode: http.StatusOK,
 		},
@@ -187,59 +187,59 @@ func TestPVZHandler_DeleteLastProduct(t *testing.T) {
 			pvzID:        "",
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ID ПВЗ обязателен\"}\n",
+			expectedBody: "{\"message\":\"ID ПВЗ обязателен\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:         "Invalid PVZ ID Format",
 			pvzID:        "invalid-uuid",
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\"}\n",
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:  "PVZ Not Found",
 			pvzID: uuid.New().String(),
 			mockBehavior: func(s *MockPVZService) {
-				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID")).Return(apperrors.ErrPVZNotFound)
+				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID"), uuid.Nil).Return(apperrors.ErrPVZNotFound)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ПВЗ не найден\"}\n",
+			expectedBody: "{\"message\":\"ПВЗ не найден\",\"code\":\"pvz_not_found\"}\n",
 		},
 		{
 			name:  "No Active Reception",
 			pvzID: uuid.New().String(),
 			mockBehavior: func(s *MockPVZService) {
-				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID")).Return(apperrors.ErrNoActiveReception)
+				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID"), uuid.Nil).Return(apperrors.ErrNoActiveReception)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Нет активной приемки\"}\n",
+			expectedBody: "{\"message\":\"Нет активной приемки\",\"code\":\"no_active_reception\"}\n",
 		},
 		{
 			name:  "Reception Closed",
 			pvzID: uuid.New().String(),
 			mockBehavior: func(s *MockPVZService) {
-				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID")).Return(apperrors.ErrReceptionClosed)
+				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID"), uuid.Nil).Return(apperrors.ErrReceptionClosed)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Приемка уже закрыта\"}\n",
+			expectedBody: "{\"message\":\"Приемка уже закрыта\",\"code\":\"reception_closed\"}\n",
 		},
 		{
 			name:  "No Products in Reception",
 			pvzID: uuid.New().String(),
 			mockBehavior: func(s *MockPVZService) {
-				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID")).Return(apperrors.ErrNoProductsToDelete)
+				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID"), uuid.Nil).Return(apperrors.ErrNoProductsToDelete)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Нет товаров для удаления\"}\n",
+			expectedBody: "{\"message\":\"Нет товаров для удаления\",\"code\":\"no_products_to_delete\"}\n",
 		},
 		{
 			name:  "Service Error",
 			pvzID: uuid.New().String(),
 			mockBehavior: func(s *MockPVZService) {
-				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID")).Return(errors.New("service error"))
+				s.On("DeleteLastProduct", mock.AnythingOfType("uuid.UUID"), uuid.Nil).Return(errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 	}
 
@@ -46,14 +46,14 @@ func TestPVZHandler_CreateReception(t *testing.T) {
 			pvzID:        "",
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ID ПВЗ обязателен\"}\n",
+			expectedBody: "{\"message\":\"ID ПВЗ обязателен\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:         "Invalid PVZ ID Format",
 			pvzID:        "invalid-uuid",
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\"}\n",
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:  "Active Reception Exists",
@@ -62,7 +62,16 @@ func TestPVZHandler_CreateReception(t *testing.T) {
 				s.On("CreateReception", mock.AnythingOfType("uuid.UUID")).Return(nil, apperrors.ErrActiveReceptionExists)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Уже есть активная приемка\"}\n",
+			expectedBody: "{\"message\":\"Уже есть активная приемка\",\"code\":\"active_reception_exists\"}\n",
+		},
+		{
+			name:  "PVZ Not Found",
+			pvzID: uuid.New().String(),
+			mockBehavior: func(s *MockPVZService) {
+				s.On("CreateReception", mock.AnythingOfType("uuid.UUID")).Return(nil, apperrors.ErrPVZNotFound)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"ПВЗ не найден\",\"code\":\"pvz_not_found\"}\n",
 		},
 		{
 			name:  "Service Error",
@@ -71,7 +80,7 @@ func TestPVZHandler_CreateReception(t *testing.T) {
 				s.On("CreateReception", mock.AnythingOfType("uuid.UUID")).Return(nil, errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 	}
 
@@ -112,7 +121,23 @@ func TestPVZHandler_CreateReception_InvalidJSON(t *testing.T) {
 	handler.CreateReception(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "{\"message\":\"Неверный формат запроса\"}\n", w.Body.String())
+	assert.Equal(t, "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n", w.Body.String())
+}
+
+func TestPVZHandler_CreateReception_APIKeyScope(t *testing.T) {
+	mockService := new(MockPVZService)
+	handler := handlers.NewPVZHandler(mockService)
+	key := &models.APIKey{Role: models.EmployeeRole, PVZIDs: []uuid.UUID{uuid.New()}}
+
+	req := httptest.NewRequest("POST", "/receptions", bytes.NewBufferString(`{"pvzId":"`+uuid.NewString()+`"}`))
+	ctx := context.WithValue(req.Context(), ctxkeys.UserRoleKey, string(models.EmployeeRole))
+	ctx = context.WithValue(ctx, ctxkeys.APIKeyKey, key)
+	w := httptest.NewRecorder()
+
+	handler.CreateReception(w, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "CreateReception", mock.Anything)
 }
 
 
@@ -142,14 +167,14 @@ func TestPVZHandler_CloseLastReception(t *testing.T) {
 			pvzID:        "",
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ID ПВЗ обязателен\"}\n",
+			expectedBody: "{\"message\":\"ID ПВЗ обязателен\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:         "Invalid PVZ ID Format",
 			pvzID:        "invalid-uuid",
 			mockBehavior: func(s *MockPVZService) {},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\"}\n",
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:  "PVZ Not Found",
@@ -159,7 +184,7 @@ func TestPVZHandler_CloseLastReception(t *testing.T) {
 					nil, apperrors.ErrPVZNotFound)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"ПВЗ не найден\"}\n",
+			expectedBody: "{\"message\":\"ПВЗ не найден\",\"code\":\"pvz_not_found\"}\n",
 		},
 		{
 			name:  "No Active Reception",
@@ -169,7 +194,7 @@ func TestPVZHandler_CloseLastReception(t *testing.T) {
 					nil, apperrors.ErrNoActiveReception)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Нет активной приемки\"}\n",
+			expectedBody: "{\"message\":\"Нет активной приемки\",\"code\":\"no_active_reception\"}\n",
 		},
 		{
 			name:  "Reception Already Closed",
@@ -179,7 +204,7 @@ func TestPVZHandler_CloseLastReception(t *testing.T) {
 					nil, apperrors.ErrReceptionAlreadyClosed)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Приемка уже закрыта\"}\n",
+			expectedBody: "{\"message\":\"Приемка уже закрыта\",\"code\":\"reception_already_closed\"}\n",
 		},
 		{
 			name:  "Service Error",
@@ -189,7 +214,7 @@ func TestPVZHandler_CloseLastReception(t *testing.T) {
 					nil, errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 	}
 
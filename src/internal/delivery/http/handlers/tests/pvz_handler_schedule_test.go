@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPVZHandler_UpdateSchedule(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockPVZService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Success",
+			body: `{"timezone":"Europe/Moscow","workingHours":[{"weekday":1,"opens":"09:00","closes":"21:30"}]}`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("UpdateSchedule", pvzID, models.PVZScheduleUpdate{
+					Timezone:     "Europe/Moscow",
+					WorkingHours: []models.WorkingHours{{Weekday: time.Monday, Opens: 540, Closes: 1290}},
+				}).Return(&models.PVZSchedule{
+					PVZID:        pvzID,
+					Timezone:     "Europe/Moscow",
+					WorkingHours: []models.WorkingHours{{Weekday: time.Monday, Opens: 540, Closes: 1290}},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid Time",
+			body:         `{"workingHours":[{"weekday":1,"opens":"9 утра","closes":"21:00"}]}`,
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный график работы\",\"code\":\"invalid_schedule\"}\n",
+		},
+		{
+			name: "Invalid Schedule",
+			body: `{"timezone":"Mars/Olympus"}`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("UpdateSchedule", pvzID, mock.AnythingOfType("models.PVZScheduleUpdate")).Return(nil, apperrors.ErrInvalidSchedule)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный график работы\",\"code\":\"invalid_schedule\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("PUT", "/pvz/"+pvzID.String()+"/schedule", bytes.NewBufferString(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("pvzId", pvzID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			handler.UpdateSchedule(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), `"opens":"09:00","closes":"21:30"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_SetCalendarException(t *testing.T) {
+	pvzID := uuid.New()
+	opens := models.ClockTime(10 * 60)
+	closes := models.ClockTime(16 * 60)
+
+	mockService := new(MockPVZService)
+	mockService.On("SetCalendarException", pvzID, models.CalendarException{
+		Date:    "2025-12-31",
+		Opens:   &opens,
+		Closes:  &closes,
+		Comment: "Сокращенный день",
+	}).Return(models.DefaultSchedule(pvzID), nil)
+	handler := handlers.NewPVZHandler(mockService)
+
+	body := `{"opens":"10:00","closes":"16:00","comment":"Сокращенный день"}`
+	req := httptest.NewRequest("PUT", "/pvz/"+pvzID.String()+"/calendar/2025-12-31", bytes.NewBufferString(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("pvzId", pvzID.String())
+	rctx.URLParams.Add("date", "2025-12-31")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.SetCalendarException(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPVZHandler_DeleteCalendarException(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name         string
+		serviceErr   error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Success",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Exception Not Found",
+			serviceErr:   apperrors.ErrCalendarExceptionNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: "{\"message\":\"Исключение в календаре не найдено\",\"code\":\"calendar_exception_not_found\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			mockService.On("DeleteCalendarException", pvzID, "2025-12-31").Return(tt.serviceErr)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("DELETE", "/pvz/"+pvzID.String()+"/calendar/2025-12-31", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("pvzId", pvzID.String())
+			rctx.URLParams.Add("date", "2025-12-31")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			handler.DeleteCalendarException(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_CreateReception_PVZClosed(t *testing.T) {
+	pvzID := uuid.New()
+
+	mockService := new(MockPVZService)
+	mockService.On("CreateReception", pvzID).Return(nil, apperrors.ErrPVZClosed)
+	handler := handlers.NewPVZHandler(mockService)
+
+	req := httptest.NewRequest("POST", "/receptions", bytes.NewBufferString(`{"pvzId":"`+pvzID.String()+`"}`))
+	w := httptest.NewRecorder()
+
+	handler.CreateReception(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "{\"message\":\"ПВЗ сейчас не работает\",\"code\":\"pvz_closed\"}\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPVZHandler_UpdateSettings(t *testing.T) {
+	pvzID := uuid.New()
+	limit := 100
+
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockPVZService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Success",
+			body: `{"maxProductsPerReception":100,"allowedProductTypes":["обувь"]}`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("UpdateSettings", pvzID, models.PVZSettingsUpdate{
+					MaxProductsPerReception: &limit,
+					AllowedProductTypes:     []models.ProductType{models.Shoes},
+				}).Return(&models.PVZSettings{
+					PVZID:                   pvzID,
+					MaxProductsPerReception: 100,
+					AllowedProductTypes:     []models.ProductType{models.Shoes},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Invalid Product Type",
+			body: `{"allowedProductTypes":["мебель"]}`,
+			mockBehavior: func(s *MockPVZService) {
+				s.On("UpdateSettings", pvzID, mock.AnythingOfType("models.PVZSettingsUpdate")).Return(nil, apperrors.ErrInvalidProductType)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Недопустимый тип товара\",\"code\":\"invalid_product_type\"}\n",
+		},
+		{
+			name:         "Invalid JSON",
+			body:         `{"maxProductsPerReception":"много"}`,
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("PATCH", "/pvz/"+pvzID.String()+"/settings", bytes.NewBufferString(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("pvzId", pvzID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			handler.UpdateSettings(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_CreateProduct_SettingsErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		serviceErr   error
+		expectedBody string
+	}{
+		{
+			name:         "Type Not Allowed",
+			serviceErr:   apperrors.ErrProductTypeNotAllowed,
+			expectedBody: "{\"message\":\"Тип товара не принимается в этом ПВЗ\",\"code\":\"product_type_not_allowed\"}\n",
+		},
+		{
+			name:         "Limit Reached",
+			serviceErr:   apperrors.ErrReceptionProductLimit,
+			expectedBody: "{\"message\":\"Достигнут лимит товаров в приемке\",\"code\":\"reception_product_limit\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvzID := uuid.New()
+			mockService := new(MockPVZService)
+			mockService.On("CreateProduct", pvzID, string(models.Shoes)).Return(nil, tt.serviceErr)
+			handler := handlers.NewPVZHandler(mockService)
+
+			body := `{"pvzId":"` + pvzID.String() + `","type":"обувь"}`
+			req := httptest.NewRequest("POST", "/products", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			handler.CreateProduct(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_CloseLastReception_EmptyReception(t *testing.T) {
+	pvzID := uuid.New()
+	mockService := new(MockPVZService)
+	mockService.On("CloseLastReception", pvzID).Return(nil, apperrors.ErrNoProductsInReception)
+	handler := handlers.NewPVZHandler(mockService)
+
+	req := httptest.NewRequest("POST", "/pvz/"+pvzID.String()+"/close_last_reception", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("pvzId", pvzID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	handler.CloseLastReception(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "{\"message\":\"Нельзя закрыть пустую приемку\",\"code\":\"no_products_in_reception\"}\n", w.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -6,6 +6,7 @@ import (
 	"avito-backend/src/internal/delivery/http/dto/request"
 	"avito-backend/src/internal/delivery/http/handlers"
 	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -24,8 +25,8 @@ type MockPVZService struct {
 	mock.Mock
 }
 
-func (m *MockPVZService) Create(city string) (*models.PVZ, error) {
-	args := m.Called(city)
+func (m *MockPVZService) Create(input models.PVZCreate) (*models.PVZ, error) {
+	args := m.Called(input)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -48,8 +49,8 @@ func (m *MockPVZService) CreateProduct(pvzID uuid.UUID, productType string) (*mo
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *MockPVZService) DeleteLastProduct(pvzID uuid.UUID) error {
-	args := m.Called(pvzID)
+func (m *MockPVZService) DeleteLastProduct(pvzID uuid.UUID, deletedBy uuid.UUID) error {
+	args := m.Called(pvzID, deletedBy)
 	return args.Error(0)
 }
 
@@ -61,8 +62,113 @@ func (m *MockPVZService) CloseLastReception(pvzID uuid.UUID) (*models.Reception,
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
-func (m *MockPVZService) GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error) {
-	args := m.Called(startDate, endDate, offset, limit)
+func (m *MockPVZService) FindNearby(lat, lon, radiusKm float64) ([]*models.PVZWithDistance, error) {
+	args := m.Called(lat, lon, radiusKm)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZWithDistance), args.Error(1)
+}
+
+func (m *MockPVZService) Update(pvzID uuid.UUID, update models.PVZUpdate) (*models.PVZ, error) {
+	args := m.Called(pvzID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *MockPVZService) CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error) {
+	args := m.Called(idleFor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
+func (m *MockPVZService) GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	args := m.Called(pvzID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZSettings), args.Error(1)
+}
+
+func (m *MockPVZService) GetPVZ(pvzID uuid.UUID) (*models.PVZWithReceptions, error) {
+	args := m.Called(pvzID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZWithReceptions), args.Error(1)
+}
+
+func (m *MockPVZService) CurrentVersion(pvzID uuid.UUID, resource service.PVZResource) (string, error) {
+	args := m.Called(pvzID, resource)
+	return args.String(0), args.Error(1)
+}
+
+// Сверяет версию через замоканный CurrentVersion и выполняет изменение на этом же моке
+func (m *MockPVZService) MutateIfVersion(pvzID uuid.UUID, resource service.PVZResource, matches func(version string) bool, mutate func(service.PVZServiceInterface) error) error {
+	version, err := m.CurrentVersion(pvzID, resource)
+	if err == apperrors.ErrPVZNotFound {
+		return apperrors.ErrPreconditionFailed
+	}
+	if err != nil {
+		return err
+	}
+	if !matches(version) {
+		return apperrors.ErrPreconditionFailed
+	}
+	return mutate(m)
+}
+
+func (m *MockPVZService) UpdateSettings(pvzID uuid.UUID, update models.PVZSettingsUpdate) (*models.PVZSettings, error) {
+	args := m.Called(pvzID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZSettings), args.Error(1)
+}
+
+func (m *MockPVZService) GetSchedule(pvzID uuid.UUID) (*models.PVZSchedule, error) {
+	args := m.Called(pvzID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZSchedule), args.Error(1)
+}
+
+func (m *MockPVZService) UpdateSchedule(pvzID uuid.UUID, update models.PVZScheduleUpdate) (*models.PVZSchedule, error) {
+	args := m.Called(pvzID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZSchedule), args.Error(1)
+}
+
+func (m *MockPVZService) SetCalendarException(pvzID uuid.UUID, exception models.CalendarException) (*models.PVZSchedule, error) {
+	args := m.Called(pvzID, exception)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZSchedule), args.Error(1)
+}
+
+func (m *MockPVZService) DeleteCalendarException(pvzID uuid.UUID, date string) error {
+	args := m.Called(pvzID, date)
+	return args.Error(0)
+}
+
+func (m *MockPVZService) ChangeStatus(pvzID uuid.UUID, status models.PVZStatus) (*models.PVZ, error) {
+	args := m.Called(pvzID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZ), args.Error(1)
+}
+
+func (m *MockPVZService) GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error) {
+	args := m.Called(filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -83,7 +189,7 @@ func TestPVZHandler_Create(t *testing.T) {
 				City: "Москва",
 			},
 			mockBehavior: func(s *MockPVZService) {
-				s.On("Create", "Москва").Return(&models.PVZ{
+				s.On("Create", models.PVZCreate{City: models.Moscow}).Return(&models.PVZ{
 					ID:               uuid.New(),
 					RegistrationDate: time.Now(),
 					City:             "Москва",
@@ -97,10 +203,10 @@ func TestPVZHandler_Create(t *testing.T) {
 				City: "Новосибирск",
 			},
 			mockBehavior: func(s *MockPVZService) {
-				s.On("Create", "Новосибирск").Return(nil, apperrors.ErrInvalidCity)
+				s.On("Create", models.PVZCreate{City: "Новосибирск"}).Return(nil, apperrors.ErrInvalidCity)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Недопустимый город\"}\n",
+			expectedBody: "{\"message\":\"Недопустимый город\",\"code\":\"invalid_city\"}\n",
 		},
 		{
 			name: "Service Error",
@@ -108,10 +214,10 @@ func TestPVZHandler_Create(t *testing.T) {
 				City: "Москва",
 			},
 			mockBehavior: func(s *MockPVZService) {
-				s.On("Create", "Москва").Return(nil, errors.New("service error"))
+				s.On("Create", models.PVZCreate{City: models.Moscow}).Return(nil, errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
 		},
 	}
 
@@ -152,7 +258,7 @@ func TestPVZHandler_Create_InvalidJSON(t *testing.T) {
 	handler.Create(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "{\"message\":\"Неверный формат запроса\"}\n", w.Body.String())
+	assert.Equal(t, "{\"message\":\"Неверный формат запроса\",\"code\":\"invalid_request\"}\n", w.Body.String())
 }
 
 func TestPVZHandler_GetPVZs(t *testing.T) {
@@ -167,12 +273,12 @@ func TestPVZHandler_GetPVZs(t *testing.T) {
 			name:        "Success",
 			queryParams: "startDate=2023-01-01T00:00:00Z&endDate=2023-12-31T23:59:59Z&page=1&limit=10",
 			mockBehavior: func(s *MockPVZService) {
-				s.On("GetPVZsWithReceptions",
-					mock.AnythingOfType("time.Time"),
-					mock.AnythingOfType("time.Time"),
-					0,
-					10,
-				).Return(
+				s.On("GetPVZsWithReceptions", models.PVZFilter{
+					StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:   time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC),
+					Offset:    0,
+					Limit:     10,
+				}).Return(
 					[]*models.PVZWithReceptions{
 						{
 							PVZ: &models.PVZ{
@@ -194,7 +300,7 @@ func TestPVZHandler_GetPVZs(t *testing.T) {
 			mockBehavior: func(s *MockPVZService) {
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный формат начальной даты\"}\n",
+			expectedBody: "{\"message\":\"Неверный формат начальной даты\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:        "Invalid End Date Format",
@@ -202,7 +308,7 @@ func TestPVZHandler_GetPVZs(t *testing.T) {
 			mockBehavior: func(s *MockPVZService) {
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный формат конечной даты\"}\n",
+			expectedBody: "{\"message\":\"Неверный формат конечной даты\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:        "Invalid Page",
@@ -210,7 +316,7 @@ func TestPVZHandler_GetPVZs(t *testing.T) {
 			mockBehavior: func(s *MockPVZService) {
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный номер страницы\"}\n",
+			expectedBody: "{\"message\":\"Неверный номер страницы\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:        "Invalid Limit",
@@ -218,27 +324,84 @@ func TestPVZHandler_GetPVZs(t *testing.T) {
 			mockBehavior: func(s *MockPVZService) {
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверное количество элементов на странице\"}\n",
+			expectedBody: "{\"message\":\"Неверное количество элементов на странице\",\"code\":\"invalid_request\"}\n",
 		},
 		{
 			name:        "Invalid Date Range",
 			queryParams: "startDate=2023-12-31T00:00:00Z&endDate=2023-01-01T00:00:00Z&page=1&limit=10",
 			mockBehavior: func(s *MockPVZService) {
-				s.On("GetPVZsWithReceptions", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 0, 10).Return(
+				s.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(
 					nil, apperrors.ErrInvalidDateRange)
 			},
 			expectedCode: http.StatusBadRequest,
-			expectedBody: "{\"message\":\"Неверный диапазон дат\"}\n",
+			expectedBody: "{\"message\":\"Неверный диапазон дат\",\"code\":\"invalid_date_range\"}\n",
 		},
 		{
 			name:        "Service Error",
 			queryParams: "startDate=2023-01-01T00:00:00Z&endDate=2023-12-31T23:59:59Z&page=1&limit=10",
 			mockBehavior: func(s *MockPVZService) {
-				s.On("GetPVZsWithReceptions", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 0, 10).Return(
+				s.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(
 					nil, errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\"}\n",
+			expectedBody: "{\"message\":\"Внутренняя ошибка сервера\",\"code\":\"internal\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPVZService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewPVZHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/pvz?"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetPVZs(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZHandler_GetPVZs_IncludeDeleted(t *testing.T) {
+	tests := []struct {
+		name         string
+		role         models.Role
+		queryParams  string
+		mockBehavior func(s *MockPVZService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:        "Moderator",
+			role:        models.ModeratorRole,
+			queryParams: "includeDeleted=true",
+			mockBehavior: func(s *MockPVZService) {
+				s.On("GetPVZsWithReceptions", models.PVZFilter{Limit: 10, IncludeDeleted: true}).Return(
+					[]*models.PVZWithReceptions{}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Employee",
+			role:         models.EmployeeRole,
+			queryParams:  "includeDeleted=true",
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusForbidden,
+			expectedBody: "{\"message\":\"Доступ запрещен\",\"code\":\"forbidden\"}\n",
+		},
+		{
+			name:         "Invalid Value",
+			role:         models.ModeratorRole,
+			queryParams:  "includeDeleted=maybe",
+			mockBehavior: func(s *MockPVZService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверное значение includeDeleted\",\"code\":\"invalid_request\"}\n",
 		},
 	}
 
@@ -249,6 +412,7 @@ func TestPVZHandler_GetPVZs(t *testing.T) {
 			handler := handlers.NewPVZHandler(mockService)
 
 			req := httptest.NewRequest("GET", "/pvz?"+tt.queryParams, nil)
+			req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserRoleKey, string(tt.role)))
 			w := httptest.NewRecorder()
 
 			handler.GetPVZs(w, req)
@@ -0,0 +1,271 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) ListUsers(filter models.UserFilter) ([]*models.User, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUser(id uuid.UUID) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateUser(id uuid.UUID, update models.UserUpdate, actorID uuid.UUID) (*models.User, error) {
+	args := m.Called(id, update, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) DeleteUser(id, actorID uuid.UUID) error {
+	args := m.Called(id, actorID)
+	return args.Error(0)
+}
+
+func userRequest(method, url, userID, actorID, body string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("userId", userID)
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	if actorID != "" {
+		ctx = context.WithValue(ctx, ctxkeys.UserIDKey, actorID)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestUserHandler_List(t *testing.T) {
+	employee := models.EmployeeRole
+
+	tests := []struct {
+		name         string
+		url          string
+		mockBehavior func(s *MockUserService)
+		expectedCode int
+	}{
+		{
+			name: "Default Pagination",
+			url:  "/users",
+			mockBehavior: func(s *MockUserService) {
+				s.On("ListUsers", models.UserFilter{Limit: 20}).Return([]*models.User{}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Filtered",
+			url:  "/users?role=employee&email=ivan&page=3&limit=5",
+			mockBehavior: func(s *MockUserService) {
+				s.On("ListUsers", models.UserFilter{Role: &employee, Email: "ivan", Offset: 10, Limit: 5}).
+					Return([]*models.User{{Email: "ivan@example.com"}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid Page",
+			url:          "/users?page=0",
+			mockBehavior: func(s *MockUserService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid Role",
+			url:  "/users?role=admin",
+			mockBehavior: func(s *MockUserService) {
+				s.On("ListUsers", mock.Anything).Return(nil, apperrors.ErrInvalidRole)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewUserHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.List(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_Get(t *testing.T) {
+	userID := uuid.New()
+
+	mockService := new(MockUserService)
+	mockService.On("GetUser", userID).Return(&models.User{ID: userID, Email: "user@example.com", PasswordHash: "secret-hash"}, nil).Once()
+	mockService.On("GetUser", userID).Return(nil, apperrors.ErrUserNotFound).Once()
+	handler := handlers.NewUserHandler(mockService)
+
+	w := httptest.NewRecorder()
+	handler.Get(w, userRequest(http.MethodGet, "/users/"+userID.String(), userID.String(), "", ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+	assert.Contains(t, w.Body.String(), `"disabled":false`)
+
+	w = httptest.NewRecorder()
+	handler.Get(w, userRequest(http.MethodGet, "/users/"+userID.String(), userID.String(), "", ""))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.Get(w, userRequest(http.MethodGet, "/users/invalid", "invalid", "", ""))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_Update(t *testing.T) {
+	userID := uuid.New()
+	moderatorID := uuid.New()
+	moderator := models.ModeratorRole
+	disabled := true
+
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockUserService)
+		expectedCode int
+	}{
+		{
+			name: "Change Role",
+			body: `{"role":"moderator"}`,
+			mockBehavior: func(s *MockUserService) {
+				s.On("UpdateUser", userID, models.UserUpdate{Role: &moderator}, moderatorID).
+					Return(&models.User{ID: userID, Role: "moderator"}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Disable",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *MockUserService) {
+				s.On("UpdateUser", userID, models.UserUpdate{Disabled: &disabled}, moderatorID).
+					Return(&models.User{ID: userID, Disabled: true}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Empty Update",
+			body:         `{}`,
+			mockBehavior: func(s *MockUserService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid JSON",
+			body:         `{"disabled":`,
+			mockBehavior: func(s *MockUserService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Self Modification",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *MockUserService) {
+				s.On("UpdateUser", userID, mock.Anything, moderatorID).Return(nil, apperrors.ErrSelfModification)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "Not Found",
+			body: `{"disabled":true}`,
+			mockBehavior: func(s *MockUserService) {
+				s.On("UpdateUser", userID, mock.Anything, moderatorID).Return(nil, apperrors.ErrUserNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewUserHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.Update(w, userRequest(http.MethodPatch, "/users/"+userID.String(), userID.String(), moderatorID.String(), tt.body))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_Delete(t *testing.T) {
+	userID := uuid.New()
+	moderatorID := uuid.New()
+
+	mockService := new(MockUserService)
+	mockService.On("DeleteUser", userID, moderatorID).Return(nil).Once()
+	mockService.On("DeleteUser", userID, moderatorID).Return(errors.New("db error")).Once()
+	handler := handlers.NewUserHandler(mockService)
+
+	w := httptest.NewRecorder()
+	handler.Delete(w, userRequest(http.MethodDelete, "/users/"+userID.String(), userID.String(), moderatorID.String(), ""))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.Delete(w, userRequest(http.MethodDelete, "/users/"+userID.String(), userID.String(), moderatorID.String(), ""))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_RejectsPVZScopedKey(t *testing.T) {
+	key := &models.APIKey{
+		ID:          uuid.New(),
+		Role:        models.ModeratorRole,
+		Permissions: []models.Permission{models.PermUserManage},
+		PVZIDs:      []uuid.UUID{uuid.New()},
+	}
+	mockService := new(MockUserService)
+	handler := handlers.NewUserHandler(mockService)
+	userID := uuid.NewString()
+
+	for _, call := range []struct {
+		method string
+		body   string
+		serve  http.HandlerFunc
+	}{
+		{"GET", "", handler.List},
+		{"GET", "", handler.Get},
+		{"PATCH", `{"role":"moderator"}`, handler.Update},
+		{"DELETE", "", handler.Delete},
+	} {
+		req := userRequest(call.method, "/users/"+userID, userID, "", call.body)
+		w := httptest.NewRecorder()
+
+		call.serve(w, req.WithContext(context.WithValue(req.Context(), ctxkeys.APIKeyKey, key)))
+
+		assert.Equal(t, http.StatusForbidden, w.Code, call.method)
+	}
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,210 @@
+package handlers_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/handlers"
+	"avito-backend/src/internal/domain/models"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateSubscription(input models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) GetSubscription(id uuid.UUID) (*models.WebhookSubscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) ListSubscriptions() ([]*models.WebhookSubscription, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteSubscription(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(subscriptionID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(subscriptionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name         string
+		body         string
+		mockBehavior func(s *MockWebhookService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Success",
+			body: `{"url":"https://partner.example/hooks","eventTypes":["ReceptionClosed"],"pvzId":"` + pvzID.String() + `"}`,
+			mockBehavior: func(s *MockWebhookService) {
+				s.On("CreateSubscription", models.WebhookSubscription{
+					URL:        "https://partner.example/hooks",
+					EventTypes: []models.EventType{models.ReceptionClosed},
+					PVZID:      &pvzID,
+				}).Return(&models.WebhookSubscription{ID: uuid.New(), Secret: "generated-secret"}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "Invalid PVZ ID",
+			body:         `{"url":"https://partner.example/hooks","eventTypes":["ReceptionClosed"],"pvzId":"123"}`,
+			mockBehavior: func(s *MockWebhookService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверный формат ID ПВЗ\",\"code\":\"invalid_request\"}\n",
+		},
+		{
+			name: "Invalid Subscription",
+			body: `{"url":"partner","eventTypes":["ReceptionClosed"]}`,
+			mockBehavior: func(s *MockWebhookService) {
+				s.On("CreateSubscription", mock.AnythingOfType("models.WebhookSubscription")).Return(nil, apperrors.ErrInvalidWebhook)
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "{\"message\":\"Неверные параметры подписки\",\"code\":\"invalid_webhook\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewWebhookHandler(mockService)
+
+			req := httptest.NewRequest("POST", "/webhooks", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Create(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			} else {
+				assert.Contains(t, w.Body.String(), `"secret":"generated-secret"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	webhookID := uuid.New()
+
+	tests := []struct {
+		name         string
+		query        string
+		mockBehavior func(s *MockWebhookService)
+		expectedCode int
+	}{
+		{
+			name:  "Success",
+			query: "?limit=5",
+			mockBehavior: func(s *MockWebhookService) {
+				s.On("ListDeliveries", webhookID, 5).Return([]*models.WebhookDelivery{{ID: uuid.New(), Status: models.WebhookDead}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid Limit",
+			query:        "?limit=abc",
+			mockBehavior: func(s *MockWebhookService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func(s *MockWebhookService) {
+				s.On("ListDeliveries", webhookID, 0).Return(nil, apperrors.ErrWebhookNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockWebhookService)
+			tt.mockBehavior(mockService)
+			handler := handlers.NewWebhookHandler(mockService)
+
+			req := httptest.NewRequest("GET", "/webhooks/"+webhookID.String()+"/deliveries"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("webhookId", webhookID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			handler.ListDeliveries(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_RejectsPVZScopedKey(t *testing.T) {
+	key := &models.APIKey{
+		ID:          uuid.New(),
+		Role:        models.ModeratorRole,
+		Permissions: []models.Permission{models.PermWebhookManage},
+		PVZIDs:      []uuid.UUID{uuid.New()},
+	}
+	mockService := new(MockWebhookService)
+	handler := handlers.NewWebhookHandler(mockService)
+	webhookID := uuid.NewString()
+
+	for _, call := range []struct {
+		method string
+		path   string
+		body   string
+		serve  http.HandlerFunc
+	}{
+		{"POST", "/webhooks", `{"url":"https://example.com/hook"}`, handler.Create},
+		{"GET", "/webhooks", "", handler.List},
+		{"GET", "/webhooks/" + webhookID, "", handler.Get},
+		{"DELETE", "/webhooks/" + webhookID, "", handler.Delete},
+		{"GET", "/webhooks/" + webhookID + "/deliveries", "", handler.ListDeliveries},
+	} {
+		req := httptest.NewRequest(call.method, call.path, bytes.NewBufferString(call.body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("webhookId", webhookID)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, ctxkeys.APIKeyKey, key)
+		w := httptest.NewRecorder()
+
+		call.serve(w, req.WithContext(ctx))
+
+		assert.Equal(t, http.StatusForbidden, w.Code, call.method+" "+call.path)
+	}
+	mockService.AssertExpectations(t)
+}
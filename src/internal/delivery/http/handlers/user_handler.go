@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type UserHandler struct {
+	userService service.UserServiceInterface
+}
+
+func NewUserHandler(userService service.UserServiceInterface) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+
+	page := 1
+	if pageStr := query.Get("page"); pageStr != "" {
+		var err error
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPage))
+			return
+		}
+	}
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPageSize))
+			return
+		}
+	}
+
+	filter := models.UserFilter{
+		Email:  query.Get("email"),
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	}
+	if roleStr := query.Get("role"); roleStr != "" {
+		role := models.Role(roleStr)
+		filter.Role = &role
+	}
+
+	slog.InfoContext(ctx, "получение списка пользователей", "page", page, "limit", limit, "role", query.Get("role"))
+
+	users, err := h.userService.ListUsers(filter)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(users)
+}
+
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	userID, ok := h.parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUser(userID)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	userID, ok := h.parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if req.Role == nil && req.Disabled == nil {
+		httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestUserUpdate))
+		return
+	}
+
+	update := models.UserUpdate{Disabled: req.Disabled}
+	if req.Role != nil {
+		role := models.Role(*req.Role)
+		update.Role = &role
+	}
+
+	slog.InfoContext(ctx, "изменение пользователя", "user_id", userID, "role", req.Role, "disabled", req.Disabled)
+
+	user, err := h.userService.UpdateUser(userID, update, userIDFromContext(ctx))
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "пользователь изменен", "user_id", userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	userID, ok := h.parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(userID, userIDFromContext(ctx)); err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "пользователь удален", "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) parseUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		httperror.Write(r.Context(), w, r, invalidRequest(apperrors.KeyInvalidRequestUserID))
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/dto/request"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookServiceInterface
+}
+
+func NewWebhookHandler(webhookService service.WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	var req request.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.Write(ctx, w, r, apperrors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	input := models.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: make([]models.EventType, 0, len(req.EventTypes)),
+	}
+	for _, eventType := range req.EventTypes {
+		input.EventTypes = append(input.EventTypes, models.EventType(eventType))
+	}
+	if req.PVZID != nil {
+		pvzID, err := uuid.Parse(*req.PVZID)
+		if err != nil {
+			httperror.Write(ctx, w, r, invalidRequest(apperrors.KeyInvalidRequestPVZID))
+			return
+		}
+		input.PVZID = &pvzID
+	}
+	if req.City != nil {
+		city := models.City(*req.City)
+		input.City = &city
+	}
+	if userID := userIDFromContext(ctx); userID != uuid.Nil {
+		input.CreatedBy = &userID
+	}
+
+	slog.InfoContext(ctx, "создание подписки на вебхуки", "url", req.URL, "event_types", req.EventTypes)
+
+	subscription, err := h.webhookService.CreateSubscription(input)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "подписка на вебхуки создана", "webhook_id", subscription.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	subscriptions, err := h.webhookService.ListSubscriptions()
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(subscriptions)
+}
+
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	webhookID, ok := h.parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscription(webhookID)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	webhookID, ok := h.parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(webhookID); err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	slog.InfoContext(ctx, "подписка на вебхуки удалена", "webhook_id", webhookID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if denyPVZScopedKey(ctx, w, r) {
+		return
+	}
+
+	webhookID, ok := h.parseWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			httperror.Write(ctx, w, r, apperrors.ErrInvalidPagination)
+			return
+		}
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(webhookID, limit)
+	if err != nil {
+		httperror.Write(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func (h *WebhookHandler) parseWebhookID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		httperror.Write(r.Context(), w, r, invalidRequest(apperrors.KeyInvalidRequestWebhookID))
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
@@ -0,0 +1,97 @@
+package httperror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/dto/response"
+	"avito-backend/src/internal/i18n"
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+	problemTypePrefix  = "urn:avito-backend:error:"
+)
+
+// Статус ответа для ошибки Err на отдельном маршруте, если он отличается от статуса из apperrors
+type Override struct {
+	Err    *apperrors.Error
+	Status int
+}
+
+// Переводит ошибку в HTTP-ответ по коду apperrors. Клиент, который передал Accept: application/problem+json,
+// получает ответ в формате RFC 7807, остальные — {"message", "code", "details"}.
+// Сообщение переводится на язык из Accept-Language, код от языка не зависит.
+// Ошибки сервера пишутся в лог с причиной, клиенту отдается только общее сообщение.
+// overrides меняют статус отдельных ошибок для маршрута
+func Write(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, overrides ...Override) {
+	appErr := apperrors.From(err)
+	for _, override := range overrides {
+		if errors.Is(appErr, override.Err) {
+			appErr = appErr.WithHTTPStatus(override.Status)
+		}
+	}
+	logError(ctx, appErr)
+
+	lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+
+	if acceptsProblem(r) {
+		w.Header().Set("Content-Type", ContentTypeProblem)
+		w.WriteHeader(appErr.HTTPStatus)
+		json.NewEncoder(w).Encode(response.ProblemDetails{
+			Type:     problemTypePrefix + appErr.Code,
+			Title:    http.StatusText(appErr.HTTPStatus),
+			Status:   appErr.HTTPStatus,
+			Detail:   appErr.Message(lang),
+			Instance: r.URL.Path,
+			Code:     appErr.Code,
+			Details:  appErr.Details,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(appErr.HTTPStatus)
+	json.NewEncoder(w).Encode(response.ErrorResponse{
+		Message: appErr.Message(lang),
+		Code:    appErr.Code,
+		Details: appErr.Details,
+	})
+}
+
+func logError(ctx context.Context, appErr *apperrors.Error) {
+	if appErr.HTTPStatus >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "внутренняя ошибка сервера", "code", appErr.Code, "error", cause(appErr))
+		return
+	}
+
+	attrs := []any{"code", appErr.Code, "message", appErr.Error()}
+	if c := errors.Unwrap(appErr); c != nil {
+		attrs = append(attrs, "error", c)
+	}
+	slog.WarnContext(ctx, "ошибка запроса", attrs...)
+}
+
+func cause(appErr *apperrors.Error) error {
+	if c := errors.Unwrap(appErr); c != nil {
+		return c
+	}
+	return appErr
+}
+
+func acceptsProblem(r *http.Request) bool {
+	for _, value := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(value, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), ContentTypeProblem) {
+			return true
+		}
+	}
+	return false
+}
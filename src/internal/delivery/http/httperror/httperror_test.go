@@ -0,0 +1,107 @@
+package httperror_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name         string
+		accept       string
+		language     string
+		err          error
+		expectedCode int
+		expectedType string
+		expectedLang string
+		expectedBody string
+	}{
+		{
+			name:         "Application Error",
+			err:          apperrors.ErrPVZNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+			expectedLang: "ru",
+			expectedBody: `{"message":"ПВЗ не найден","code":"pvz_not_found"}`,
+		},
+		{
+			name:         "English Message",
+			language:     "en-US,en;q=0.9,ru;q=0.8",
+			err:          apperrors.ErrPVZNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedType: "application/json",
+			expectedLang: "en",
+			expectedBody: `{"message":"PVZ not found","code":"pvz_not_found"}`,
+		},
+		{
+			name:         "Message And Details",
+			err:          apperrors.ErrInvalidRequest.WithMessageKey("invalid_request.page").WithDetails(map[string]any{"page": "0"}),
+			expectedCode: http.StatusBadRequest,
+			expectedType: "application/json",
+			expectedLang: "ru",
+			expectedBody: `{"message":"Неверный номер страницы","code":"invalid_request","details":{"page":"0"}}`,
+		},
+		{
+			name:         "Unknown Error Is Hidden",
+			err:          errors.New("connection refused"),
+			expectedCode: http.StatusInternalServerError,
+			expectedType: "application/json",
+			expectedLang: "ru",
+			expectedBody: `{"message":"Внутренняя ошибка сервера","code":"internal"}`,
+		},
+		{
+			name:         "Problem Details",
+			accept:       "application/json;q=0.9, application/problem+json",
+			language:     "en",
+			err:          apperrors.ErrPreconditionFailed,
+			expectedCode: http.StatusPreconditionFailed,
+			expectedType: "application/problem+json",
+			expectedLang: "en",
+			expectedBody: `{"type":"urn:avito-backend:error:precondition_failed","title":"Precondition Failed","status":412,
+				"detail":"The resource has changed, fetch the current version","instance":"/pvz/1","code":"precondition_failed"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/pvz/1", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.language != "" {
+				req.Header.Set("Accept-Language", tt.language)
+			}
+			w := httptest.NewRecorder()
+
+			httperror.Write(context.Background(), w, req, tt.err)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedLang, w.Header().Get("Content-Language"))
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestWrite_Override(t *testing.T) {
+	override := httperror.Override{Err: apperrors.ErrPVZNotFound, Status: http.StatusBadRequest}
+
+	req := httptest.NewRequest("POST", "/products", nil)
+	w := httptest.NewRecorder()
+	httperror.Write(context.Background(), w, req, apperrors.ErrPVZNotFound.Wrap(errors.New("no rows")), override)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"ПВЗ не найден","code":"pvz_not_found"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httperror.Write(context.Background(), w, req, apperrors.ErrForbidden, override)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -1,50 +1,80 @@
 package middleware
 
 import (
+	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/delivery/http/ctxkeys"
-	"avito-backend/src/internal/delivery/http/dto/response"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/pkg/jwt"
 	"context"
-	"encoding/json"
 	"net/http"
 	"strings"
 )
 
-func AuthMiddleware(tokenManager *jwt.TokenManager) func(http.Handler) http.Handler {
+// Проверяет, что токен пользователя не отозван сменой пароля
+type SessionValidator interface {
+	ValidateSession(userID string, tokenVersion int) error
+}
+
+// Проверяет API-ключ межсервисного клиента
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(rawKey string) (*models.APIKey, error)
+}
+
+// sessions может быть nil: тогда проверяется только подпись и срок действия токена.
+// apiKeys может быть nil: тогда заголовок X-API-Key не принимается
+func AuthMiddleware(tokenManager *jwt.TokenManager, sessions SessionValidator, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rawKey := r.Header.Get("X-API-Key"); rawKey != "" && apiKeys != nil {
+				authenticateAPIKey(w, r, next, apiKeys, rawKey)
+				return
+			}
+
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(response.ErrorResponse{
-					Message: "Отсутствует токен авторизации",
-				})
+				httperror.Write(r.Context(), w, r, apperrors.ErrMissingToken)
 				return
 			}
 
 			headerParts := strings.Split(authHeader, " ")
 			if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(response.ErrorResponse{
-					Message: "Неверный формат токена",
-				})
+				httperror.Write(r.Context(), w, r, apperrors.ErrInvalidToken.WithMessageKey(apperrors.KeyInvalidTokenFormat))
 				return
 			}
 
-			userRole, err := tokenManager.ValidateToken(headerParts[1])
+			claims, err := tokenManager.ParseToken(headerParts[1])
 			if err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(response.ErrorResponse{
-					Message: "Неверный токен",
-				})
+				httperror.Write(r.Context(), w, r, apperrors.ErrInvalidToken.Wrap(err))
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), ctxkeys.UserRoleKey, userRole)
+			if sessions != nil && claims.UserID != "" {
+				if err := sessions.ValidateSession(claims.UserID, claims.TokenVersion); err != nil {
+					// Токен отключенной учетной записи недействителен, как и отозванный
+					httperror.Write(r.Context(), w, r, err, httperror.Override{Err: apperrors.ErrAccountDisabled, Status: http.StatusUnauthorized})
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), ctxkeys.UserRoleKey, claims.Role)
+			if claims.UserID != "" {
+				ctx = context.WithValue(ctx, ctxkeys.UserIDKey, claims.UserID)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// Запрос с API-ключом получает роль ключа; идентификатора пользователя у него нет
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, apiKeys APIKeyAuthenticator, rawKey string) {
+	key, err := apiKeys.AuthenticateAPIKey(rawKey)
+	if err != nil {
+		httperror.Write(r.Context(), w, r, err)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), ctxkeys.UserRoleKey, string(key.Role))
+	ctx = context.WithValue(ctx, ctxkeys.APIKeyKey, key)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
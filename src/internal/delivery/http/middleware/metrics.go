@@ -39,3 +39,16 @@ func (rw *responseWriter) WriteHeader(code int) {
 func (rw *responseWriter) Status() string {
 	return rw.status
 }
+
+// Без Flush обертка скрывает http.Flusher исходного writer, и потоковые ответы (SSE)
+// буферизуются до завершения запроса
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Нужен http.ResponseController, чтобы добраться до исходного writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Пропускает запрос, если роли из токена выдано право permission. Для API-ключа
+// дополнительно проверяются его список прав и ПВЗ из параметра маршрута pvzId
+func RequirePermission(permission models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := r.Context().Value(ctxkeys.UserRoleKey).(string)
+			allowed := models.Role(role).Can(permission)
+
+			if key, ok := r.Context().Value(ctxkeys.APIKeyKey).(*models.APIKey); ok && allowed {
+				allowed = key.Allows(permission)
+				if pvzID, err := uuid.Parse(chi.URLParam(r, "pvzId")); err == nil && allowed {
+					allowed = key.AllowsPVZ(pvzID)
+				}
+			}
+
+			if !allowed {
+				httperror.Write(r.Context(), w, r, apperrors.ErrForbidden.WithDetails(map[string]any{"permission": permission}))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/httperror"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/metrics"
+	"avito-backend/src/pkg/ratelimit"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// Ограничивает частоту запросов группы маршрутов. После AuthMiddleware ключом служит пользователь
+// или API-ключ, для анонимных запросов и токенов без идентификатора — IP клиента
+func RateLimitMiddleware(group string, limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !limiter.Rule().Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, wait := limiter.Allow(rateLimitKey(r))
+			if !allowed {
+				metrics.RateLimitRejectedTotal.WithLabelValues(group).Inc()
+				retryAfter := ratelimit.RetryAfterSeconds(wait)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				httperror.Write(r.Context(), w, r, apperrors.ErrTooManyRequests.WithDetails(map[string]any{"group": group, "retryAfter": retryAfter}))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func rateLimitKey(r *http.Request) string {
+	if key, ok := r.Context().Value(ctxkeys.APIKeyKey).(*models.APIKey); ok {
+		return "apikey:" + key.ID.String()
+	}
+	if userID, ok := r.Context().Value(ctxkeys.UserIDKey).(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + ClientIP(r)
+}
+
+// IP из адреса соединения; заголовкам X-Forwarded-For не доверяем, их может подставить клиент
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -1,20 +1,25 @@
 package middleware_test
 
 import (
+	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/delivery/http/ctxkeys"
 	"avito-backend/src/internal/delivery/http/middleware"
+	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/pkg/jwt"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAuthMiddleware(t *testing.T) {
-	tokenManager := jwt.NewTokenManager("test-secret", "24h")
+	tokenManager := jwt.NewTokenManager("test-secret", 24*time.Hour)
 
 	tests := []struct {
 		name           string
@@ -84,7 +89,7 @@ func TestAuthMiddleware(t *testing.T) {
 				w.WriteHeader(http.StatusOK)
 			})
 
-			middleware := middleware.AuthMiddleware(tokenManager)(nextHandler)
+			middleware := middleware.AuthMiddleware(tokenManager, nil, nil)(nextHandler)
 
 			req := httptest.NewRequest("GET", "/", nil)
 			tt.setupAuth(req)
@@ -108,7 +113,7 @@ func TestAuthMiddleware(t *testing.T) {
 }
 
 func TestAuthMiddleware_ContextPropagation(t *testing.T) {
-	tokenManager := jwt.NewTokenManager("test-secret", "24h")
+	tokenManager := jwt.NewTokenManager("test-secret", 24*time.Hour)
 	token, err := tokenManager.GenerateToken("admin")
 	require.NoError(t, err)
 
@@ -118,7 +123,7 @@ func TestAuthMiddleware_ContextPropagation(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	middleware := middleware.AuthMiddleware(tokenManager)(nextHandler)
+	middleware := middleware.AuthMiddleware(tokenManager, nil, nil)(nextHandler)
 
 	req := httptest.NewRequest("GET", "/", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -130,14 +135,37 @@ func TestAuthMiddleware_ContextPropagation(t *testing.T) {
 	assert.Equal(t, "admin", capturedRole)
 }
 
+func TestAuthMiddleware_UserIDPropagation(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-secret", 24*time.Hour)
+	token, err := tokenManager.GenerateUserToken("8a1f4b2c-0f5e-4c47-9d51-6f2d3a9b7e10", "moderator", 0)
+	require.NoError(t, err)
+
+	var capturedUserID string
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedUserID = r.Context().Value(ctxkeys.UserIDKey).(string)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	middleware := middleware.AuthMiddleware(tokenManager, nil, nil)(nextHandler)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+
+	middleware.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "8a1f4b2c-0f5e-4c47-9d51-6f2d3a9b7e10", capturedUserID)
+}
+
 func TestAuthMiddleware_ResponseHeaders(t *testing.T) {
-	tokenManager := jwt.NewTokenManager("test-secret", "24h")
+	tokenManager := jwt.NewTokenManager("test-secret", 24*time.Hour)
 
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	middleware := middleware.AuthMiddleware(tokenManager)(nextHandler)
+	middleware := middleware.AuthMiddleware(tokenManager, nil, nil)(nextHandler)
 
 	req := httptest.NewRequest("GET", "/", nil)
 	rr := httptest.NewRecorder()
@@ -146,3 +174,117 @@ func TestAuthMiddleware_ResponseHeaders(t *testing.T) {
 
 	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
 }
+
+type stubSessions struct {
+	version int
+	err     error
+}
+
+func (s stubSessions) ValidateSession(userID string, tokenVersion int) error {
+	if s.err != nil {
+		return s.err
+	}
+	if tokenVersion != s.version {
+		return apperrors.ErrTokenRevoked
+	}
+	return nil
+}
+
+func TestAuthMiddleware_SessionValidation(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-secret", 24*time.Hour)
+	userToken, err := tokenManager.GenerateUserToken("8a1f4b2c-0f5e-4c47-9d51-6f2d3a9b7e10", "employee", 1)
+	require.NoError(t, err)
+	dummyToken, err := tokenManager.GenerateToken("employee")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		token          string
+		sessions       stubSessions
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{name: "Current version", token: userToken, sessions: stubSessions{version: 1}, expectedStatus: http.StatusOK},
+		{name: "Revoked", token: userToken, sessions: stubSessions{version: 2}, expectedStatus: http.StatusUnauthorized, expectedMsg: "Токен отозван"},
+		{name: "Dummy token skips check", token: dummyToken, sessions: stubSessions{version: 5}, expectedStatus: http.StatusOK},
+		{name: "Disabled account", token: userToken, sessions: stubSessions{err: apperrors.ErrAccountDisabled}, expectedStatus: http.StatusUnauthorized, expectedMsg: "Учетная запись отключена"},
+		{name: "Validator error", token: userToken, sessions: stubSessions{err: errors.New("db down")}, expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := middleware.AuthMiddleware(tokenManager, tt.sessions, nil)(nextHandler)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedMsg != "" {
+				var resp map[string]string
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				assert.Equal(t, tt.expectedMsg, resp["message"])
+			}
+		})
+	}
+}
+
+type stubAPIKeys struct {
+	key *models.APIKey
+}
+
+func (s stubAPIKeys) AuthenticateAPIKey(rawKey string) (*models.APIKey, error) {
+	if rawKey != "pvzk_valid" {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+	return s.key, nil
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-secret", 24*time.Hour)
+	key := &models.APIKey{ID: uuid.New(), Role: models.EmployeeRole}
+	handler := middleware.AuthMiddleware(tokenManager, nil, stubAPIKeys{key: key})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "employee", r.Context().Value(ctxkeys.UserRoleKey))
+		assert.Equal(t, key, r.Context().Value(ctxkeys.APIKeyKey))
+		assert.Nil(t, r.Context().Value(ctxkeys.UserIDKey), "у ключа нет пользователя")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("Valid key", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "pvzk_valid")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "pvzk_revoked")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		var resp map[string]string
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		assert.Equal(t, "Неверный API-ключ", resp["message"])
+	})
+
+	t.Run("Keys disabled", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "pvzk_valid")
+		rr := httptest.NewRecorder()
+
+		middleware.AuthMiddleware(tokenManager, nil, nil)(handler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, "без Authorization запрос не проходит")
+	})
+}
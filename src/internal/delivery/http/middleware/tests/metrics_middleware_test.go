@@ -101,3 +101,20 @@ func TestResponseWriter(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddlewareChain_Flush(t *testing.T) {
+	flushed := false
+	handler := middleware.MetricsMiddleware(middleware.LoggerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: ping\n\n"))
+		err := http.NewResponseController(w).Flush()
+		assert.NoError(t, err)
+		flushed = true
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/pvz/events", nil))
+
+	assert.True(t, flushed)
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "data: ping\n\n", rec.Body.String())
+}
@@ -0,0 +1,141 @@
+package middleware_test
+
+import (
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/middleware"
+	"avito-backend/src/internal/domain/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequirePermission(t *testing.T) {
+	models.SetRolePermissions(map[models.Role][]models.Permission{
+		models.ModeratorRole: {models.PermPVZCreate, models.PermPVZRead},
+		models.EmployeeRole:  {models.PermPVZRead},
+		"analyst":            {models.PermPVZRead},
+	})
+	t.Cleanup(func() { models.SetRolePermissions(models.DefaultRolePermissions()) })
+
+	tests := []struct {
+		name           string
+		permission     models.Permission
+		contextRole    string
+		expectedStatus int
+	}{
+		{
+			name:           "Success - Role Has Permission",
+			permission:     models.PermPVZCreate,
+			contextRole:    string(models.ModeratorRole),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Success - Role From Config",
+			permission:     models.PermPVZRead,
+			contextRole:    "analyst",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Failure - Missing Permission",
+			permission:     models.PermPVZCreate,
+			contextRole:    string(models.EmployeeRole),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Failure - Unknown Role",
+			permission:     models.PermPVZRead,
+			contextRole:    "unknown_role",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Failure - No Role",
+			permission:     models.PermPVZRead,
+			contextRole:    "",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			middleware := middleware.RequirePermission(tt.permission)(nextHandler)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.contextRole != "" {
+				ctx := context.WithValue(req.Context(), ctxkeys.UserRoleKey, tt.contextRole)
+				req = req.WithContext(ctx)
+			}
+
+			rr := httptest.NewRecorder()
+
+			middleware.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusForbidden {
+				var response struct {
+					Message string `json:"message"`
+				}
+				err := json.NewDecoder(rr.Body).Decode(&response)
+				require.NoError(t, err)
+				assert.Equal(t, "Доступ запрещен", response.Message)
+			}
+		})
+	}
+}
+
+func TestRequirePermission_APIKeyScope(t *testing.T) {
+	pvzID := uuid.New()
+	key := &models.APIKey{
+		Role:        models.EmployeeRole,
+		Permissions: []models.Permission{models.PermPVZRead, models.PermReceptionClose},
+		PVZIDs:      []uuid.UUID{pvzID},
+	}
+
+	tests := []struct {
+		name           string
+		permission     models.Permission
+		pvzID          string
+		expectedStatus int
+	}{
+		{name: "Право из списка ключа", permission: models.PermPVZRead, expectedStatus: http.StatusOK},
+		{name: "Право роли вне списка ключа", permission: models.PermProductCreate, expectedStatus: http.StatusForbidden},
+		{name: "ПВЗ из области ключа", permission: models.PermReceptionClose, pvzID: pvzID.String(), expectedStatus: http.StatusOK},
+		{name: "Чужой ПВЗ", permission: models.PermReceptionClose, pvzID: uuid.NewString(), expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.With(middleware.RequirePermission(tt.permission)).Post("/pvz/{pvzId}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			router.With(middleware.RequirePermission(tt.permission)).Post("/pvz", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			path := "/pvz"
+			if tt.pvzID != "" {
+				path += "/" + tt.pvzID
+			}
+			req := httptest.NewRequest("POST", path, nil)
+			ctx := context.WithValue(req.Context(), ctxkeys.UserRoleKey, string(key.Role))
+			ctx = context.WithValue(ctx, ctxkeys.APIKeyKey, key)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
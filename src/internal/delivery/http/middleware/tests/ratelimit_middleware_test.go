@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"avito-backend/src/internal/delivery/http/ctxkeys"
+	"avito-backend/src/internal/delivery/http/dto/response"
+	"avito-backend/src/internal/delivery/http/middleware"
+	"avito-backend/src/pkg/ratelimit"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	newRequest := func(remoteAddr, userID string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/products", nil)
+		req.RemoteAddr = remoteAddr
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), ctxkeys.UserIDKey, userID))
+		}
+		return req
+	}
+
+	t.Run("Rejects By IP", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.Rule{Requests: 2, Per: time.Minute})
+		handler := middleware.RateLimitMiddleware("auth", limiter)(okHandler)
+
+		for i := 0; i < 2; i++ {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, newRequest("10.0.0.1:5000", ""))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("10.0.0.1:5001", ""))
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+		var resp response.ErrorResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, "Слишком много запросов", resp.Message)
+		assert.Equal(t, "too_many_requests", resp.Code)
+		assert.Equal(t, float64(30), resp.Details["retryAfter"])
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("10.0.0.2:5000", ""))
+		assert.Equal(t, http.StatusOK, rec.Code, "другой IP ограничивается отдельно")
+	})
+
+	t.Run("Keys By User", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(ratelimit.Rule{Requests: 1, Per: time.Minute})
+		handler := middleware.RateLimitMiddleware("employee", limiter)(okHandler)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("10.0.0.1:5000", "user-1"))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("10.0.0.1:5000", "user-2"))
+		assert.Equal(t, http.StatusOK, rec.Code, "пользователи за одним IP ограничиваются отдельно")
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest("10.0.0.9:5000", "user-1"))
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		handler := middleware.RateLimitMiddleware("shared", ratelimit.NewLimiter(ratelimit.Rule{}))(okHandler)
+
+		for i := 0; i < 100; i++ {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, newRequest("10.0.0.1:5000", ""))
+			require.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+}
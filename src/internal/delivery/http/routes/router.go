@@ -1,9 +1,11 @@
 package routes
 
 import (
+	"avito-backend/src/internal/delivery/http/handlers"
 	appmiddleware "avito-backend/src/internal/delivery/http/middleware"
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/pkg/jwt"
+	"avito-backend/src/pkg/ratelimit"
 
 	"net/http"
 
@@ -16,39 +18,141 @@ type AuthHandlerInterface interface {
 	Register(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
 	DummyLogin(w http.ResponseWriter, r *http.Request)
+	Unlock(w http.ResponseWriter, r *http.Request)
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	RequestPasswordReset(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
 }
 
 type PVZHandlerInterface interface {
 	Create(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Deactivate(w http.ResponseWriter, r *http.Request)
+	Activate(w http.ResponseWriter, r *http.Request)
+	Archive(w http.ResponseWriter, r *http.Request)
 	GetPVZs(w http.ResponseWriter, r *http.Request)
+	GetPVZ(w http.ResponseWriter, r *http.Request)
+	GetNearby(w http.ResponseWriter, r *http.Request)
+	GetSchedule(w http.ResponseWriter, r *http.Request)
+	UpdateSchedule(w http.ResponseWriter, r *http.Request)
+	SetCalendarException(w http.ResponseWriter, r *http.Request)
+	DeleteCalendarException(w http.ResponseWriter, r *http.Request)
+	GetSettings(w http.ResponseWriter, r *http.Request)
+	UpdateSettings(w http.ResponseWriter, r *http.Request)
 	CreateReception(w http.ResponseWriter, r *http.Request)
 	CreateProduct(w http.ResponseWriter, r *http.Request)
 	DeleteLastProduct(w http.ResponseWriter, r *http.Request)
 	CloseLastReception(w http.ResponseWriter, r *http.Request)
 }
 
+type WebhookHandlerInterface interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	ListDeliveries(w http.ResponseWriter, r *http.Request)
+}
+
+type UserHandlerInterface interface {
+	List(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type APIKeyHandlerInterface interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Revoke(w http.ResponseWriter, r *http.Request)
+}
+
+type JWKSHandlerInterface interface {
+	Get(w http.ResponseWriter, r *http.Request)
+}
+
+type EventsHandlerInterface interface {
+	StreamPVZ(w http.ResponseWriter, r *http.Request)
+	StreamCity(w http.ResponseWriter, r *http.Request)
+}
+
+// Лимиты запросов по группам маршрутов; нулевой лимит отключает ограничение
+type RateLimits struct {
+	Auth      ratelimit.Rule
+	Moderator ratelimit.Rule
+	Employee  ratelimit.Rule
+	Shared    ratelimit.Rule
+}
+
 type Router struct {
-	authHandler  AuthHandlerInterface
-	pvzHandler   PVZHandlerInterface
-	tokenManager *jwt.TokenManager
+	authHandler    AuthHandlerInterface
+	pvzHandler     PVZHandlerInterface
+	webhookHandler WebhookHandlerInterface
+	eventsHandler  EventsHandlerInterface
+	userHandler    UserHandlerInterface
+	apiKeyHandler  APIKeyHandlerInterface
+	jwksHandler    JWKSHandlerInterface
+	tokenManager   *jwt.TokenManager
+	sessions       appmiddleware.SessionValidator
+	apiKeys        appmiddleware.APIKeyAuthenticator
+	rateLimits     RateLimits
+	dummyLogin     bool
+	corsOrigins    []string
+	readiness      handlers.ReadinessChecker
 }
 
-func NewRouter(authHandler AuthHandlerInterface, pvzHandler PVZHandlerInterface, tokenManager *jwt.TokenManager) *Router {
-	return &Router{
-		authHandler:  authHandler,
-		pvzHandler:   pvzHandler,
-		tokenManager: tokenManager,
+type RouterOption func(*Router)
+
+// Ограничивает источники для CORS; по умолчанию разрешены все
+func WithCORSOrigins(origins []string) RouterOption {
+	return func(r *Router) {
+		r.corsOrigins = origins
 	}
 }
 
+// Включает /readyz с проверкой готовности экземпляра принимать трафик
+func WithReadiness(readiness handlers.ReadinessChecker) RouterOption {
+	return func(r *Router) {
+		r.readiness = readiness
+	}
+}
+
+// Отключает /dummyLogin, в prod тестовые токены не выдаются
+func WithoutDummyLogin() RouterOption {
+	return func(r *Router) {
+		r.dummyLogin = false
+	}
+}
+
+func NewRouter(authHandler AuthHandlerInterface, pvzHandler PVZHandlerInterface, webhookHandler WebhookHandlerInterface, eventsHandler EventsHandlerInterface, userHandler UserHandlerInterface, apiKeyHandler APIKeyHandlerInterface, jwksHandler JWKSHandlerInterface, tokenManager *jwt.TokenManager, sessions appmiddleware.SessionValidator, apiKeys appmiddleware.APIKeyAuthenticator, rateLimits RateLimits, opts ...RouterOption) *Router {
+	r := &Router{
+		authHandler:    authHandler,
+		pvzHandler:     pvzHandler,
+		webhookHandler: webhookHandler,
+		eventsHandler:  eventsHandler,
+		userHandler:    userHandler,
+		apiKeyHandler:  apiKeyHandler,
+		jwksHandler:    jwksHandler,
+		tokenManager:   tokenManager,
+		sessions:       sessions,
+		apiKeys:        apiKeys,
+		rateLimits:     rateLimits,
+		dummyLogin:     true,
+		corsOrigins:    []string{"*"},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
 func (r *Router) InitRoutes() *chi.Mux {
 	router := chi.NewRouter()
 
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   r.corsOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match", "Last-Event-ID", "X-API-Key"},
+		ExposedHeaders:   []string{"ETag", "Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -56,29 +160,76 @@ func (r *Router) InitRoutes() *chi.Mux {
 	router.Use(middleware.RequestID)
 	router.Use(appmiddleware.LoggerMiddleware)
 
-	router.Post("/register", r.authHandler.Register)
-	router.Post("/login", r.authHandler.Login)
-	router.Post("/dummyLogin", r.authHandler.DummyLogin)
+	router.Get("/.well-known/jwks.json", r.jwksHandler.Get)
+
+	healthHandler := handlers.NewHealthHandler(r.readiness)
+	router.Get("/healthz", healthHandler.Live)
+	if r.readiness != nil {
+		router.Get("/readyz", healthHandler.Ready)
+	}
 
 	router.Group(func(router chi.Router) {
-		router.Use(appmiddleware.AuthMiddleware(r.tokenManager))
+		router.Use(appmiddleware.RateLimitMiddleware("auth", ratelimit.NewLimiter(r.rateLimits.Auth)))
+		router.Post("/register", r.authHandler.Register)
+		router.Post("/login", r.authHandler.Login)
+		if r.dummyLogin {
+			router.Post("/dummyLogin", r.authHandler.DummyLogin)
+		}
+		router.Post("/password/reset-request", r.authHandler.RequestPasswordReset)
+		router.Post("/password/reset", r.authHandler.ResetPassword)
+	})
+
+	router.Group(func(router chi.Router) {
+		router.Use(appmiddleware.AuthMiddleware(r.tokenManager, r.sessions, r.apiKeys))
+
+		// Группы отличаются только лимитами запросов, доступ к каждому маршруту задается правом
+		can := appmiddleware.RequirePermission
 
 		router.Group(func(router chi.Router) {
-			router.Use(appmiddleware.RequireRole(models.ModeratorRole))
-			router.Post("/pvz", r.pvzHandler.Create)
+			router.Use(appmiddleware.RateLimitMiddleware("moderator", ratelimit.NewLimiter(r.rateLimits.Moderator)))
+			router.With(can(models.PermPVZCreate)).Post("/pvz", r.pvzHandler.Create)
+			router.With(can(models.PermAuthUnlock)).Post("/auth/unlock", r.authHandler.Unlock)
+			router.With(can(models.PermPVZUpdate)).Patch("/pvz/{pvzId}", r.pvzHandler.Update)
+			router.With(can(models.PermPVZStatus)).Post("/pvz/{pvzId}/deactivate", r.pvzHandler.Deactivate)
+			router.With(can(models.PermPVZStatus)).Post("/pvz/{pvzId}/activate", r.pvzHandler.Activate)
+			router.With(can(models.PermPVZStatus)).Post("/pvz/{pvzId}/archive", r.pvzHandler.Archive)
+			router.With(can(models.PermScheduleUpdate)).Put("/pvz/{pvzId}/schedule", r.pvzHandler.UpdateSchedule)
+			router.With(can(models.PermScheduleUpdate)).Put("/pvz/{pvzId}/calendar/{date}", r.pvzHandler.SetCalendarException)
+			router.With(can(models.PermScheduleUpdate)).Delete("/pvz/{pvzId}/calendar/{date}", r.pvzHandler.DeleteCalendarException)
+			router.With(can(models.PermSettingsRead)).Get("/pvz/{pvzId}/settings", r.pvzHandler.GetSettings)
+			router.With(can(models.PermSettingsUpdate)).Patch("/pvz/{pvzId}/settings", r.pvzHandler.UpdateSettings)
+			router.With(can(models.PermEventsCity)).Get("/pvz/events", r.eventsHandler.StreamCity)
+			router.With(can(models.PermWebhookManage)).Post("/webhooks", r.webhookHandler.Create)
+			router.With(can(models.PermWebhookManage)).Get("/webhooks", r.webhookHandler.List)
+			router.With(can(models.PermWebhookManage)).Get("/webhooks/{webhookId}", r.webhookHandler.Get)
+			router.With(can(models.PermWebhookManage)).Delete("/webhooks/{webhookId}", r.webhookHandler.Delete)
+			router.With(can(models.PermWebhookManage)).Get("/webhooks/{webhookId}/deliveries", r.webhookHandler.ListDeliveries)
+			router.With(can(models.PermUserManage)).Get("/users", r.userHandler.List)
+			router.With(can(models.PermUserManage)).Get("/users/{userId}", r.userHandler.Get)
+			router.With(can(models.PermUserManage)).Patch("/users/{userId}", r.userHandler.Update)
+			router.With(can(models.PermUserManage)).Delete("/users/{userId}", r.userHandler.Delete)
+			router.With(can(models.PermAPIKeyManage)).Post("/api-keys", r.apiKeyHandler.Create)
+			router.With(can(models.PermAPIKeyManage)).Get("/api-keys", r.apiKeyHandler.List)
+			router.With(can(models.PermAPIKeyManage)).Delete("/api-keys/{keyId}", r.apiKeyHandler.Revoke)
 		})
 
 		router.Group(func(router chi.Router) {
-			router.Use(appmiddleware.RequireRole(models.EmployeeRole))
-			router.Post("/receptions", r.pvzHandler.CreateReception)
-			router.Post("/products", r.pvzHandler.CreateProduct)
-			router.Post("/pvz/{pvzId}/delete_last_product", r.pvzHandler.DeleteLastProduct)
-			router.Post("/pvz/{pvzId}/close_last_reception", r.pvzHandler.CloseLastReception)
+			router.Use(appmiddleware.RateLimitMiddleware("employee", ratelimit.NewLimiter(r.rateLimits.Employee)))
+			router.With(can(models.PermReceptionCreate)).Post("/receptions", r.pvzHandler.CreateReception)
+			router.With(can(models.PermProductCreate)).Post("/products", r.pvzHandler.CreateProduct)
+			router.With(can(models.PermProductDelete)).Post("/pvz/{pvzId}/delete_last_product", r.pvzHandler.DeleteLastProduct)
+			router.With(can(models.PermReceptionClose)).Post("/pvz/{pvzId}/close_last_reception", r.pvzHandler.CloseLastReception)
 		})
 
 		router.Group(func(router chi.Router) {
-			router.Use(appmiddleware.RequireRoles([]models.Role{models.EmployeeRole, models.ModeratorRole}))
-			router.Get("/pvz", r.pvzHandler.GetPVZs)
+			router.Use(appmiddleware.RateLimitMiddleware("shared", ratelimit.NewLimiter(r.rateLimits.Shared)))
+			router.With(can(models.PermPVZRead)).Get("/pvz", r.pvzHandler.GetPVZs)
+			router.With(can(models.PermPVZRead)).Get("/pvz/nearby", r.pvzHandler.GetNearby)
+			router.With(can(models.PermPVZRead)).Get("/pvz/{pvzId}", r.pvzHandler.GetPVZ)
+			router.With(can(models.PermPVZRead)).Get("/pvz/{pvzId}/schedule", r.pvzHandler.GetSchedule)
+			router.With(can(models.PermEventsRead)).Get("/pvz/{pvzId}/events", r.eventsHandler.StreamPVZ)
+			// Сменить свой пароль может пользователь с любой ролью
+			router.Post("/me/password", r.authHandler.ChangePassword)
 		})
 	})
 
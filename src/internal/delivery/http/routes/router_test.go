@@ -1,10 +1,16 @@
 package routes
 
 import (
+	"avito-backend/src/internal/delivery/http/handlers"
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/pkg/jwt"
+	"avito-backend/src/pkg/ratelimit"
+	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -18,36 +24,109 @@ type MockAuthHandler struct {
 func (m *MockAuthHandler) Register(w http.ResponseWriter, r *http.Request)   { m.Called(w, r) }
 func (m *MockAuthHandler) Login(w http.ResponseWriter, r *http.Request)      { m.Called(w, r) }
 func (m *MockAuthHandler) DummyLogin(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+func (m *MockAuthHandler) Unlock(w http.ResponseWriter, r *http.Request)     { m.Called(w, r) }
+func (m *MockAuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	m.Called(w, r)
+}
+func (m *MockAuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	m.Called(w, r)
+}
+func (m *MockAuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
 
 type MockPVZHandler struct {
 	mock.Mock
 }
 
 func (m *MockPVZHandler) Create(w http.ResponseWriter, r *http.Request)             { m.Called(w, r) }
+func (m *MockPVZHandler) GetNearby(w http.ResponseWriter, r *http.Request)          { m.Called(w, r) }
+func (m *MockPVZHandler) Update(w http.ResponseWriter, r *http.Request)             { m.Called(w, r) }
+func (m *MockPVZHandler) Deactivate(w http.ResponseWriter, r *http.Request)         { m.Called(w, r) }
+func (m *MockPVZHandler) Activate(w http.ResponseWriter, r *http.Request)           { m.Called(w, r) }
+func (m *MockPVZHandler) Archive(w http.ResponseWriter, r *http.Request)            { m.Called(w, r) }
 func (m *MockPVZHandler) GetPVZs(w http.ResponseWriter, r *http.Request)            { m.Called(w, r) }
+func (m *MockPVZHandler) GetPVZ(w http.ResponseWriter, r *http.Request)             { m.Called(w, r) }
 func (m *MockPVZHandler) CreateReception(w http.ResponseWriter, r *http.Request)    { m.Called(w, r) }
 func (m *MockPVZHandler) CreateProduct(w http.ResponseWriter, r *http.Request)      { m.Called(w, r) }
 func (m *MockPVZHandler) DeleteLastProduct(w http.ResponseWriter, r *http.Request)  { m.Called(w, r) }
 func (m *MockPVZHandler) CloseLastReception(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+func (m *MockPVZHandler) GetSchedule(w http.ResponseWriter, r *http.Request)        { m.Called(w, r) }
+func (m *MockPVZHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request)     { m.Called(w, r) }
+func (m *MockPVZHandler) SetCalendarException(w http.ResponseWriter, r *http.Request) {
+	m.Called(w, r)
+}
+func (m *MockPVZHandler) DeleteCalendarException(w http.ResponseWriter, r *http.Request) {
+	m.Called(w, r)
+}
+func (m *MockPVZHandler) GetSettings(w http.ResponseWriter, r *http.Request)    { m.Called(w, r) }
+func (m *MockPVZHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+
+type MockWebhookHandler struct {
+	mock.Mock
+}
+
+func (m *MockWebhookHandler) Create(w http.ResponseWriter, r *http.Request)         { m.Called(w, r) }
+func (m *MockWebhookHandler) List(w http.ResponseWriter, r *http.Request)           { m.Called(w, r) }
+func (m *MockWebhookHandler) Get(w http.ResponseWriter, r *http.Request)            { m.Called(w, r) }
+func (m *MockWebhookHandler) Delete(w http.ResponseWriter, r *http.Request)         { m.Called(w, r) }
+func (m *MockWebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+
+type MockAPIKeyHandler struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyHandler) Create(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+func (m *MockAPIKeyHandler) List(w http.ResponseWriter, r *http.Request)   { m.Called(w, r) }
+func (m *MockAPIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+
+type MockJWKSHandler struct {
+	mock.Mock
+}
+
+func (m *MockJWKSHandler) Get(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+
+type MockEventsHandler struct {
+	mock.Mock
+}
+
+func (m *MockEventsHandler) StreamPVZ(w http.ResponseWriter, r *http.Request)  { m.Called(w, r) }
+func (m *MockEventsHandler) StreamCity(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+
+type MockUserHandler struct {
+	mock.Mock
+}
+
+func (m *MockUserHandler) List(w http.ResponseWriter, r *http.Request)   { m.Called(w, r) }
+func (m *MockUserHandler) Get(w http.ResponseWriter, r *http.Request)    { m.Called(w, r) }
+func (m *MockUserHandler) Update(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
+func (m *MockUserHandler) Delete(w http.ResponseWriter, r *http.Request) { m.Called(w, r) }
 
 func TestNewRouter(t *testing.T) {
 	authHandler := &MockAuthHandler{}
 	pvzHandler := &MockPVZHandler{}
-	tokenManager := jwt.NewTokenManager("test-key", "1h")
+	webhookHandler := &MockWebhookHandler{}
+	eventsHandler := &MockEventsHandler{}
+	userHandler := &MockUserHandler{}
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
 
-	router := NewRouter(authHandler, pvzHandler, tokenManager)
+	router := NewRouter(authHandler, pvzHandler, webhookHandler, eventsHandler, userHandler, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{})
 
 	assert.NotNil(t, router)
 	assert.Equal(t, authHandler, router.authHandler)
 	assert.Equal(t, pvzHandler, router.pvzHandler)
+	assert.Equal(t, webhookHandler, router.webhookHandler)
+	assert.Equal(t, eventsHandler, router.eventsHandler)
+	assert.Equal(t, userHandler, router.userHandler)
 	assert.Equal(t, tokenManager, router.tokenManager)
 }
 
 func TestRouter_InitRoutes(t *testing.T) {
 	authHandler := &MockAuthHandler{}
 	pvzHandler := &MockPVZHandler{}
-	tokenManager := jwt.NewTokenManager("test-key", "1h")
-	router := NewRouter(authHandler, pvzHandler, tokenManager)
+	webhookHandler := &MockWebhookHandler{}
+	eventsHandler := &MockEventsHandler{}
+	userHandler := &MockUserHandler{}
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	router := NewRouter(authHandler, pvzHandler, webhookHandler, eventsHandler, userHandler, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{})
 
 	r := router.InitRoutes()
 
@@ -60,12 +139,38 @@ func TestRouter_InitRoutes(t *testing.T) {
 		{"POST", "/register"},
 		{"POST", "/login"},
 		{"POST", "/dummyLogin"},
+		{"POST", "/password/reset-request"},
+		{"POST", "/password/reset"},
+		{"POST", "/me/password"},
 		{"POST", "/pvz"},
+		{"POST", "/auth/unlock"},
 		{"GET", "/pvz"},
+		{"GET", "/pvz/nearby"},
+		{"GET", "/pvz/{pvzId}/schedule"},
+		{"PUT", "/pvz/{pvzId}/schedule"},
+		{"PUT", "/pvz/{pvzId}/calendar/{date}"},
+		{"DELETE", "/pvz/{pvzId}/calendar/{date}"},
+		{"GET", "/pvz/{pvzId}/settings"},
+		{"PATCH", "/pvz/{pvzId}/settings"},
+		{"GET", "/pvz/{pvzId}/events"},
+		{"GET", "/pvz/events"},
 		{"POST", "/receptions"},
 		{"POST", "/products"},
 		{"POST", "/pvz/{pvzId}/delete_last_product"},
 		{"POST", "/pvz/{pvzId}/close_last_reception"},
+		{"PATCH", "/pvz/{pvzId}"},
+		{"POST", "/pvz/{pvzId}/deactivate"},
+		{"POST", "/pvz/{pvzId}/activate"},
+		{"POST", "/pvz/{pvzId}/archive"},
+		{"POST", "/webhooks"},
+		{"GET", "/webhooks"},
+		{"GET", "/webhooks/{webhookId}"},
+		{"DELETE", "/webhooks/{webhookId}"},
+		{"GET", "/webhooks/{webhookId}/deliveries"},
+		{"GET", "/users"},
+		{"GET", "/users/{userId}"},
+		{"PATCH", "/users/{userId}"},
+		{"DELETE", "/users/{userId}"},
 	}
 
 	for _, rt := range routes {
@@ -85,8 +190,11 @@ func TestRouter_InitRoutes(t *testing.T) {
 func TestRouter_Middleware(t *testing.T) {
 	authHandler := &MockAuthHandler{}
 	pvzHandler := &MockPVZHandler{}
-	tokenManager := jwt.NewTokenManager("test-key", "1h")
-	router := NewRouter(authHandler, pvzHandler, tokenManager)
+	webhookHandler := &MockWebhookHandler{}
+	eventsHandler := &MockEventsHandler{}
+	userHandler := &MockUserHandler{}
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	router := NewRouter(authHandler, pvzHandler, webhookHandler, eventsHandler, userHandler, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{})
 
 	r := router.InitRoutes()
 
@@ -152,3 +260,117 @@ func TestRouter_Middleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRouter_Permissions(t *testing.T) {
+	models.SetRolePermissions(map[models.Role][]models.Permission{
+		"analyst":     {models.PermPVZRead},
+		"pvz_manager": {models.PermPVZRead, models.PermPVZCreate},
+	})
+	t.Cleanup(func() { models.SetRolePermissions(models.DefaultRolePermissions()) })
+
+	pvzHandler := &MockPVZHandler{}
+	pvzHandler.On("Create", mock.Anything, mock.Anything).Return()
+	pvzHandler.On("GetPVZs", mock.Anything, mock.Anything).Return()
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	router := NewRouter(&MockAuthHandler{}, pvzHandler, &MockWebhookHandler{}, &MockEventsHandler{}, &MockUserHandler{}, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{})
+	r := router.InitRoutes()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		role     models.Role
+		wantCode int
+	}{
+		{name: "Аналитик читает список ПВЗ", method: "GET", path: "/pvz", role: "analyst", wantCode: http.StatusOK},
+		{name: "Аналитик не создает ПВЗ", method: "POST", path: "/pvz", role: "analyst", wantCode: http.StatusForbidden},
+		{name: "Менеджер ПВЗ создает ПВЗ", method: "POST", path: "/pvz", role: "pvz_manager", wantCode: http.StatusOK},
+		{name: "Менеджер ПВЗ не управляет пользователями", method: "GET", path: "/users", role: "pvz_manager", wantCode: http.StatusForbidden},
+		{name: "Модератор без прав в политике", method: "POST", path: "/pvz", role: models.ModeratorRole, wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := tokenManager.GenerateToken(string(tt.role))
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestRouter_JWKS(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	router := NewRouter(&MockAuthHandler{}, &MockPVZHandler{}, &MockWebhookHandler{}, &MockEventsHandler{}, &MockUserHandler{}, &MockAPIKeyHandler{}, handlers.NewJWKSHandler(tokenManager), tokenManager, nil, nil, RateLimits{})
+	r := router.InitRoutes()
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, "эндпоинт доступен без авторизации")
+	assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+}
+
+type stubReadiness struct {
+	err error
+}
+
+func (s stubReadiness) Ready(ctx context.Context) error {
+	return s.err
+}
+
+func TestRouter_Health(t *testing.T) {
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+
+	r := NewRouter(&MockAuthHandler{}, &MockPVZHandler{}, &MockWebhookHandler{}, &MockEventsHandler{}, &MockUserHandler{}, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{}).InitRoutes()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "эндпоинт доступен без авторизации")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "без проверки готовности /readyz не регистрируется")
+
+	r = NewRouter(&MockAuthHandler{}, &MockPVZHandler{}, &MockWebhookHandler{}, &MockEventsHandler{}, &MockUserHandler{}, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{},
+		WithReadiness(stubReadiness{err: errors.New("instance is shutting down")})).InitRoutes()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestRouter_WithoutDummyLogin(t *testing.T) {
+	authHandler := &MockAuthHandler{}
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	router := NewRouter(authHandler, &MockPVZHandler{}, &MockWebhookHandler{}, &MockEventsHandler{}, &MockUserHandler{}, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil, RateLimits{}, WithoutDummyLogin())
+	r := router.InitRoutes()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/dummyLogin", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	authHandler.AssertNotCalled(t, "DummyLogin", mock.Anything, mock.Anything)
+}
+
+func TestRouter_RateLimit(t *testing.T) {
+	authHandler := &MockAuthHandler{}
+	authHandler.On("DummyLogin", mock.Anything, mock.Anything).Return()
+	tokenManager := jwt.NewTokenManager("test-key", time.Hour)
+	router := NewRouter(authHandler, &MockPVZHandler{}, &MockWebhookHandler{}, &MockEventsHandler{}, &MockUserHandler{}, &MockAPIKeyHandler{}, &MockJWKSHandler{}, tokenManager, nil, nil,
+		RateLimits{Auth: ratelimit.Rule{Requests: 1, Per: time.Minute}})
+
+	r := router.InitRoutes()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dummyLogin", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dummyLogin", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	authHandler.AssertNumberOfCalls(t, "DummyLogin", 1)
+}
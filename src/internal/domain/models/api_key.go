@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ключ для межсервисных клиентов. В БД хранится только SHA-256 ключа, сам ключ
+// показывается один раз при создании. Пустые Permissions и PVZIDs означают
+// все права роли и все ПВЗ
+type APIKey struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Prefix      string       `json:"prefix"`
+	KeyHash     string       `json:"-"`
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+	PVZIDs      []uuid.UUID  `json:"pvzIds"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time   `json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time   `json:"revokedAt,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	CreatedBy   *uuid.UUID   `json:"createdBy,omitempty"`
+}
+
+// Право должно быть и у роли ключа, и в его списке прав, если список задан
+func (k *APIKey) Allows(permission Permission) bool {
+	if !k.Role.Can(permission) {
+		return false
+	}
+	if len(k.Permissions) == 0 {
+		return true
+	}
+	for _, p := range k.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) AllowsPVZ(pvzID uuid.UUID) bool {
+	if len(k.PVZIDs) == 0 {
+		return true
+	}
+	for _, id := range k.PVZIDs {
+		if id == pvzID {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditAccountLocked   AuditAction = "account_locked"
+	AuditIPLocked        AuditAction = "ip_locked"
+	AuditAccountUnlocked AuditAction = "account_unlocked"
+	AuditIPUnlocked      AuditAction = "ip_unlocked"
+	AuditPasswordChanged AuditAction = "password_changed"
+	AuditPasswordReset   AuditAction = "password_reset"
+	AuditUserUpdated     AuditAction = "user_updated"
+	AuditUserDeleted     AuditAction = "user_deleted"
+	AuditAPIKeyCreated   AuditAction = "api_key_created"
+	AuditAPIKeyRevoked   AuditAction = "api_key_revoked"
+)
+
+// Запись журнала аудита. ActorID — кто выполнил действие, UserID/Email/IP — над кем
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Action    AuditAction     `json:"action"`
+	ActorID   *uuid.UUID      `json:"actorId,omitempty"`
+	UserID    *uuid.UUID      `json:"userId,omitempty"`
+	Email     string          `json:"email,omitempty"`
+	IP        string          `json:"ip,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
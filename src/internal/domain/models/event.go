@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	ReceptionCreated EventType = "ReceptionCreated"
+	ProductAdded     EventType = "ProductAdded"
+	ProductRemoved   EventType = "ProductRemoved"
+	ReceptionClosed  EventType = "ReceptionClosed"
+)
+
+// Доменное событие из таблицы outbox; сериализуется целиком при публикации
+type OutboxEvent struct {
+	ID        int64           `json:"-"`
+	EventID   uuid.UUID       `json:"id"`
+	Type      EventType       `json:"type"`
+	PVZID     uuid.UUID       `json:"pvzId"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"occurredAt"`
+	Attempts  int             `json:"-"`
+	City      City            `json:"-"`
+}
+
+// Фильтр потока событий: по одному ПВЗ или по всем ПВЗ города
+type EventFilter struct {
+	PVZID *uuid.UUID
+	City  *City
+}
+
+func (f EventFilter) Matches(event *OutboxEvent) bool {
+	if f.PVZID != nil && *f.PVZID != event.PVZID {
+		return false
+	}
+	if f.City != nil && *f.City != event.City {
+		return false
+	}
+	return true
+}
+
+func (t EventType) IsValid() bool {
+	switch t {
+	case ReceptionCreated, ProductAdded, ProductRemoved, ReceptionClosed:
+		return true
+	}
+	return false
+}
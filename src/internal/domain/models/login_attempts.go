@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// По чему считаются неудачные попытки входа
+type LoginScope string
+
+const (
+	LoginScopeEmail LoginScope = "email"
+	LoginScopeIP    LoginScope = "ip"
+)
+
+type LoginAttempts struct {
+	Scope        LoginScope
+	Key          string
+	FailedCount  int
+	Lockouts     int
+	LockedUntil  *time.Time
+	LastFailedAt *time.Time
+}
+
+func (a *LoginAttempts) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"sort"
+	"sync"
+)
+
+// Право на действие; роли получают набор прав из конфигурации RBAC
+type Permission string
+
+const (
+	PermPVZRead            Permission = "pvz:read"
+	PermPVZCreate          Permission = "pvz:create"
+	PermPVZUpdate          Permission = "pvz:update"
+	PermPVZStatus          Permission = "pvz:status"
+	PermScheduleUpdate     Permission = "schedule:update"
+	PermSettingsRead       Permission = "settings:read"
+	PermSettingsUpdate     Permission = "settings:update"
+	PermEventsRead         Permission = "events:read"
+	PermEventsCity         Permission = "events:city"
+	PermReceptionCreate    Permission = "reception:create"
+	PermReceptionClose     Permission = "reception:close"
+	PermProductCreate      Permission = "product:create"
+	PermProductDelete      Permission = "product:delete"
+	PermProductReadDeleted Permission = "product:read_deleted"
+	PermWebhookManage      Permission = "webhook:manage"
+	PermUserManage         Permission = "user:manage"
+	PermAuthUnlock         Permission = "auth:unlock"
+	PermAPIKeyManage       Permission = "apikey:manage"
+)
+
+var knownPermissions = map[Permission]bool{
+	PermPVZRead: true, PermPVZCreate: true, PermPVZUpdate: true, PermPVZStatus: true,
+	PermScheduleUpdate: true, PermSettingsRead: true, PermSettingsUpdate: true,
+	PermEventsRead: true, PermEventsCity: true,
+	PermReceptionCreate: true, PermReceptionClose: true,
+	PermProductCreate: true, PermProductDelete: true, PermProductReadDeleted: true,
+	PermWebhookManage: true, PermUserManage: true, PermAuthUnlock: true, PermAPIKeyManage: true,
+}
+
+func (p Permission) IsValid() bool {
+	return knownPermissions[p]
+}
+
+// Права ролей до загрузки конфигурации; совпадают с прежними проверками ролей в роутере
+func DefaultRolePermissions() map[Role][]Permission {
+	return map[Role][]Permission{
+		ModeratorRole: {
+			PermPVZRead, PermPVZCreate, PermPVZUpdate, PermPVZStatus, PermScheduleUpdate,
+			PermSettingsRead, PermSettingsUpdate, PermEventsRead, PermEventsCity,
+			PermProductReadDeleted, PermWebhookManage, PermUserManage, PermAuthUnlock, PermAPIKeyManage,
+		},
+		EmployeeRole: {
+			PermPVZRead, PermEventsRead,
+			PermReceptionCreate, PermReceptionClose, PermProductCreate, PermProductDelete,
+		},
+	}
+}
+
+var (
+	rolesMu         sync.RWMutex
+	rolePermissions = buildRolePermissions(DefaultRolePermissions())
+)
+
+// Заменяет набор ролей и их прав. Вызывается при старте, после чтения конфигурации RBAC
+func SetRolePermissions(permissions map[Role][]Permission) {
+	built := buildRolePermissions(permissions)
+
+	rolesMu.Lock()
+	defer rolesMu.Unlock()
+	rolePermissions = built
+}
+
+func buildRolePermissions(permissions map[Role][]Permission) map[Role]map[Permission]bool {
+	built := make(map[Role]map[Permission]bool, len(permissions))
+	for role, perms := range permissions {
+		set := make(map[Permission]bool, len(perms))
+		for _, perm := range perms {
+			set[perm] = true
+		}
+		built[role] = set
+	}
+	return built
+}
+
+func (r Role) Can(permission Permission) bool {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+	return rolePermissions[r][permission]
+}
+
+// Список известных ролей в алфавитном порядке
+func Roles() []Role {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+
+	roles := make([]Role, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+	return roles
+}
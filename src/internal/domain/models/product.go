@@ -19,6 +19,8 @@ type Product struct {
 	DateTime    time.Time   `json:"dateTime"`
 	Type        ProductType `json:"type"`
 	ReceptionID uuid.UUID   `json:"receptionId"`
+	DeletedAt   *time.Time  `json:"deletedAt,omitempty"`
+	DeletedBy   *uuid.UUID  `json:"deletedBy,omitempty"`
 }
 
 func (t ProductType) IsValid() bool {
@@ -14,16 +14,67 @@ const (
 	Kazan  City = "Казань"
 )
 
+type PVZStatus string
+
+const (
+	PVZActive   PVZStatus = "active"
+	PVZInactive PVZStatus = "inactive"
+	PVZArchived PVZStatus = "archived"
+)
+
 type PVZ struct {
-	ID               uuid.UUID    `json:"id"`
-	RegistrationDate time.Time    `json:"registrationDate"`
-	City             City         `json:"city"`
+	ID               uuid.UUID  `json:"id"`
+	RegistrationDate time.Time  `json:"registrationDate"`
+	City             City       `json:"city"`
+	Status           PVZStatus  `json:"status"`
+	UpdatedAt        *time.Time `json:"updatedAt,omitempty"`
+	Address          string     `json:"address,omitempty"`
+	Latitude         *float64   `json:"latitude,omitempty"`
+	Longitude        *float64   `json:"longitude,omitempty"`
+	IsOpen           *bool      `json:"isOpen,omitempty"`
 }
 
 func (c City) IsValid() bool {
 	return c == Moscow || c == SPB || c == Kazan
 }
 
+func (s PVZStatus) IsValid() bool {
+	return s == PVZActive || s == PVZInactive || s == PVZArchived
+}
+
+// Force подтверждает создание ПВЗ рядом с уже существующим
+type PVZCreate struct {
+	City      City
+	Address   string
+	Latitude  *float64
+	Longitude *float64
+	Force     bool
+}
+
+// Поля с nil не изменяются
+type PVZUpdate struct {
+	City      *City
+	Address   *string
+	Latitude  *float64
+	Longitude *float64
+}
+
+type PVZWithDistance struct {
+	PVZ        *PVZ    `json:"pvz"`
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+type PVZFilter struct {
+	StartDate       time.Time
+	EndDate         time.Time
+	Offset          int
+	Limit           int
+	IncludeDeleted  bool
+	IncludeArchived bool
+	// Если задан, выдача ограничивается этими ПВЗ
+	PVZIDs []uuid.UUID
+}
+
 type PVZWithReceptions struct {
 	PVZ        *PVZ                    `json:"pvz"`
 	Receptions []ReceptionWithProducts `json:"receptions"`
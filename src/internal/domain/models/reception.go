@@ -14,8 +14,10 @@ const (
 )
 
 type Reception struct {
-	ID       uuid.UUID       `json:"id"`
-	DateTime time.Time       `json:"dateTime"`
-	PVZID    uuid.UUID       `json:"pvzId"`
-	Status   ReceptionStatus `json:"status"`
+	ID             uuid.UUID       `json:"id"`
+	DateTime       time.Time       `json:"dateTime"`
+	PVZID          uuid.UUID       `json:"pvzId"`
+	Status         ReceptionStatus `json:"status"`
+	ClosedAt       *time.Time      `json:"closedAt,omitempty"`
+	ClosedBySystem bool            `json:"closedBySystem,omitempty"`
 }
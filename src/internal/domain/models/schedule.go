@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	// База часовых поясов встраивается в бинарник, в минимальных образах ее может не быть
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTimezone = "Europe/Moscow"
+	DateLayout      = "2006-01-02"
+)
+
+// Минуты от полуночи, в JSON передается как "HH:MM"
+type ClockTime int
+
+const EndOfDay ClockTime = 24 * 60
+
+func ParseClockTime(value string) (ClockTime, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%2d:%2d", &hours, &minutes); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("неверный формат времени %q", value)
+	}
+
+	clock := ClockTime(hours*60 + minutes)
+	if minutes < 0 || minutes > 59 || hours < 0 || clock > EndOfDay {
+		return 0, fmt.Errorf("неверное время %q", value)
+	}
+	return clock, nil
+}
+
+func (c ClockTime) String() string {
+	return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60)
+}
+
+func (c ClockTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+func (c *ClockTime) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseClockTime(value)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+type WorkingHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Opens   ClockTime    `json:"opens"`
+	Closes  ClockTime    `json:"closes"`
+}
+
+// Исключение из недельного графика на конкретную дату (праздник, сокращенный день)
+type CalendarException struct {
+	Date    string     `json:"date"`
+	Closed  bool       `json:"closed"`
+	Opens   *ClockTime `json:"opens,omitempty"`
+	Closes  *ClockTime `json:"closes,omitempty"`
+	Comment string     `json:"comment,omitempty"`
+}
+
+type PVZSchedule struct {
+	PVZID         uuid.UUID           `json:"pvzId"`
+	Timezone      string              `json:"timezone"`
+	WorkingHours  []WorkingHours      `json:"workingHours"`
+	Exceptions    []CalendarException `json:"exceptions"`
+	OverrideUntil *time.Time          `json:"overrideUntil,omitempty"`
+}
+
+type PVZScheduleUpdate struct {
+	Timezone      string
+	WorkingHours  []WorkingHours
+	OverrideUntil *time.Time
+}
+
+func DefaultSchedule(pvzID uuid.UUID) *PVZSchedule {
+	return &PVZSchedule{
+		PVZID:        pvzID,
+		Timezone:     DefaultTimezone,
+		WorkingHours: []WorkingHours{},
+		Exceptions:   []CalendarException{},
+	}
+}
+
+func (s *PVZSchedule) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ПВЗ без заданного графика считается работающим круглосуточно
+func (s *PVZSchedule) IsOpenAt(t time.Time) bool {
+	local := t.In(s.Location())
+	minute := ClockTime(local.Hour()*60 + local.Minute())
+	date := local.Format(DateLayout)
+
+	for _, exception := range s.Exceptions {
+		if exception.Date != date {
+			continue
+		}
+		if exception.Closed || exception.Opens == nil || exception.Closes == nil {
+			return false
+		}
+		return *exception.Opens <= minute && minute < *exception.Closes
+	}
+
+	if len(s.WorkingHours) == 0 {
+		return true
+	}
+
+	for _, hours := range s.WorkingHours {
+		if hours.Weekday == local.Weekday() {
+			return hours.Opens <= minute && minute < hours.Closes
+		}
+	}
+	return false
+}
+
+func (s *PVZSchedule) OverrideActive(t time.Time) bool {
+	return s.OverrideUntil != nil && t.Before(*s.OverrideUntil)
+}
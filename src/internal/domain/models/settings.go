@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Операционные настройки ПВЗ; MaxProductsPerReception = 0 означает отсутствие лимита
+type PVZSettings struct {
+	PVZID                   uuid.UUID     `json:"pvzId"`
+	MaxProductsPerReception int           `json:"maxProductsPerReception"`
+	AllowedProductTypes     []ProductType `json:"allowedProductTypes"`
+	AllowEmptyClose         bool          `json:"allowEmptyClose"`
+	UpdatedAt               *time.Time    `json:"updatedAt,omitempty"`
+}
+
+type PVZSettingsUpdate struct {
+	MaxProductsPerReception *int
+	AllowedProductTypes     []ProductType
+	AllowEmptyClose         *bool
+}
+
+func AllProductTypes() []ProductType {
+	return []ProductType{Electronics, Clothes, Shoes}
+}
+
+func (s *PVZSettings) AllowsProductType(productType ProductType) bool {
+	for _, allowed := range s.AllowedProductTypes {
+		if allowed == productType {
+			return true
+		}
+	}
+	return false
+}
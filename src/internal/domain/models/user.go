@@ -14,8 +14,28 @@ type User struct {
 	Email        string    `json:"email"`
 	Role         string    `json:"role"`
 	PasswordHash string    `json:"-"`
+	TokenVersion int       `json:"-"`
+	Disabled     bool      `json:"disabled"`
 }
 
+// Фильтр списка пользователей; Email ищется по подстроке без учета регистра
+type UserFilter struct {
+	Role   *Role
+	Email  string
+	Offset int
+	Limit  int
+}
+
+// Изменения пользователя модератором; nil означает, что поле не меняется
+type UserUpdate struct {
+	Role     *Role
+	Disabled *bool
+}
+
+// Роль допустима, если она описана в конфигурации RBAC
 func (r Role) IsValid() bool {
-	return r == EmployeeRole || r == ModeratorRole
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+	_, ok := rolePermissions[r]
+	return ok
 }
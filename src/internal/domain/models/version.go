@@ -0,0 +1,121 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+	"time"
+)
+
+// Версия данных для сильного ETag. Собирается из идентификаторов, времени изменений и счетчиков,
+// поэтому не требует сериализации ответа
+type version struct {
+	h hash.Hash
+}
+
+func newVersion(kind string) *version {
+	v := &version{h: sha256.New()}
+	v.add(kind)
+	return v
+}
+
+func (v *version) add(parts ...string) {
+	for _, part := range parts {
+		v.h.Write([]byte(part))
+		v.h.Write([]byte{0})
+	}
+}
+
+func (v *version) addTime(t *time.Time) {
+	if t == nil {
+		v.add("-")
+		return
+	}
+	v.add(strconv.FormatInt(t.UnixNano(), 10))
+}
+
+func (v *version) addBool(b *bool) {
+	if b == nil {
+		v.add("-")
+		return
+	}
+	v.add(strconv.FormatBool(*b))
+}
+
+func (v *version) String() string {
+	return hex.EncodeToString(v.h.Sum(nil)[:16])
+}
+
+// Версия страницы списка: состав и порядок ПВЗ, их статус и признак работы, а также самое
+// позднее изменение ПВЗ, приемок и товаров и их количество
+func PVZListVersion(pvzs []*PVZWithReceptions) string {
+	v := newVersion("pvz-list")
+	var latest time.Time
+	receptions, products := 0, 0
+	later := func(t *time.Time) {
+		if t != nil && t.After(latest) {
+			latest = *t
+		}
+	}
+
+	for _, item := range pvzs {
+		v.add(item.PVZ.ID.String(), string(item.PVZ.Status))
+		v.addBool(item.PVZ.IsOpen)
+		later(&item.PVZ.RegistrationDate)
+		later(item.PVZ.UpdatedAt)
+
+		for _, reception := range item.Receptions {
+			receptions++
+			later(&reception.Reception.DateTime)
+			later(reception.Reception.ClosedAt)
+
+			for _, product := range reception.Products {
+				products++
+				later(&product.DateTime)
+				later(product.DeletedAt)
+			}
+		}
+	}
+
+	v.addTime(&latest)
+	v.add(strconv.Itoa(receptions), strconv.Itoa(products))
+	return v.String()
+}
+
+func (p *PVZWithReceptions) Version() string {
+	return PVZListVersion([]*PVZWithReceptions{p})
+}
+
+// У графика нет времени изменения, поэтому в версию входят все его поля
+func (s *PVZSchedule) Version() string {
+	v := newVersion("schedule")
+	v.add(s.PVZID.String(), s.Timezone)
+	v.addTime(s.OverrideUntil)
+	for _, hours := range s.WorkingHours {
+		v.add(strconv.Itoa(int(hours.Weekday)), hours.Opens.String(), hours.Closes.String())
+	}
+	v.add("exceptions")
+	for _, exception := range s.Exceptions {
+		v.add(exception.Date, strconv.FormatBool(exception.Closed), exception.Comment)
+		for _, clock := range []*ClockTime{exception.Opens, exception.Closes} {
+			if clock == nil {
+				v.add("-")
+				continue
+			}
+			v.add(clock.String())
+		}
+	}
+	return v.String()
+}
+
+// Глобальные настройки по умолчанию не имеют времени изменения, поэтому значения тоже входят в версию
+func (s *PVZSettings) Version() string {
+	v := newVersion("settings")
+	v.add(s.PVZID.String(), strconv.Itoa(s.MaxProductsPerReception), strconv.FormatBool(s.AllowEmptyClose))
+	v.addTime(s.UpdatedAt)
+	for _, productType := range s.AllowedProductTypes {
+		v.add(string(productType))
+	}
+	return v.String()
+}
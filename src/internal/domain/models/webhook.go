@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Подписка партнера на события; фильтры по ПВЗ и городу необязательны
+type WebhookSubscription struct {
+	ID         uuid.UUID   `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"eventTypes"`
+	PVZID      *uuid.UUID  `json:"pvzId,omitempty"`
+	City       *City       `json:"city,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	CreatedBy  *uuid.UUID  `json:"createdBy,omitempty"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDead      WebhookDeliveryStatus = "dead"
+)
+
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscriptionId"`
+	EventID        uuid.UUID             `json:"eventId"`
+	EventType      EventType             `json:"eventType"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	AttemptCount   int                   `json:"attemptCount"`
+	NextAttemptAt  *time.Time            `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	Attempts       []WebhookAttempt      `json:"attempts"`
+}
+
+type WebhookAttempt struct {
+	Number      int       `json:"number"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+	StatusCode  *int      `json:"statusCode,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+}
+
+// Доставка вместе с адресом и секретом подписки, которые нужны для отправки
+type WebhookDispatch struct {
+	Delivery *WebhookDelivery
+	URL      string
+	Secret   string
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"avito-backend/src/pkg/database"
+)
+
+var ErrDraining = errors.New("instance is shutting down")
+
+// Непройденная проверка готовности. Имя проверки можно показать клиенту, а причину
+// только писать в лог: в ней бывают адреса и тексты ошибок базы
+type CheckError struct {
+	Check string
+	Err   error
+}
+
+func (e *CheckError) Error() string {
+	return e.Check + ": " + e.Err.Error()
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
+// Готовность принимать трафик: база доступна, миграции накатаны до ожидаемой версии
+// и экземпляр не начал завершение работы
+type Readiness struct {
+	db              *sql.DB
+	expectedVersion uint
+	timeout         time.Duration
+	draining        atomic.Bool
+}
+
+func NewReadiness(db *sql.DB, expectedVersion uint, timeout time.Duration) *Readiness {
+	return &Readiness{
+		db:              db,
+		expectedVersion: expectedVersion,
+		timeout:         timeout,
+	}
+}
+
+// Вызывается по SIGTERM: балансировщик перестает слать запросы до того, как сервер остановится
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+func (r *Readiness) Ready(ctx context.Context) error {
+	if r.draining.Load() {
+		return &CheckError{Check: "shutdown", Err: ErrDraining}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		return &CheckError{Check: "database", Err: fmt.Errorf("database unreachable: %w", err)}
+	}
+
+	version, dirty, err := database.MigrationVersion(ctx, r.db)
+	if err != nil {
+		return &CheckError{Check: "migrations", Err: fmt.Errorf("cannot read migration version: %w", err)}
+	}
+	if dirty {
+		return &CheckError{Check: "migrations", Err: fmt.Errorf("migration %d is dirty", version)}
+	}
+	if version != r.expectedVersion {
+		return &CheckError{Check: "migrations", Err: fmt.Errorf("migration version %d, expected %d", version, r.expectedVersion)}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package health_test
+
+import (
+	"avito-backend/src/internal/health"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const versionQuery = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+func TestReadiness_Ready(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(mock sqlmock.Sqlmock)
+		wantErr   string
+		wantCheck string
+	}{
+		{
+			name: "Ready",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+				mock.ExpectQuery(regexp.QuoteMeta(versionQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(16, false))
+			},
+		},
+		{
+			name: "Database unreachable",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+			},
+			wantErr:   "database unreachable",
+			wantCheck: "database",
+		},
+		{
+			name: "Migrations behind",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+				mock.ExpectQuery(regexp.QuoteMeta(versionQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(15, false))
+			},
+			wantErr:   "migration version 15, expected 16",
+			wantCheck: "migrations",
+		},
+		{
+			name: "Dirty migration",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectPing()
+				mock.ExpectQuery(regexp.QuoteMeta(versionQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(16, true))
+			},
+			wantErr:   "migration 16 is dirty",
+			wantCheck: "migrations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			defer db.Close()
+			tt.setup(mock)
+
+			err = health.NewReadiness(db, 16, time.Second).Ready(context.Background())
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+				var checkErr *health.CheckError
+				require.ErrorAs(t, err, &checkErr)
+				assert.Equal(t, tt.wantCheck, checkErr.Check)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReadiness_Drain(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	readiness := health.NewReadiness(db, 16, time.Second)
+	readiness.Drain()
+
+	assert.ErrorIs(t, readiness.Ready(context.Background()), health.ErrDraining)
+	assert.NoError(t, mock.ExpectationsWereMet(), "после SIGTERM база не опрашивается")
+}
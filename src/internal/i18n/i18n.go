@@ -0,0 +1,122 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Язык сообщений, если клиент не передал Accept-Language или ни один из его языков не поддерживается
+const DefaultLanguage = "ru"
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+// Каталоги сообщений по языкам: ключ сообщения -> текст с подстановками вида {name}
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("read locales: %v", err))
+	}
+
+	result := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("read locale %s: %v", entry.Name(), err))
+		}
+		var catalog map[string]string
+		if err := yaml.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("parse locale %s: %v", entry.Name(), err))
+		}
+		result[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = catalog
+	}
+
+	if _, ok := result[DefaultLanguage]; !ok {
+		panic("default locale " + DefaultLanguage + " is missing")
+	}
+	return result
+}
+
+// Поддерживаемые языки в алфавитном порядке
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Ключи каталога языка; nil, если язык не поддерживается
+func Keys(lang string) []string {
+	catalog, ok := catalogs[lang]
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(catalog))
+	for key := range catalog {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Текст сообщения из каталога языка без подстановок и без перехода на язык по умолчанию
+func Lookup(lang, key string) (string, bool) {
+	text, ok := catalogs[lang][key]
+	return text, ok
+}
+
+// Сообщение на языке lang с подставленными params. Отсутствующий перевод берется из каталога
+// по умолчанию, а неизвестный ключ возвращается как есть, чтобы клиент получил хотя бы его
+func Translate(lang, key string, params map[string]any) string {
+	text, ok := Lookup(lang, key)
+	if !ok {
+		if text, ok = Lookup(DefaultLanguage, key); !ok {
+			return key
+		}
+	}
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
+
+// Выбирает поддерживаемый язык по заголовку Accept-Language с учетом q-значений.
+// Регион не учитывается: en-US и en-GB получают en
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := catalogs[lang]; !ok {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"avito-backend/src/internal/i18n"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{[a-zA-Z]+\}`)
+
+func TestCatalogs_Complete(t *testing.T) {
+	require.Contains(t, i18n.Languages(), "en")
+	defaultKeys := i18n.Keys(i18n.DefaultLanguage)
+
+	for _, lang := range i18n.Languages() {
+		assert.Equal(t, defaultKeys, i18n.Keys(lang), "ключи каталога %s отличаются от каталога по умолчанию", lang)
+
+		for _, key := range defaultKeys {
+			text, ok := i18n.Lookup(lang, key)
+			if !ok {
+				continue
+			}
+			assert.NotEmpty(t, text, "пустой перевод %s для языка %s", key, lang)
+
+			defaultText, _ := i18n.Lookup(i18n.DefaultLanguage, key)
+			assert.ElementsMatch(t, placeholderPattern.FindAllString(defaultText, -1), placeholderPattern.FindAllString(text, -1),
+				"подстановки в %s для языка %s отличаются", key, lang)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	params := map[string]any{"minLength": 8}
+
+	assert.Equal(t, "Пароль не соответствует требованиям: пароль короче 8 символов", i18n.Translate("ru", "weak_password.too_short", params))
+	assert.Equal(t, "Password does not meet the requirements: password is shorter than 8 characters", i18n.Translate("en", "weak_password.too_short", params))
+	assert.Equal(t, "ПВЗ не найден", i18n.Translate("de", "pvz_not_found", nil))
+	assert.Equal(t, "unknown_key", i18n.Translate("en", "unknown_key", nil))
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "ru"},
+		{"en", "en"},
+		{"en-US,en;q=0.9", "en"},
+		{"de-DE, en;q=0.8, ru;q=0.9", "ru"},
+		{"ru;q=0.5, EN-gb;q=0.7", "en"},
+		{"de, fr;q=0.9", "ru"},
+		{"en;q=abc", "ru"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, i18n.Negotiate(tt.header))
+		})
+	}
+}
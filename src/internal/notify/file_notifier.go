@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Дописывает сообщения в файл в формате JSON Lines
+type FileNotifier struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+type fileRecord struct {
+	Message
+	SentAt time.Time `json:"sentAt"`
+}
+
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileNotifier{file: file}, nil
+}
+
+func (n *FileNotifier) Send(ctx context.Context, message Message) error {
+	line, err := json.Marshal(fileRecord{Message: message, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err = n.file.Write(append(line, '\n'))
+	return err
+}
+
+func (n *FileNotifier) Close() error {
+	return n.file.Close()
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Пишет сообщения в лог приложения, вместе с содержимым; только для локальной разработки
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, message Message) error {
+	slog.InfoContext(ctx, "уведомление пользователю", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+// Сообщение пользователю; канал доставки (почта, SMS) определяется реализацией Notifier
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+const (
+	NotifierLog  = "log"
+	NotifierFile = "file"
+)
+
+// Выбирает реализацию по названию из конфигурации
+func NewNotifier(kind, filePath string) (Notifier, error) {
+	switch kind {
+	case NotifierLog:
+		return NewLogNotifier(), nil
+	case NotifierFile:
+		return NewFileNotifier(filePath)
+	}
+	return nil, fmt.Errorf("unknown notifier %q", kind)
+}
@@ -0,0 +1,48 @@
+package notify_test
+
+import (
+	"avito-backend/src/internal/notify"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifier_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "notifications.jsonl")
+	notifier, err := notify.NewFileNotifier(path)
+	require.NoError(t, err)
+	defer notifier.Close()
+
+	require.NoError(t, notifier.Send(context.Background(), notify.Message{To: "a@example.com", Subject: "Первое", Body: "текст"}))
+	require.NoError(t, notifier.Send(context.Background(), notify.Message{To: "b@example.com", Subject: "Второе", Body: "текст"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "b@example.com", record["to"])
+	assert.Equal(t, "Второе", record["subject"])
+	assert.NotEmpty(t, record["sentAt"])
+}
+
+func TestNewNotifier(t *testing.T) {
+	notifier, err := notify.NewNotifier(notify.NotifierLog, "")
+	require.NoError(t, err)
+	assert.IsType(t, &notify.LogNotifier{}, notifier)
+
+	notifier, err = notify.NewNotifier(notify.NotifierFile, filepath.Join(t.TempDir(), "out.jsonl"))
+	require.NoError(t, err)
+	assert.IsType(t, &notify.FileNotifier{}, notifier)
+
+	_, err = notify.NewNotifier("smtp", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/logger"
+	"context"
+	"log/slog"
+)
+
+// Пишет события в лог приложения; подходит для локальной разработки
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	ctx = logger.WithPVZID(ctx, event.PVZID.String())
+	slog.InfoContext(ctx, "доменное событие",
+		"event_id", event.EventID, "event_type", event.Type, "payload", string(event.Payload))
+	return nil
+}
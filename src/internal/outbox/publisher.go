@@ -0,0 +1,35 @@
+package outbox
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Доставляет событие во внешнюю систему; ошибка означает, что событие будет отправлено повторно
+type Publisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+const (
+	PublisherLog  = "log"
+	PublisherFile = "file"
+	PublisherHTTP = "http"
+)
+
+// Выбирает реализацию по названию из конфигурации
+func NewPublisher(kind, filePath, httpURL string, httpTimeout time.Duration) (Publisher, error) {
+	switch kind {
+	case PublisherLog:
+		return NewLogPublisher(), nil
+	case PublisherFile:
+		return NewFilePublisher(filePath)
+	case PublisherHTTP:
+		if httpURL == "" {
+			return nil, fmt.Errorf("outbox http publisher requires a URL")
+		}
+		return NewHTTPPublisher(httpURL, httpTimeout), nil
+	}
+	return nil, fmt.Errorf("unknown outbox publisher %q", kind)
+}
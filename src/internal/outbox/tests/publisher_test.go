@@ -0,0 +1,92 @@
+package outbox_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/outbox"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEvent() *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:        7,
+		EventID:   uuid.New(),
+		Type:      models.ProductAdded,
+		PVZID:     uuid.New(),
+		Payload:   json.RawMessage(`{"type":"обувь"}`),
+		CreatedAt: time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestHTTPPublisher_Publish(t *testing.T) {
+	event := newEvent()
+
+	t.Run("Success", func(t *testing.T) {
+		var received map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, event.EventID.String(), r.Header.Get("X-Event-ID"))
+			assert.Equal(t, string(models.ProductAdded), r.Header.Get("X-Event-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		publisher := outbox.NewHTTPPublisher(server.URL, time.Second)
+
+		require.NoError(t, publisher.Publish(context.Background(), event))
+		assert.Equal(t, event.PVZID.String(), received["pvzId"])
+		assert.Equal(t, map[string]any{"type": "обувь"}, received["payload"])
+	})
+
+	t.Run("Server Error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		publisher := outbox.NewHTTPPublisher(server.URL, time.Second)
+
+		assert.Error(t, publisher.Publish(context.Background(), event))
+	})
+}
+
+func TestFilePublisher_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "outbox.jsonl")
+	publisher, err := outbox.NewFilePublisher(path)
+	require.NoError(t, err)
+
+	require.NoError(t, publisher.Publish(context.Background(), newEvent()))
+	require.NoError(t, publisher.Publish(context.Background(), newEvent()))
+	require.NoError(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"type":"ProductAdded"`)
+	assert.Contains(t, lines[0], `"occurredAt":"2025-03-03T09:00:00Z"`)
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := outbox.NewPublisher(outbox.PublisherLog, "", "", time.Second)
+	require.NoError(t, err)
+	assert.IsType(t, &outbox.LogPublisher{}, publisher)
+
+	_, err = outbox.NewPublisher(outbox.PublisherHTTP, "", "", time.Second)
+	assert.Error(t, err)
+
+	_, err = outbox.NewPublisher("kafka", "", "", time.Second)
+	assert.Error(t, err)
+}
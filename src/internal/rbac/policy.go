@@ -0,0 +1,61 @@
+package rbac
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"avito-backend/src/internal/domain/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Формат файла:
+//
+//	roles:
+//	  moderator: [pvz:create, pvz:update]
+//	  analyst: [pvz:read]
+type policyFile struct {
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// Читает права ролей из YAML-файла
+func LoadPolicy(path string) (map[models.Role][]models.Permission, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rbac policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// Неизвестное право считается ошибкой: опечатка в конфигурации иначе молча закрыла бы доступ
+func ParsePolicy(data []byte) (map[models.Role][]models.Permission, error) {
+	var file policyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse rbac policy: %w", err)
+	}
+	if len(file.Roles) == 0 {
+		return nil, fmt.Errorf("rbac policy defines no roles")
+	}
+
+	policy := make(map[models.Role][]models.Permission, len(file.Roles))
+	for roleName, permissionNames := range file.Roles {
+		if !roleNamePattern.MatchString(roleName) {
+			return nil, fmt.Errorf("invalid role name %q", roleName)
+		}
+
+		permissions := make([]models.Permission, 0, len(permissionNames))
+		for _, name := range permissionNames {
+			permission := models.Permission(name)
+			if !permission.IsValid() {
+				return nil, fmt.Errorf("role %q: unknown permission %q", roleName, name)
+			}
+			permissions = append(permissions, permission)
+		}
+		policy[models.Role(roleName)] = permissions
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/rbac"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	t.Run("Успешный разбор", func(t *testing.T) {
+		policy, err := rbac.ParsePolicy([]byte(`
+roles:
+  analyst: [pvz:read, events:read]
+  pvz_manager:
+    - pvz:create
+`))
+		require.NoError(t, err)
+		assert.Equal(t, []models.Permission{models.PermPVZRead, models.PermEventsRead}, policy["analyst"])
+		assert.Equal(t, []models.Permission{models.PermPVZCreate}, policy["pvz_manager"])
+	})
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Неизвестное право", data: "roles:\n  analyst: [pvz:destroy]\n"},
+		{name: "Недопустимое имя роли", data: "roles:\n  Analyst: [pvz:read]\n"},
+		{name: "Нет ролей", data: "roles: {}\n"},
+		{name: "Некорректный YAML", data: "roles: [\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rbac.ParsePolicy([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadPolicy_ShippedConfig(t *testing.T) {
+	policy, err := rbac.LoadPolicy("../../../../config/rbac.yaml")
+	require.NoError(t, err)
+
+	// Встроенные роли должны сохранять права по умолчанию
+	for role, permissions := range models.DefaultRolePermissions() {
+		assert.ElementsMatch(t, permissions, policy[role], "роль %s", role)
+	}
+	assert.Contains(t, policy, models.Role("pvz_manager"))
+	assert.Contains(t, policy, models.Role("auditor"))
+	assert.Contains(t, policy, models.Role("analyst"))
+}
+
+func TestLoadPolicy_MissingFile(t *testing.T) {
+	_, err := rbac.LoadPolicy("no-such-file.yaml")
+	assert.Error(t, err)
+}
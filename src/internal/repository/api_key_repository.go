@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type APIKeyRepositoryInterface interface {
+	Create(key *models.APIKey) error
+	GetByHash(keyHash string) (*models.APIKey, error)
+	List() ([]*models.APIKey, error)
+	Revoke(id uuid.UUID, now time.Time) error
+	TouchLastUsed(id uuid.UUID, now time.Time) error
+}
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+var apiKeyColumns = []string{"id", "name", "prefix", "key_hash", "role", "permissions", "pvz_ids",
+	"expires_at", "last_used_at", "revoked_at", "created_at", "created_by"}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	permissions := make(pq.StringArray, 0, len(key.Permissions))
+	for _, permission := range key.Permissions {
+		permissions = append(permissions, string(permission))
+	}
+	pvzIDs := make(pq.StringArray, 0, len(key.PVZIDs))
+	for _, pvzID := range key.PVZIDs {
+		pvzIDs = append(pvzIDs, pvzID.String())
+	}
+
+	sqlQuery, args, err := psql.Insert("api_keys").
+		Columns("id", "name", "prefix", "key_hash", "role", "permissions", "pvz_ids", "expires_at", "created_at", "created_by").
+		Values(key.ID, key.Name, key.Prefix, key.KeyHash, string(key.Role), permissions, pvzIDs,
+			key.ExpiresAt, key.CreatedAt, key.CreatedBy).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(sqlQuery, args...)
+	return err
+}
+
+func (r *APIKeyRepository) GetByHash(keyHash string) (*models.APIKey, error) {
+	sqlQuery, args, err := psql.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.Eq{"key_hash": keyHash}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAPIKey(r.db.QueryRow(sqlQuery, args...))
+}
+
+func (r *APIKeyRepository) List() ([]*models.APIKey, error) {
+	sqlQuery, args, err := psql.Select(apiKeyColumns...).
+		From("api_keys").
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Ключ не удаляется, чтобы в списке оставалась история. Неизвестный или уже
+// отозванный ключ дает sql.ErrNoRows
+func (r *APIKeyRepository) Revoke(id uuid.UUID, now time.Time) error {
+	sqlQuery, args, err := psql.Update("api_keys").
+		Set("revoked_at", now).
+		Where(sq.Eq{"id": id, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id uuid.UUID, now time.Time) error {
+	sqlQuery, args, err := psql.Update("api_keys").
+		Set("last_used_at", now).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(sqlQuery, args...)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var role string
+	var permissions, pvzIDs pq.StringArray
+	var expiresAt, lastUsedAt, revokedAt nullTime
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &role, &permissions, &pvzIDs,
+		&expiresAt, &lastUsedAt, &revokedAt, &key.CreatedAt, &createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Role = models.Role(role)
+	key.Permissions = make([]models.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		key.Permissions = append(key.Permissions, models.Permission(permission))
+	}
+	key.PVZIDs = make([]uuid.UUID, 0, len(pvzIDs))
+	for _, value := range pvzIDs {
+		pvzID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		key.PVZIDs = append(key.PVZIDs, pvzID)
+	}
+	key.ExpiresAt = expiresAt.ptr()
+	key.LastUsedAt = lastUsedAt.ptr()
+	key.RevokedAt = revokedAt.ptr()
+	if createdBy.Valid {
+		key.CreatedBy = &createdBy.UUID
+	}
+
+	return key, nil
+}
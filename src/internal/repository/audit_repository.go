@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"database/sql"
+)
+
+type AuditRepositoryInterface interface {
+	Record(entry *models.AuditEntry) error
+}
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Record(entry *models.AuditEntry) error {
+	var details interface{}
+	if len(entry.Details) > 0 {
+		details = string(entry.Details)
+	}
+
+	sqlQuery, args, err := psql.Insert("audit_log").
+		Columns("action", "actor_id", "user_id", "email", "ip", "details", "created_at").
+		Values(entry.Action, entry.ActorID, entry.UserID, nullString(entry.Email), nullString(entry.IP), details, entry.CreatedAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRow(sqlQuery, args...).Scan(&entry.ID)
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
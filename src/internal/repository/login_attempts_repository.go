@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type LoginAttemptsRepositoryInterface interface {
+	GetAttempts(scope models.LoginScope, key string) (*models.LoginAttempts, error)
+	UpdateAttempts(scope models.LoginScope, key string, update func(attempts *models.LoginAttempts)) (*models.LoginAttempts, error)
+	DeleteAttempts(scope models.LoginScope, key string) error
+}
+
+type LoginAttemptsRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptsRepository(db *sql.DB) *LoginAttemptsRepository {
+	return &LoginAttemptsRepository{db: db}
+}
+
+var loginAttemptsColumns = []string{"scope", "key", "failed_count", "lockouts", "locked_until", "last_failed_at"}
+
+func (r *LoginAttemptsRepository) GetAttempts(scope models.LoginScope, key string) (*models.LoginAttempts, error) {
+	query := psql.Select(loginAttemptsColumns...).
+		From("login_attempts").
+		Where(sq.Eq{"scope": scope, "key": key})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanLoginAttempts(r.db.QueryRow(sqlQuery, args...))
+}
+
+// Изменяет счетчики под блокировкой строки, чтобы параллельные попытки входа не затирали друг друга
+func (r *LoginAttemptsRepository) UpdateAttempts(scope models.LoginScope, key string, update func(attempts *models.LoginAttempts)) (*models.LoginAttempts, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	insertQuery, args, err := psql.Insert("login_attempts").
+		Columns("scope", "key").
+		Values(scope, key).
+		Suffix("ON CONFLICT (scope, key) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(insertQuery, args...); err != nil {
+		return nil, err
+	}
+
+	selectQuery, args, err := psql.Select(loginAttemptsColumns...).
+		From("login_attempts").
+		Where(sq.Eq{"scope": scope, "key": key}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := scanLoginAttempts(tx.QueryRow(selectQuery, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	update(attempts)
+
+	updateQuery, args, err := psql.Update("login_attempts").
+		Set("failed_count", attempts.FailedCount).
+		Set("lockouts", attempts.Lockouts).
+		Set("locked_until", attempts.LockedUntil).
+		Set("last_failed_at", attempts.LastFailedAt).
+		Where(sq.Eq{"scope": scope, "key": key}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(updateQuery, args...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *LoginAttemptsRepository) DeleteAttempts(scope models.LoginScope, key string) error {
+	sqlQuery, args, err := psql.Delete("login_attempts").
+		Where(sq.Eq{"scope": scope, "key": key}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanLoginAttempts(row *sql.Row) (*models.LoginAttempts, error) {
+	attempts := &models.LoginAttempts{}
+	var lockedUntil, lastFailedAt sql.NullTime
+	err := row.Scan(&attempts.Scope, &attempts.Key, &attempts.FailedCount, &attempts.Lockouts, &lockedUntil, &lastFailedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		attempts.LockedUntil = &lockedUntil.Time
+	}
+	if lastFailedAt.Valid {
+		attempts.LastFailedAt = &lastFailedAt.Time
+	}
+	return attempts, nil
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ключ advisory-блокировки: захват событий выполняется одной репликой за раз, иначе две реплики
+// могли бы одновременно захватить соседние события одного ПВЗ и отправить их не по порядку
+const outboxRelayLockKey int64 = 320032
+
+type OutboxRepositoryInterface interface {
+	// Захватывает до limit событий, откладывая их до leaseUntil. Захват фиксируется до отправки,
+	// поэтому публикация не держит транзакцию. Пустой результат — событий нет или захват выполняет другая реплика
+	ClaimPending(now, leaseUntil time.Time, limit int) ([]*models.OutboxEvent, error)
+	MarkPublished(id int64) error
+	// Откладывает событие до nextAttemptAt; до этого момента следующие события его ПВЗ не выдаются
+	MarkFailed(id int64, reason string, nextAttemptAt time.Time) error
+	// Переводит событие в dead letter: оно больше не отправляется и не задерживает свой ПВЗ
+	MarkDead(id int64, reason string) error
+	// Снимает захват с событий, которые не отправлялись, чтобы они вернулись в очередь без ожидания аренды
+	Release(ids []int64) error
+}
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Выдаются события по возрастанию id, перед которыми в том же ПВЗ нет отложенных или захваченных событий.
+// ПВЗ с неудачным первым событием целиком пропускается и не занимает место в пачке
+func (r *OutboxRepository) ClaimPending(now, leaseUntil time.Time, limit int) ([]*models.OutboxEvent, error) {
+	due := sq.Select("o.id").
+		From("outbox o").
+		Where(sq.Eq{"o.published_at": nil, "o.failed_at": nil}).
+		Where(sq.Or{sq.Eq{"o.next_attempt_at": nil}, sq.LtOrEq{"o.next_attempt_at": now}}).
+		Where("NOT EXISTS (SELECT 1 FROM outbox h WHERE h.pvz_id = o.pvz_id AND h.id < o.id "+
+			"AND h.published_at IS NULL AND h.failed_at IS NULL AND h.next_attempt_at > ?)", now).
+		OrderBy("o.id").
+		Limit(uint64(limit))
+
+	dueSQL, dueArgs, err := due.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := psql.Update("outbox").
+		Set("next_attempt_at", leaseUntil).
+		Where("id IN ("+dueSQL+")", dueArgs...).
+		Suffix("RETURNING id, event_id, event_type, pvz_id, payload, created_at, attempts")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRow("SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockKey).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	rows, err := tx.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	for rows.Next() {
+		event := &models.OutboxEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.EventID, &event.Type, &event.PVZID, &payload, &event.CreatedAt, &event.Attempts); err != nil {
+			return nil, err
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(id int64) error {
+	query := psql.Update("outbox").
+		Set("published_at", time.Now()).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", nil).
+		Set("last_error", nil).
+		Where(sq.Eq{"id": id})
+
+	return r.exec(query)
+}
+
+func (r *OutboxRepository) MarkFailed(id int64, reason string, nextAttemptAt time.Time) error {
+	query := psql.Update("outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", nextAttemptAt).
+		Set("last_error", reason).
+		Where(sq.Eq{"id": id})
+
+	return r.exec(query)
+}
+
+func (r *OutboxRepository) MarkDead(id int64, reason string) error {
+	query := psql.Update("outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", nil).
+		Set("failed_at", time.Now()).
+		Set("last_error", reason).
+		Where(sq.Eq{"id": id})
+
+	return r.exec(query)
+}
+
+func (r *OutboxRepository) Release(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := psql.Update("outbox").
+		Set("next_attempt_at", nil).
+		Where(sq.Eq{"id": ids, "published_at": nil})
+
+	return r.exec(query)
+}
+
+func (r *OutboxRepository) exec(query sq.UpdateBuilder) error {
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(sqlQuery, args...)
+	return err
+}
+
+// Идентификатор последнего записанного события, 0 если событий еще не было
+func (r *OutboxRepository) LatestEventID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM outbox").Scan(&id)
+	return id, err
+}
+
+// События с идентификатором больше afterID по возрастанию, вместе с городом ПВЗ
+func (r *OutboxRepository) ListEventsAfter(afterID int64, filter models.EventFilter, limit int) ([]*models.OutboxEvent, error) {
+	query := psql.Select("o.id", "o.event_id", "o.event_type", "o.pvz_id", "o.payload", "o.created_at", "p.city").
+		From("outbox o").
+		Join("pvz p ON p.id = o.pvz_id").
+		Where(sq.Gt{"o.id": afterID}).
+		OrderBy("o.id").
+		Limit(uint64(limit))
+	if filter.PVZID != nil {
+		query = query.Where(sq.Eq{"o.pvz_id": *filter.PVZID})
+	}
+	if filter.City != nil {
+		query = query.Where(sq.Eq{"p.city": *filter.City})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	for rows.Next() {
+		event := &models.OutboxEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.EventID, &event.Type, &event.PVZID, &payload, &event.CreatedAt, &event.City); err != nil {
+			return nil, err
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+const insertOutboxEventSQL = `INSERT INTO outbox (event_id, event_type, pvz_id, payload, created_at) ` +
+	`SELECT $1, $2, pvz_id, $3::jsonb, $4 FROM receptions WHERE id = $5 FOR UPDATE`
+
+// ПВЗ берется из приемки; строка приемки блокируется, чтобы события одной приемки
+// получали идентификаторы в порядке фиксации транзакций
+func insertOutboxEvent(tx pgx.Tx, eventType models.EventType, receptionID uuid.UUID, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return execAffecting(tx, insertOutboxEventSQL, uuid.New(), eventType, string(data), time.Now(), receptionID)
+}
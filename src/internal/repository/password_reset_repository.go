@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepositoryInterface interface {
+	CreateToken(token *models.PasswordResetToken) error
+	ConsumeToken(tokenHash string, now time.Time) (uuid.UUID, error)
+}
+
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) CreateToken(token *models.PasswordResetToken) error {
+	sqlQuery, args, err := psql.Insert("password_reset_tokens").
+		Columns("token_hash", "user_id", "expires_at", "created_at").
+		Values(token.TokenHash, token.UserID, token.ExpiresAt, token.CreatedAt).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(sqlQuery, args...)
+	return err
+}
+
+// Помечает токен использованным и возвращает владельца. Просроченный, уже использованный
+// или неизвестный токен дает sql.ErrNoRows
+func (r *PasswordResetRepository) ConsumeToken(tokenHash string, now time.Time) (uuid.UUID, error) {
+	sqlQuery, args, err := psql.Update("password_reset_tokens").
+		Set("used_at", now).
+		Where(sq.Eq{"token_hash": tokenHash, "used_at": nil}).
+		Where(sq.Gt{"expires_at": now}).
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var userID uuid.UUID
+	if err := r.db.QueryRow(sqlQuery, args...).Scan(&userID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"avito-backend/src/pkg/database"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Код ошибки PostgreSQL при нарушении уникального индекса
+const uniqueViolation = "23505"
+
+// Пул соединений pgx. Подготовленные запросы кешируются на каждом соединении,
+// поэтому тексты запросов по возможности неизменны, а переменные части передаются параметрами
+type PgxPool interface {
+	database.Querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Выбирает, откуда читать: реплику с допустимым отставанием или primary
+type ReadRouter interface {
+	Reader() database.Querier
+}
+
+// Репозитории на pgx возвращают sql.ErrNoRows, как и остальные, чтобы сервисам было все равно, какой под ними драйвер
+func noRows(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// Выполняет изменение и возвращает sql.ErrNoRows, если не затронуто ни одной строки
+func execAffecting(db database.Querier, sqlQuery string, args ...any) error {
+	tag, err := db.Exec(context.Background(), sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func inTx(pool PgxPool, fn func(tx pgx.Tx) error) error {
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
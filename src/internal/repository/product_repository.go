@@ -1,47 +1,64 @@
 package repository
 
 import (
+	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
-	"database/sql"
+	"context"
+	"errors"
+	"time"
 
-	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-func (r *PVZRepository) CreateProduct(product *models.Product) error {
-	query := psql.Insert("products").
-		Columns("id", "date_time", "type", "reception_id").
-		Values(product.ID, product.DateTime, product.Type, product.ReceptionID)
+const (
+	lockReceptionSQL = `SELECT status FROM receptions WHERE id = $1 FOR UPDATE`
+	createProductSQL = `INSERT INTO products (id, date_time, type, reception_id) VALUES ($1, $2, $3, $4)`
+	lastProductSQL   = `SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 AND deleted_at IS NULL ORDER BY date_time DESC LIMIT 1`
+	countProductsSQL = `SELECT COUNT(*) FROM products WHERE reception_id = $1 AND deleted_at IS NULL`
+	deleteProductSQL = `UPDATE products SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL RETURNING date_time, type, reception_id`
+)
 
-	sqlQuery, args, err := query.ToSql()
-	if err != nil {
-		return err
-	}
+// Строка приемки блокируется до конца транзакции, поэтому параллельные добавления не превысят
+// maxProducts и не попадут в приемку, которую в это время закрывают. maxProducts = 0 — без лимита
+func (r *PVZRepository) CreateProduct(product *models.Product, maxProducts int) error {
+	return inTx(r.db, func(tx pgx.Tx) error {
+		var status models.ReceptionStatus
+		if err := tx.QueryRow(context.Background(), lockReceptionSQL, product.ReceptionID).Scan(&status); err != nil {
+			return noRows(err)
+		}
+		if status == models.Closed {
+			return apperrors.ErrReceptionClosed
+		}
+
+		if maxProducts > 0 {
+			var count int
+			if err := tx.QueryRow(context.Background(), countProductsSQL, product.ReceptionID).Scan(&count); err != nil {
+				return err
+			}
+			if count >= maxProducts {
+				return apperrors.ErrReceptionProductLimit
+			}
+		}
 
-	_, err = r.db.Exec(sqlQuery, args...)
-	return err
+		_, err := tx.Exec(context.Background(), createProductSQL,
+			product.ID, product.DateTime, product.Type, product.ReceptionID)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvent(tx, models.ProductAdded, product.ReceptionID, product)
+	})
 }
 
 func (r *PVZRepository) GetLastProductInReception(receptionID uuid.UUID) (*models.Product, error) {
-	query := psql.Select("id", "date_time", "type", "reception_id").
-		From("products").
-		Where(sq.Eq{"reception_id": receptionID}).
-		OrderBy("date_time DESC").
-		Limit(1)
-
-	sqlQuery, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
-
 	product := &models.Product{}
-	err = r.db.QueryRow(sqlQuery, args...).Scan(
+	err := r.reader().QueryRow(context.Background(), lastProductSQL, receptionID).Scan(
 		&product.ID,
 		&product.DateTime,
 		&product.Type,
 		&product.ReceptionID,
 	)
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
@@ -51,27 +68,33 @@ func (r *PVZRepository) GetLastProductInReception(receptionID uuid.UUID) (*model
 	return product, nil
 }
 
-func (r *PVZRepository) DeleteProduct(productID uuid.UUID) error {
-	query := psql.Delete("products").
-		Where(sq.Eq{"id": productID})
-
-	sqlQuery, args, err := query.ToSql()
-	if err != nil {
-		return err
+func (r *PVZRepository) CountProductsInReception(receptionID uuid.UUID) (int, error) {
+	var count int
+	if err := r.reader().QueryRow(context.Background(), countProductsSQL, receptionID).Scan(&count); err != nil {
+		return 0, err
 	}
 
-	result, err := r.db.Exec(sqlQuery, args...)
-	if err != nil {
-		return err
-	}
+	return count, nil
+}
 
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if affected == 0 {
-		return sql.ErrNoRows
+// Товар не удаляется физически, а помечается удаленным, чтобы сохранить след для разборов
+func (r *PVZRepository) DeleteProduct(productID uuid.UUID, deletedBy uuid.UUID) error {
+	var actor any
+	if deletedBy != uuid.Nil {
+		actor = deletedBy
 	}
 
-	return nil
+	deletedAt := time.Now()
+	return inTx(r.db, func(tx pgx.Tx) error {
+		product := &models.Product{ID: productID, DeletedAt: &deletedAt}
+		if deletedBy != uuid.Nil {
+			product.DeletedBy = &deletedBy
+		}
+		err := tx.QueryRow(context.Background(), deleteProductSQL, deletedAt, actor, productID).
+			Scan(&product.DateTime, &product.Type, &product.ReceptionID)
+		if err != nil {
+			return noRows(err)
+		}
+		return insertOutboxEvent(tx, models.ProductRemoved, product.ReceptionID, product)
+	})
 }
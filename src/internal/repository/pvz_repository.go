@@ -2,99 +2,237 @@ package repository
 
 import (
 	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/database"
+	"avito-backend/src/pkg/geo"
+	"context"
 	"database/sql"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type PVZRepositoryInterface interface {
 	Create(pvz *models.PVZ) error
 	GetByID(id uuid.UUID) (*models.PVZ, error)
+	Update(pvz *models.PVZ) error
 	CreateReception(reception *models.Reception) error
 	GetActiveReceptionByPVZID(pvzID uuid.UUID) (*models.Reception, error)
-	CreateProduct(product *models.Product) error
+	CreateProduct(product *models.Product, maxProducts int) error
 	GetLastProductInReception(receptionID uuid.UUID) (*models.Product, error)
-	DeleteProduct(productID uuid.UUID) error
+	CountProductsInReception(receptionID uuid.UUID) (int, error)
+	DeleteProduct(productID uuid.UUID, deletedBy uuid.UUID) error
 	UpdateReception(reception *models.Reception) error
-	GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error)
+	GetStaleReceptions(idleSince time.Time) ([]*models.Reception, error)
+	CloseIdleReception(reception *models.Reception, idleSince time.Time) error
+	GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error)
+	GetInBoundingBox(box geo.BoundingBox) ([]*models.PVZ, error)
+	GetSchedules(pvzIDs []uuid.UUID) (map[uuid.UUID]*models.PVZSchedule, error)
+	SaveSchedule(schedule *models.PVZSchedule) error
+	SaveCalendarException(pvzID uuid.UUID, exception models.CalendarException) error
+	DeleteCalendarException(pvzID uuid.UUID, date string) error
+	GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error)
+	SaveSettings(settings *models.PVZSettings) error
+	LockPVZ(pvzID uuid.UUID, fn func(repo PVZRepositoryInterface) error) error
 }
 
+type nullTime struct {
+	sql.NullTime
+}
+
+func (t nullTime) ptr() *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+type nullFloat struct {
+	sql.NullFloat64
+}
+
+func (f nullFloat) ptr() *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
+
+var pvzColumns = []string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}
+
+func withAlias(alias string, columns []string) []string {
+	result := make([]string, len(columns))
+	for i, column := range columns {
+		result[i] = alias + "." + column
+	}
+	return result
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPVZ(row rowScanner) (*models.PVZ, error) {
+	pvz := &models.PVZ{}
+	var updatedAt nullTime
+	var address sql.NullString
+	var latitude, longitude nullFloat
+
+	err := row.Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City, &pvz.Status, &updatedAt, &address, &latitude, &longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	pvz.UpdatedAt = updatedAt.ptr()
+	pvz.Address = address.String
+	pvz.Latitude = latitude.ptr()
+	pvz.Longitude = longitude.ptr()
+	return pvz, nil
+}
+
+func nullableString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+const (
+	createPVZSQL = `INSERT INTO pvz (id, registration_date, city, status, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	getPVZSQL    = `SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE id = $1`
+	updatePVZSQL = `UPDATE pvz SET city = $1, status = $2, address = $3, latitude = $4, longitude = $5, updated_at = $6 WHERE id = $7`
+	lockPVZSQL   = `SELECT id FROM pvz WHERE id = $1 FOR UPDATE`
+
+	pvzInBoundingBoxSQL = `SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz ` +
+		`WHERE latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4 AND status <> $5`
+)
+
 type PVZRepository struct {
-	db *sql.DB
+	db       PgxPool
+	replicas ReadRouter
 }
 
-func NewPVZRepository(db *sql.DB) *PVZRepository {
+func NewPVZRepository(db PgxPool) *PVZRepository {
 	return &PVZRepository{db: db}
 }
 
-func (r *PVZRepository) Create(pvz *models.PVZ) error {
-	query := psql.Insert("pvz").
-		Columns("id", "registration_date", "city").
-		Values(pvz.ID, pvz.RegistrationDate, pvz.City)
+// Копия репозитория, которая читает через router; изменения и транзакции по-прежнему идут в primary
+func (r *PVZRepository) WithReplicas(router ReadRouter) *PVZRepository {
+	return &PVZRepository{db: r.db, replicas: router}
+}
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return err
+// Источник для чтения выбирается один раз на вызов метода, чтобы связанные запросы видели одно состояние базы
+func (r *PVZRepository) reader() database.Querier {
+	if r.replicas == nil {
+		return r.db
 	}
+	return r.replicas.Reader()
+}
 
-	_, err = r.db.Exec(sql, args...)
+func (r *PVZRepository) Create(pvz *models.PVZ) error {
+	_, err := r.db.Exec(context.Background(), createPVZSQL,
+		pvz.ID, pvz.RegistrationDate, pvz.City, pvz.Status, nullableString(pvz.Address), pvz.Latitude, pvz.Longitude)
 	return err
 }
 
+// Выполняет fn в транзакции, в которой строка ПВЗ заблокирована до фиксации. Репозиторий,
+// переданный в fn, читает и пишет в этой транзакции; вложенные транзакции становятся точками
+// сохранения. Если ПВЗ нет, возвращает sql.ErrNoRows
+func (r *PVZRepository) LockPVZ(pvzID uuid.UUID, fn func(repo PVZRepositoryInterface) error) error {
+	return inTx(r.db, func(tx pgx.Tx) error {
+		var id uuid.UUID
+		if err := tx.QueryRow(context.Background(), lockPVZSQL, pvzID).Scan(&id); err != nil {
+			return noRows(err)
+		}
+		return fn(&PVZRepository{db: tx})
+	})
+}
+
 func (r *PVZRepository) GetByID(id uuid.UUID) (*models.PVZ, error) {
-	query := psql.Select("id", "registration_date", "city").
-		From("pvz").
-		Where(sq.Eq{"id": id})
+	pvz, err := scanPVZ(r.reader().QueryRow(context.Background(), getPVZSQL, id))
+	return pvz, noRows(err)
+}
+
+func (r *PVZRepository) Update(pvz *models.PVZ) error {
+	now := time.Now()
 
-	sql, args, err := query.ToSql()
+	err := execAffecting(r.db, updatePVZSQL,
+		pvz.City, pvz.Status, nullableString(pvz.Address), pvz.Latitude, pvz.Longitude, now, pvz.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	pvz := &models.PVZ{}
-	err = r.db.QueryRow(sql, args...).Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City)
+	pvz.UpdatedAt = &now
+	return nil
+}
+
+// Грубый отбор ПВЗ с координатами внутри прямоугольника, архивные не возвращаются
+func (r *PVZRepository) GetInBoundingBox(box geo.BoundingBox) ([]*models.PVZ, error) {
+	rows, err := r.reader().Query(context.Background(), pvzInBoundingBoxSQL,
+		box.MinLat, box.MaxLat, box.MinLon, box.MaxLon, models.PVZArchived)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return pvz, nil
+	pvzs := make([]*models.PVZ, 0)
+	for rows.Next() {
+		pvz, err := scanPVZ(rows)
+		if err != nil {
+			return nil, err
+		}
+		pvzs = append(pvzs, pvz)
+	}
+
+	return pvzs, rows.Err()
 }
 
-func (r *PVZRepository) GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error) {
-	query := psql.Select("p.id", "p.registration_date", "p.city").
+// ПВЗ страницы, их приемки и товары загружаются тремя запросами, а не отдельным запросом на каждый ПВЗ и приемку
+func (r *PVZRepository) GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error) {
+	ctx := context.Background()
+	db := r.reader()
+	startDate, endDate := filter.StartDate, filter.EndDate
+	byDate := !startDate.IsZero() && !endDate.IsZero()
+
+	query := psql.Select(withAlias("p", pvzColumns)...).
 		From("pvz p").
 		LeftJoin("receptions r ON p.id = r.pvz_id")
 
-	if !startDate.IsZero() && !endDate.IsZero() {
-		query = query.Where(sq.And{
-			sq.GtOrEq{"r.date_time": startDate},
-			sq.LtOrEq{"r.date_time": endDate},
-		})
+	if byDate {
+		query = query.Where("r.date_time BETWEEN ? AND ?", startDate, endDate)
+	}
+
+	if !filter.IncludeArchived {
+		query = query.Where(sq.NotEq{"p.status": models.PVZArchived})
 	}
 
-	query = query.GroupBy("p.id", "p.registration_date", "p.city").
+	if len(filter.PVZIDs) > 0 {
+		query = query.Where("p.id = ANY(?)", filter.PVZIDs)
+	}
+
+	// Лимит и смещение передаются параметрами, чтобы все страницы использовали один подготовленный запрос
+	query = query.GroupBy("p.id").
 		OrderBy("p.registration_date DESC").
-		Offset(uint64(offset)).
-		Limit(uint64(limit))
+		Suffix("LIMIT ? OFFSET ?", filter.Limit, filter.Offset)
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := r.db.Query(sqlQuery, args...)
+	rows, err := db.Query(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var pvzs []*models.PVZWithReceptions
+	pvzs := make([]*models.PVZWithReceptions, 0)
+	byPVZ := make(map[uuid.UUID]*models.PVZWithReceptions)
+	pvzIDs := make([]uuid.UUID, 0)
 	for rows.Next() {
-		pvz := &models.PVZ{}
-		err = rows.Scan(&pvz.ID, &pvz.RegistrationDate, &pvz.City)
+		pvz, err := scanPVZ(rows)
 		if err != nil {
+			rows.Close()
 			return nil, err
 		}
 
@@ -102,79 +240,122 @@ func (r *PVZRepository) GetPVZsWithReceptions(startDate, endDate time.Time, offs
 			PVZ:        pvz,
 			Receptions: make([]models.ReceptionWithProducts, 0),
 		}
+		pvzs = append(pvzs, pvzWithReceptions)
+		byPVZ[pvz.ID] = pvzWithReceptions
+		pvzIDs = append(pvzIDs, pvz.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(pvzIDs) == 0 {
+		return pvzs, nil
+	}
 
-		receptionsQuery := psql.Select("r.id", "r.date_time", "r.pvz_id", "r.status").
-			From("receptions r").
-			Where(sq.Eq{"r.pvz_id": pvz.ID})
+	receptions, err := loadReceptions(ctx, db, pvzIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(receptions) == 0 {
+		return pvzs, nil
+	}
 
-		if !startDate.IsZero() && !endDate.IsZero() {
-			receptionsQuery = receptionsQuery.Where(sq.And{
-				sq.GtOrEq{"r.date_time": startDate},
-				sq.LtOrEq{"r.date_time": endDate},
-			})
-		}
+	receptionIDs := make([]uuid.UUID, 0, len(receptions))
+	for _, reception := range receptions {
+		receptionIDs = append(receptionIDs, reception.ID)
+	}
+	products, err := loadProducts(ctx, db, receptionIDs, filter.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
 
-		sqlQuery, args, err = receptionsQuery.ToSql()
-		if err != nil {
-			return nil, err
+	for _, reception := range receptions {
+		receptionProducts := products[reception.ID]
+		if receptionProducts == nil {
+			receptionProducts = make([]models.Product, 0)
 		}
+		pvz := byPVZ[reception.PVZID]
+		pvz.Receptions = append(pvz.Receptions, models.ReceptionWithProducts{
+			Reception: reception,
+			Products:  receptionProducts,
+		})
+	}
 
-		receptionRows, err := r.db.Query(sqlQuery, args...)
+	return pvzs, nil
+}
+
+func loadReceptions(ctx context.Context, db database.Querier, pvzIDs []uuid.UUID, filter models.PVZFilter) ([]*models.Reception, error) {
+	query := psql.Select("r.id", "r.date_time", "r.pvz_id", "r.status", "r.closed_at", "r.closed_by_system").
+		From("receptions r").
+		Where("r.pvz_id = ANY(?)", pvzIDs).
+		OrderBy("r.date_time")
+
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() {
+		query = query.Where("r.date_time BETWEEN ? AND ?", filter.StartDate, filter.EndDate)
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receptions := make([]*models.Reception, 0)
+	for rows.Next() {
+		reception := &models.Reception{}
+		var closedAt nullTime
+		err := rows.Scan(&reception.ID, &reception.DateTime, &reception.PVZID, &reception.Status, &closedAt, &reception.ClosedBySystem)
 		if err != nil {
 			return nil, err
 		}
+		reception.ClosedAt = closedAt.ptr()
+		receptions = append(receptions, reception)
+	}
 
-		for receptionRows.Next() {
-			reception := &models.Reception{}
-			err = receptionRows.Scan(&reception.ID, &reception.DateTime, &reception.PVZID, &reception.Status)
-			if err != nil {
-				receptionRows.Close()
-				return nil, err
-			}
-
-			receptionWithProducts := models.ReceptionWithProducts{
-				Reception: reception,
-				Products:  make([]models.Product, 0),
-			}
-
-			productsQuery := psql.Select("p.id", "p.date_time", "p.type", "p.reception_id").
-				From("products p").
-				Where(sq.Eq{"p.reception_id": reception.ID})
-
-			sqlQuery, args, err = productsQuery.ToSql()
-			if err != nil {
-				receptionRows.Close()
-				return nil, err
-			}
-
-			productRows, err := r.db.Query(sqlQuery, args...)
-			if err != nil {
-				receptionRows.Close()
-				return nil, err
-			}
-
-			for productRows.Next() {
-				product := models.Product{}
-				err = productRows.Scan(&product.ID, &product.DateTime, &product.Type, &product.ReceptionID)
-				if err != nil {
-					productRows.Close()
-					receptionRows.Close()
-					return nil, err
-				}
-				receptionWithProducts.Products = append(receptionWithProducts.Products, product)
-			}
-			productRows.Close()
-
-			pvzWithReceptions.Receptions = append(pvzWithReceptions.Receptions, receptionWithProducts)
-		}
-		receptionRows.Close()
+	return receptions, rows.Err()
+}
 
-		pvzs = append(pvzs, pvzWithReceptions)
+func loadProducts(ctx context.Context, db database.Querier, receptionIDs []uuid.UUID, includeDeleted bool) (map[uuid.UUID][]models.Product, error) {
+	query := psql.Select("p.id", "p.date_time", "p.type", "p.reception_id", "p.deleted_at", "p.deleted_by").
+		From("products p").
+		Where("p.reception_id = ANY(?)", receptionIDs).
+		OrderBy("p.date_time")
+
+	if !includeDeleted {
+		query = query.Where(sq.Eq{"p.deleted_at": nil})
 	}
 
-	if pvzs == nil {
-		pvzs = make([]*models.PVZWithReceptions, 0)
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
 	}
 
-	return pvzs, nil
+	rows, err := db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[uuid.UUID][]models.Product)
+	for rows.Next() {
+		product := models.Product{}
+		var deletedAt nullTime
+		var deletedBy uuid.NullUUID
+		err := rows.Scan(&product.ID, &product.DateTime, &product.Type, &product.ReceptionID, &deletedAt, &deletedBy)
+		if err != nil {
+			return nil, err
+		}
+		product.DeletedAt = deletedAt.ptr()
+		if deletedBy.Valid {
+			product.DeletedBy = &deletedBy.UUID
+		}
+		products[product.ReceptionID] = append(products[product.ReceptionID], product)
+	}
+
+	return products, rows.Err()
 }
@@ -2,47 +2,46 @@ package repository
 
 import (
 	"avito-backend/src/internal/domain/models"
-	"database/sql"
+	"context"
+	"errors"
+	"time"
 
-	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-func (r *PVZRepository) CreateReception(reception *models.Reception) error {
-	query := psql.Insert("receptions").
-		Columns("id", "date_time", "pvz_id", "status").
-		Values(reception.ID, reception.DateTime, reception.PVZID, reception.Status)
+const (
+	createReceptionSQL    = `INSERT INTO receptions (id, date_time, pvz_id, status) VALUES ($1, $2, $3, $4)`
+	activeReceptionSQL    = `SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE r.pvz_id = $1 AND r.status = $2`
+	updateReceptionSQL    = `UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4`
+	closeIdleReceptionSQL = `UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4 AND status = $5 ` +
+		`AND NOT EXISTS (SELECT 1 FROM products p WHERE p.reception_id = receptions.id AND (p.date_time >= $6 OR p.deleted_at >= $6))`
 
-	sqlQuery, args, err := query.ToSql()
-	if err != nil {
-		return err
-	}
+	staleReceptionsSQL = `SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r ` +
+		`LEFT JOIN products p ON p.reception_id = r.id WHERE r.status = $1 GROUP BY r.id ` +
+		`HAVING GREATEST(r.date_time, MAX(p.date_time), MAX(p.deleted_at)) < $2 ORDER BY r.date_time`
+)
 
-	_, err = r.db.Exec(sqlQuery, args...)
-	return err
+func (r *PVZRepository) CreateReception(reception *models.Reception) error {
+	return inTx(r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), createReceptionSQL,
+			reception.ID, reception.DateTime, reception.PVZID, reception.Status)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvent(tx, models.ReceptionCreated, reception.ID, reception)
+	})
 }
 
 func (r *PVZRepository) GetActiveReceptionByPVZID(pvzID uuid.UUID) (*models.Reception, error) {
-	query := psql.Select("r.id", "r.date_time", "r.pvz_id", "r.status").
-		From("receptions r").
-		Where(sq.And{
-			sq.Eq{"r.pvz_id": pvzID},
-			sq.Eq{"r.status": models.InProgress},
-		})
-
-	sqlQuery, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
-
 	reception := &models.Reception{}
-	err = r.db.QueryRow(sqlQuery, args...).Scan(
+	err := r.reader().QueryRow(context.Background(), activeReceptionSQL, pvzID, models.InProgress).Scan(
 		&reception.ID,
 		&reception.DateTime,
 		&reception.PVZID,
 		&reception.Status,
 	)
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
@@ -53,27 +52,50 @@ func (r *PVZRepository) GetActiveReceptionByPVZID(pvzID uuid.UUID) (*models.Rece
 }
 
 func (r *PVZRepository) UpdateReception(reception *models.Reception) error {
-	query := psql.Update("receptions").
-		Set("status", reception.Status).
-		Where(sq.Eq{"id": reception.ID})
+	return inTx(r.db, func(tx pgx.Tx) error {
+		err := execAffecting(tx, updateReceptionSQL,
+			reception.Status, reception.ClosedAt, reception.ClosedBySystem, reception.ID)
+		if err != nil {
+			return err
+		}
+		if reception.Status != models.Closed {
+			return nil
+		}
+		return insertOutboxEvent(tx, models.ReceptionClosed, reception.ID, reception)
+	})
+}
 
-	sqlQuery, args, err := query.ToSql()
+// Приемки в статусе in_progress без активности (создание, добавление или удаление товара) с момента idleSince
+func (r *PVZRepository) GetStaleReceptions(idleSince time.Time) ([]*models.Reception, error) {
+	rows, err := r.reader().Query(context.Background(), staleReceptionsSQL, models.InProgress, idleSince)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	result, err := r.db.Exec(sqlQuery, args...)
-	if err != nil {
-		return err
+	receptions := make([]*models.Reception, 0)
+	for rows.Next() {
+		reception := &models.Reception{}
+		if err := rows.Scan(&reception.ID, &reception.DateTime, &reception.PVZID, &reception.Status); err != nil {
+			return nil, err
+		}
+		receptions = append(receptions, reception)
 	}
 
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if affected == 0 {
-		return sql.ErrNoRows
-	}
+	return receptions, rows.Err()
+}
 
-	return nil
+// Закрывает приемку, только если она все еще открыта и по ней не было активности после idleSince
+func (r *PVZRepository) CloseIdleReception(reception *models.Reception, idleSince time.Time) error {
+	return inTx(r.db, func(tx pgx.Tx) error {
+		err := execAffecting(tx, closeIdleReceptionSQL,
+			reception.Status, reception.ClosedAt, reception.ClosedBySystem, reception.ID, models.InProgress, idleSince)
+		if err != nil {
+			return err
+		}
+		if reception.Status != models.Closed {
+			return nil
+		}
+		return insertOutboxEvent(tx, models.ReceptionClosed, reception.ID, reception)
+	})
 }
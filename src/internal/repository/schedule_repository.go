@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/database"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Графики загружаются пачкой по всем ПВЗ через = ANY, чтобы текст запроса не зависел от их числа
+const (
+	scheduleSettingsSQL   = `SELECT pvz_id, timezone, override_until FROM pvz_schedules WHERE pvz_id = ANY($1)`
+	workingHoursSQL       = `SELECT pvz_id, weekday, opens_minute, closes_minute FROM pvz_working_hours WHERE pvz_id = ANY($1) ORDER BY pvz_id, weekday`
+	calendarExceptionsSQL = `SELECT pvz_id, date, closed, opens_minute, closes_minute, comment FROM pvz_calendar_exceptions ` +
+		`WHERE pvz_id = ANY($1) ORDER BY pvz_id, date`
+
+	upsertScheduleSQL = `INSERT INTO pvz_schedules (pvz_id, timezone, override_until) VALUES ($1, $2, $3) ` +
+		`ON CONFLICT (pvz_id) DO UPDATE SET timezone = EXCLUDED.timezone, override_until = EXCLUDED.override_until`
+	deleteWorkingHoursSQL      = `DELETE FROM pvz_working_hours WHERE pvz_id = $1`
+	upsertCalendarExceptionSQL = `INSERT INTO pvz_calendar_exceptions (pvz_id, date, closed, opens_minute, closes_minute, comment) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (pvz_id, date) DO UPDATE SET closed = EXCLUDED.closed, ` +
+		`opens_minute = EXCLUDED.opens_minute, closes_minute = EXCLUDED.closes_minute, comment = EXCLUDED.comment`
+	deleteCalendarExceptionSQL = `DELETE FROM pvz_calendar_exceptions WHERE pvz_id = $1 AND date = $2`
+)
+
+func nullableClock(clock *models.ClockTime) any {
+	if clock == nil {
+		return nil
+	}
+	return int(*clock)
+}
+
+func clockPtr(value sql.NullInt32) *models.ClockTime {
+	if !value.Valid {
+		return nil
+	}
+	clock := models.ClockTime(value.Int32)
+	return &clock
+}
+
+// Возвращает графики работы для набора ПВЗ, для ПВЗ без графика — график по умолчанию
+func (r *PVZRepository) GetSchedules(pvzIDs []uuid.UUID) (map[uuid.UUID]*models.PVZSchedule, error) {
+	schedules := make(map[uuid.UUID]*models.PVZSchedule, len(pvzIDs))
+	if len(pvzIDs) == 0 {
+		return schedules, nil
+	}
+	for _, id := range pvzIDs {
+		schedules[id] = models.DefaultSchedule(id)
+	}
+
+	db := r.reader()
+	if err := loadScheduleSettings(db, pvzIDs, schedules); err != nil {
+		return nil, err
+	}
+	if err := loadWorkingHours(db, pvzIDs, schedules); err != nil {
+		return nil, err
+	}
+	if err := loadCalendarExceptions(db, pvzIDs, schedules); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func loadScheduleSettings(db database.Querier, pvzIDs []uuid.UUID, schedules map[uuid.UUID]*models.PVZSchedule) error {
+	rows, err := db.Query(context.Background(), scheduleSettingsSQL, pvzIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID uuid.UUID
+		var timezone string
+		var overrideUntil nullTime
+		if err := rows.Scan(&pvzID, &timezone, &overrideUntil); err != nil {
+			return err
+		}
+
+		if schedule, ok := schedules[pvzID]; ok {
+			schedule.Timezone = timezone
+			schedule.OverrideUntil = overrideUntil.ptr()
+		}
+	}
+
+	return rows.Err()
+}
+
+func loadWorkingHours(db database.Querier, pvzIDs []uuid.UUID, schedules map[uuid.UUID]*models.PVZSchedule) error {
+	rows, err := db.Query(context.Background(), workingHoursSQL, pvzIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID uuid.UUID
+		var hours models.WorkingHours
+		if err := rows.Scan(&pvzID, &hours.Weekday, &hours.Opens, &hours.Closes); err != nil {
+			return err
+		}
+
+		if schedule, ok := schedules[pvzID]; ok {
+			schedule.WorkingHours = append(schedule.WorkingHours, hours)
+		}
+	}
+
+	return rows.Err()
+}
+
+func loadCalendarExceptions(db database.Querier, pvzIDs []uuid.UUID, schedules map[uuid.UUID]*models.PVZSchedule) error {
+	rows, err := db.Query(context.Background(), calendarExceptionsSQL, pvzIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pvzID uuid.UUID
+		var date time.Time
+		var opens, closes sql.NullInt32
+		var comment sql.NullString
+		exception := models.CalendarException{}
+		if err := rows.Scan(&pvzID, &date, &exception.Closed, &opens, &closes, &comment); err != nil {
+			return err
+		}
+
+		exception.Date = date.Format(models.DateLayout)
+		exception.Opens = clockPtr(opens)
+		exception.Closes = clockPtr(closes)
+		exception.Comment = comment.String
+
+		if schedule, ok := schedules[pvzID]; ok {
+			schedule.Exceptions = append(schedule.Exceptions, exception)
+		}
+	}
+
+	return rows.Err()
+}
+
+// Сохраняет часовой пояс, override и недельный график; исключения календаря не затрагиваются
+func (r *PVZRepository) SaveSchedule(schedule *models.PVZSchedule) error {
+	ctx := context.Background()
+	return inTx(r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, upsertScheduleSQL, schedule.PVZID, schedule.Timezone, schedule.OverrideUntil); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, deleteWorkingHoursSQL, schedule.PVZID); err != nil {
+			return err
+		}
+		if len(schedule.WorkingHours) == 0 {
+			return nil
+		}
+
+		// Число строк меняется, поэтому запрос собирается squirrel; вариантов не больше семи
+		insert := psql.Insert("pvz_working_hours").
+			Columns("pvz_id", "weekday", "opens_minute", "closes_minute")
+		for _, hours := range schedule.WorkingHours {
+			insert = insert.Values(schedule.PVZID, int(hours.Weekday), int(hours.Opens), int(hours.Closes))
+		}
+
+		sqlQuery, args, err := insert.ToSql()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, sqlQuery, args...)
+		return err
+	})
+}
+
+func (r *PVZRepository) SaveCalendarException(pvzID uuid.UUID, exception models.CalendarException) error {
+	_, err := r.db.Exec(context.Background(), upsertCalendarExceptionSQL,
+		pvzID, exception.Date, exception.Closed, nullableClock(exception.Opens), nullableClock(exception.Closes), nullableString(exception.Comment))
+	return err
+}
+
+func (r *PVZRepository) DeleteCalendarException(pvzID uuid.UUID, date string) error {
+	return execAffecting(r.db, deleteCalendarExceptionSQL, pvzID, date)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	getSettingsSQL  = `SELECT pvz_id, max_products_per_reception, allowed_product_types, allow_empty_close, updated_at FROM pvz_settings WHERE pvz_id = $1`
+	saveSettingsSQL = `INSERT INTO pvz_settings (pvz_id, max_products_per_reception, allowed_product_types, allow_empty_close, updated_at) ` +
+		`VALUES ($1, $2, $3, $4, $5) ON CONFLICT (pvz_id) DO UPDATE SET max_products_per_reception = EXCLUDED.max_products_per_reception, ` +
+		`allowed_product_types = EXCLUDED.allowed_product_types, allow_empty_close = EXCLUDED.allow_empty_close, ` +
+		`updated_at = EXCLUDED.updated_at`
+)
+
+// Возвращает sql.ErrNoRows, если для ПВЗ настройки не заданы и действуют глобальные значения
+func (r *PVZRepository) GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	settings := &models.PVZSettings{}
+	var allowedTypes []string
+	var updatedAt time.Time
+	err := r.reader().QueryRow(context.Background(), getSettingsSQL, pvzID).Scan(
+		&settings.PVZID,
+		&settings.MaxProductsPerReception,
+		&allowedTypes,
+		&settings.AllowEmptyClose,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, noRows(err)
+	}
+
+	settings.AllowedProductTypes = make([]models.ProductType, 0, len(allowedTypes))
+	for _, productType := range allowedTypes {
+		settings.AllowedProductTypes = append(settings.AllowedProductTypes, models.ProductType(productType))
+	}
+	settings.UpdatedAt = &updatedAt
+
+	return settings, nil
+}
+
+func (r *PVZRepository) SaveSettings(settings *models.PVZSettings) error {
+	now := time.Now()
+
+	allowedTypes := make([]string, 0, len(settings.AllowedProductTypes))
+	for _, productType := range settings.AllowedProductTypes {
+		allowedTypes = append(allowedTypes, string(productType))
+	}
+
+	_, err := r.db.Exec(context.Background(), saveSettingsSQL,
+		settings.PVZID, settings.MaxProductsPerReception, allowedTypes, settings.AllowEmptyClose, now)
+	if err != nil {
+		return err
+	}
+
+	settings.UpdatedAt = &now
+	return nil
+}
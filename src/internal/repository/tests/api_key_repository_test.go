@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepository_GetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAPIKeyRepository(db)
+	keyID := uuid.New()
+	pvzID := uuid.New()
+	createdAt := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, prefix, key_hash, role, permissions, pvz_ids, expires_at, last_used_at, revoked_at, created_at, created_by FROM api_keys WHERE key_hash = $1`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "key_hash", "role", "permissions", "pvz_ids",
+			"expires_at", "last_used_at", "revoked_at", "created_at", "created_by"}).
+			AddRow(keyID, "etl", "pvzk_12345678", "hash", "employee", "{pvz:read}", "{"+pvzID.String()+"}",
+				nil, nil, nil, createdAt, nil))
+
+	key, err := repo.GetByHash("hash")
+	require.NoError(t, err)
+	assert.Equal(t, keyID, key.ID)
+	assert.Equal(t, models.EmployeeRole, key.Role)
+	assert.Equal(t, []models.Permission{models.PermPVZRead}, key.Permissions)
+	assert.Equal(t, []uuid.UUID{pvzID}, key.PVZIDs)
+	assert.Nil(t, key.ExpiresAt)
+	assert.Nil(t, key.CreatedBy)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_Revoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAPIKeyRepository(db)
+	keyID := uuid.New()
+	now := time.Now()
+	query := regexp.QuoteMeta(`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`)
+
+	mock.ExpectExec(query).WithArgs(now, keyID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(now, keyID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.Revoke(keyID, now))
+	assert.Equal(t, sql.ErrNoRows, repo.Revoke(keyID, now))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
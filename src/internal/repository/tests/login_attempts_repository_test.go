@@ -0,0 +1,117 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var loginAttemptsColumns = []string{"scope", "key", "failed_count", "lockouts", "locked_until", "last_failed_at"}
+
+func TestLoginAttemptsRepository_GetAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLoginAttemptsRepository(db)
+	lockedUntil := time.Now().Add(time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT scope, key, failed_count, lockouts, locked_until, last_failed_at FROM login_attempts WHERE key = $1 AND scope = $2`)).
+		WithArgs("user@example.com", models.LoginScopeEmail).
+		WillReturnRows(sqlmock.NewRows(loginAttemptsColumns).
+			AddRow(models.LoginScopeEmail, "user@example.com", 0, 1, lockedUntil, nil))
+
+	attempts, err := repo.GetAttempts(models.LoginScopeEmail, "user@example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Lockouts)
+	require.NotNil(t, attempts.LockedUntil)
+	assert.True(t, attempts.LockedUntil.Equal(lockedUntil))
+	assert.Nil(t, attempts.LastFailedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptsRepository_UpdateAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLoginAttemptsRepository(db)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO login_attempts (scope,key) VALUES ($1,$2) ON CONFLICT (scope, key) DO NOTHING`)).
+		WithArgs(models.LoginScopeIP, "10.0.0.1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT scope, key, failed_count, lockouts, locked_until, last_failed_at FROM login_attempts WHERE key = $1 AND scope = $2 FOR UPDATE`)).
+		WithArgs("10.0.0.1", models.LoginScopeIP).
+		WillReturnRows(sqlmock.NewRows(loginAttemptsColumns).
+			AddRow(models.LoginScopeIP, "10.0.0.1", 2, 0, nil, now.Add(-time.Minute)))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE login_attempts SET failed_count = $1, lockouts = $2, locked_until = $3, last_failed_at = $4 WHERE key = $5 AND scope = $6`)).
+		WithArgs(3, 0, nil, now, "10.0.0.1", models.LoginScopeIP).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts, err := repo.UpdateAttempts(models.LoginScopeIP, "10.0.0.1", func(attempts *models.LoginAttempts) {
+		attempts.FailedCount++
+		attempts.LastFailedAt = &now
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts.FailedCount)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginAttemptsRepository_DeleteAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewLoginAttemptsRepository(db)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM login_attempts WHERE key = $1 AND scope = $2`)
+
+	mock.ExpectExec(deleteQuery).
+		WithArgs("user@example.com", models.LoginScopeEmail).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(deleteQuery).
+		WithArgs("other@example.com", models.LoginScopeEmail).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.DeleteAttempts(models.LoginScopeEmail, "user@example.com"))
+	assert.Equal(t, sql.ErrNoRows, repo.DeleteAttempts(models.LoginScopeEmail, "other@example.com"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_Record(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAuditRepository(db)
+	actorID := uuid.New()
+	entry := &models.AuditEntry{
+		Action:    models.AuditAccountUnlocked,
+		ActorID:   &actorID,
+		Email:     "user@example.com",
+		Details:   json.RawMessage(`{"reason":"звонок в поддержку"}`),
+		CreatedAt: time.Now(),
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO audit_log (action,actor_id,user_id,email,ip,details,created_at) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id`)).
+		WithArgs(entry.Action, entry.ActorID, entry.UserID, sql.NullString{String: "user@example.com", Valid: true},
+			sql.NullString{}, `{"reason":"звонок в поддержку"}`, entry.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+	require.NoError(t, repo.Record(entry))
+	assert.Equal(t, int64(7), entry.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,227 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var outboxInsertQuery = regexp.QuoteMeta(`INSERT INTO outbox (event_id, event_type, pvz_id, payload, created_at) SELECT $1, $2, pvz_id, $3::jsonb, $4 FROM receptions WHERE id = $5 FOR UPDATE`)
+
+func expectOutboxEvent(mock pgxmock.PgxPoolIface, eventType models.EventType, receptionID uuid.UUID) {
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(pgxmock.AnyArg(), eventType, pgxmock.AnyArg(), pgxmock.AnyArg(), receptionID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func TestPVZRepository_CreateReception_RollbackOnOutboxError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+	reception := &models.Reception{ID: uuid.New(), DateTime: time.Now(), PVZID: uuid.New(), Status: models.InProgress}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO receptions`)).
+		WithArgs(reception.ID, reception.DateTime, reception.PVZID, reception.Status).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(outboxInsertQuery).
+		WithArgs(pgxmock.AnyArg(), models.ReceptionCreated, pgxmock.AnyArg(), pgxmock.AnyArg(), reception.ID).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = repo.CreateReception(reception)
+
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ClaimPending(t *testing.T) {
+	lockQuery := regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1)`)
+	claimQuery := regexp.QuoteMeta(`UPDATE outbox SET next_attempt_at = $1 WHERE id IN (` +
+		`SELECT o.id FROM outbox o WHERE o.failed_at IS NULL AND o.published_at IS NULL ` +
+		`AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= $2) ` +
+		`AND NOT EXISTS (SELECT 1 FROM outbox h WHERE h.pvz_id = o.pvz_id AND h.id < o.id ` +
+		`AND h.published_at IS NULL AND h.failed_at IS NULL AND h.next_attempt_at > $3) ` +
+		`ORDER BY o.id LIMIT 10) ` +
+		`RETURNING id, event_id, event_type, pvz_id, payload, created_at, attempts`)
+	columns := []string{"id", "event_id", "event_type", "pvz_id", "payload", "created_at", "attempts"}
+
+	t.Run("Claims Before Publishing", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewOutboxRepository(db)
+		eventID := uuid.New()
+		pvzID := uuid.New()
+		now := time.Now()
+		leaseUntil := now.Add(time.Minute)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery(claimQuery).
+			WithArgs(leaseUntil, now, now).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(2, uuid.New(), string(models.ProductRemoved), pvzID, []byte(`{}`), now, 3).
+				AddRow(1, eventID, string(models.ProductAdded), pvzID, []byte(`{"type":"обувь"}`), now, 0))
+		mock.ExpectCommit()
+
+		events, err := repo.ClaimPending(now, leaseUntil, 10)
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, int64(1), events[0].ID, "события упорядочены по id")
+		assert.Equal(t, eventID, events[0].EventID)
+		assert.Equal(t, models.ProductAdded, events[0].Type)
+		assert.Equal(t, pvzID, events[0].PVZID)
+		assert.JSONEq(t, `{"type":"обувь"}`, string(events[0].Payload))
+		assert.Equal(t, 3, events[1].Attempts)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Locked By Another Relay", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewOutboxRepository(db)
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+		mock.ExpectRollback()
+
+		events, err := repo.ClaimPending(now, now.Add(time.Minute), 10)
+
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback On Error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewOutboxRepository(db)
+		now := time.Now()
+		claimErr := errors.New("claim failed")
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		mock.ExpectQuery(claimQuery).WillReturnError(claimErr)
+		mock.ExpectRollback()
+
+		_, err = repo.ClaimPending(now, now.Add(time.Minute), 10)
+
+		assert.Equal(t, claimErr, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOutboxRepository_RecordResults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxRepository(db)
+	nextAttemptAt := time.Now().Add(time.Minute)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET published_at = $1, attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $4`)).
+		WithArgs(sqlmock.AnyArg(), nil, nil, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $1, last_error = $2 WHERE id = $3`)).
+		WithArgs(nextAttemptAt, "timeout", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $1, failed_at = $2, last_error = $3 WHERE id = $4`)).
+		WithArgs(nil, sqlmock.AnyArg(), "bad payload", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET next_attempt_at = $1 WHERE id IN ($2,$3) AND published_at IS NULL`)).
+		WithArgs(nil, int64(4), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	require.NoError(t, repo.MarkPublished(1))
+	require.NoError(t, repo.MarkFailed(2, "timeout", nextAttemptAt))
+	require.NoError(t, repo.MarkDead(3, "bad payload"))
+	require.NoError(t, repo.Release([]int64{4, 5}))
+	require.NoError(t, repo.Release(nil))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_LatestEventID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(id), 0) FROM outbox`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	id, err := repo.LatestEventID()
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ListEventsAfter(t *testing.T) {
+	columns := []string{"id", "event_id", "event_type", "pvz_id", "payload", "created_at", "city"}
+
+	t.Run("By PVZ", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewOutboxRepository(db)
+		pvzID := uuid.New()
+		eventID := uuid.New()
+		createdAt := time.Now()
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT o.id, o.event_id, o.event_type, o.pvz_id, o.payload, o.created_at, p.city FROM outbox o JOIN pvz p ON p.id = o.pvz_id WHERE o.id > $1 AND o.pvz_id = $2 ORDER BY o.id LIMIT 100`)).
+			WithArgs(int64(10), pvzID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(int64(11), eventID, models.ProductAdded, pvzID, []byte(`{"type":"обувь"}`), createdAt, models.Moscow))
+
+		events, err := repo.ListEventsAfter(10, models.EventFilter{PVZID: &pvzID}, 100)
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, int64(11), events[0].ID)
+		assert.Equal(t, eventID, events[0].EventID)
+		assert.Equal(t, models.Moscow, events[0].City)
+		assert.JSONEq(t, `{"type":"обувь"}`, string(events[0].Payload))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("By City", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewOutboxRepository(db)
+		city := models.Kazan
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT o.id, o.event_id, o.event_type, o.pvz_id, o.payload, o.created_at, p.city FROM outbox o JOIN pvz p ON p.id = o.pvz_id WHERE o.id > $1 AND p.city = $2 ORDER BY o.id LIMIT 500`)).
+			WithArgs(int64(0), city).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		events, err := repo.ListEventsAfter(0, models.EventFilter{City: &city}, 500)
+
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
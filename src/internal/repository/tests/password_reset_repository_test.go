@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetRepository_CreateToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPasswordResetRepository(db)
+	now := time.Now()
+	token := &models.PasswordResetToken{TokenHash: "hash", UserID: uuid.New(), ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO password_reset_tokens (token_hash,user_id,expires_at,created_at) VALUES ($1,$2,$3,$4)`)).
+		WithArgs(token.TokenHash, token.UserID, token.ExpiresAt, token.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.CreateToken(token))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordResetRepository_ConsumeToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPasswordResetRepository(db)
+	now := time.Now()
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = $1 WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $3 RETURNING user_id`)
+
+	mock.ExpectQuery(query).
+		WithArgs(now, "hash", now).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+	mock.ExpectQuery(query).
+		WithArgs(now, "hash", now).
+		WillReturnError(sql.ErrNoRows)
+
+	consumed, err := repo.ConsumeToken("hash", now)
+	require.NoError(t, err)
+	assert.Equal(t, userID, consumed)
+
+	_, err = repo.ConsumeToken("hash", now)
+	assert.Equal(t, sql.ErrNoRows, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
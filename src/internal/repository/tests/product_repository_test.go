@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/repository"
 	"database/sql"
@@ -8,17 +9,18 @@ import (
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 func TestPVZRepository_CreateProduct(t *testing.T) {
-    db, mock, err := sqlmock.New()
+    mock, err := pgxmock.NewPool()
     require.NoError(t, err)
-    defer db.Close()
+    defer mock.Close()
 
-    repo := repository.NewPVZRepository(db)
+    repo := repository.NewPVZRepository(mock)
     productID := uuid.New()
     receptionID := uuid.New()
     now := time.Now()
@@ -30,30 +32,79 @@ func TestPVZRepository_CreateProduct(t *testing.T) {
         ReceptionID: receptionID,
     }
 
-    mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO products (id,date_time,type,reception_id) VALUES ($1,$2,$3,$4)`)).
+    mock.ExpectBegin()
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM receptions WHERE id = $1 FOR UPDATE`)).
+        WithArgs(receptionID).
+        WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(models.InProgress))
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM products WHERE reception_id = $1 AND deleted_at IS NULL`)).
+        WithArgs(receptionID).
+        WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(9))
+    mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO products (id, date_time, type, reception_id) VALUES ($1, $2, $3, $4)`)).
         WithArgs(product.ID, product.DateTime, product.Type, product.ReceptionID).
-        WillReturnResult(sqlmock.NewResult(1, 1))
+        WillReturnResult(pgxmock.NewResult("INSERT", 1))
+    expectOutboxEvent(mock, models.ProductAdded, receptionID)
+    mock.ExpectCommit()
 
-    err = repo.CreateProduct(product)
+    err = repo.CreateProduct(product, 10)
     require.NoError(t, err)
     require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestPVZRepository_CreateProduct_Rejected(t *testing.T) {
+    receptionID := uuid.New()
+    product := &models.Product{ID: uuid.New(), DateTime: time.Now(), Type: models.Electronics, ReceptionID: receptionID}
+
+    t.Run("Limit Reached", func(t *testing.T) {
+        mock, err := pgxmock.NewPool()
+        require.NoError(t, err)
+        defer mock.Close()
+
+        mock.ExpectBegin()
+        mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM receptions WHERE id = $1 FOR UPDATE`)).
+            WithArgs(receptionID).
+            WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(models.InProgress))
+        mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM products WHERE reception_id = $1 AND deleted_at IS NULL`)).
+            WithArgs(receptionID).
+            WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(10))
+        mock.ExpectRollback()
+
+        err = repository.NewPVZRepository(mock).CreateProduct(product, 10)
+        assert.Equal(t, apperrors.ErrReceptionProductLimit, err)
+        require.NoError(t, mock.ExpectationsWereMet())
+    })
+
+    t.Run("Reception Closed", func(t *testing.T) {
+        mock, err := pgxmock.NewPool()
+        require.NoError(t, err)
+        defer mock.Close()
+
+        mock.ExpectBegin()
+        mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM receptions WHERE id = $1 FOR UPDATE`)).
+            WithArgs(receptionID).
+            WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(models.Closed))
+        mock.ExpectRollback()
+
+        err = repository.NewPVZRepository(mock).CreateProduct(product, 0)
+        assert.Equal(t, apperrors.ErrReceptionClosed, err)
+        require.NoError(t, mock.ExpectationsWereMet())
+    })
+}
+
 func TestPVZRepository_GetLastProductInReception(t *testing.T) {
-    db, mock, err := sqlmock.New()
+    mock, err := pgxmock.NewPool()
     require.NoError(t, err)
-    defer db.Close()
+    defer mock.Close()
 
-    repo := repository.NewPVZRepository(db)
+    repo := repository.NewPVZRepository(mock)
     productID := uuid.New()
     receptionID := uuid.New()
     now := time.Now()
 
     t.Run("Success", func(t *testing.T) {
-        rows := sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
+        rows := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
             AddRow(productID, now, models.Electronics, receptionID)
 
-        mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 ORDER BY date_time DESC LIMIT 1`)).
+        mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 AND deleted_at IS NULL ORDER BY date_time DESC LIMIT 1`)).
             WithArgs(receptionID).
             WillReturnRows(rows)
 
@@ -66,9 +117,9 @@ func TestPVZRepository_GetLastProductInReception(t *testing.T) {
     })
 
     t.Run("Not Found", func(t *testing.T) {
-        mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 ORDER BY date_time DESC LIMIT 1`)).
+        mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 AND deleted_at IS NULL ORDER BY date_time DESC LIMIT 1`)).
             WithArgs(receptionID).
-            WillReturnError(sql.ErrNoRows)
+            WillReturnError(pgx.ErrNoRows)
 
         product, err := repo.GetLastProductInReception(receptionID)
         require.NoError(t, err)
@@ -76,7 +127,7 @@ func TestPVZRepository_GetLastProductInReception(t *testing.T) {
     })
 
     t.Run("DB Error", func(t *testing.T) {
-        mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 ORDER BY date_time DESC LIMIT 1`)).
+        mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, date_time, type, reception_id FROM products WHERE reception_id = $1 AND deleted_at IS NULL ORDER BY date_time DESC LIMIT 1`)).
             WithArgs(receptionID).
             WillReturnError(sql.ErrConnDone)
 
@@ -87,39 +138,65 @@ func TestPVZRepository_GetLastProductInReception(t *testing.T) {
 }
 
 func TestPVZRepository_DeleteProduct(t *testing.T) {
-    db, mock, err := sqlmock.New()
+    mock, err := pgxmock.NewPool()
     require.NoError(t, err)
-    defer db.Close()
+    defer mock.Close()
 
-    repo := repository.NewPVZRepository(db)
+    repo := repository.NewPVZRepository(mock)
     productID := uuid.New()
+    userID := uuid.New()
+    receptionID := uuid.New()
+    deleteQuery := regexp.QuoteMeta(`UPDATE products SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL RETURNING date_time, type, reception_id`)
+    deletedRow := func() *pgxmock.Rows {
+        return pgxmock.NewRows([]string{"date_time", "type", "reception_id"}).
+            AddRow(time.Now(), models.Electronics, receptionID)
+    }
 
     t.Run("Success", func(t *testing.T) {
-        mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM products WHERE id = $1`)).
-            WithArgs(productID).
-            WillReturnResult(sqlmock.NewResult(1, 1))
+        mock.ExpectBegin()
+        mock.ExpectQuery(deleteQuery).
+            WithArgs(pgxmock.AnyArg(), userID, productID).
+            WillReturnRows(deletedRow())
+        expectOutboxEvent(mock, models.ProductRemoved, receptionID)
+        mock.ExpectCommit()
+
+        err = repo.DeleteProduct(productID, userID)
+        require.NoError(t, err)
+    })
+
+    t.Run("Without Actor", func(t *testing.T) {
+        mock.ExpectBegin()
+        mock.ExpectQuery(deleteQuery).
+            WithArgs(pgxmock.AnyArg(), nil, productID).
+            WillReturnRows(deletedRow())
+        expectOutboxEvent(mock, models.ProductRemoved, receptionID)
+        mock.ExpectCommit()
 
-        err = repo.DeleteProduct(productID)
+        err = repo.DeleteProduct(productID, uuid.Nil)
         require.NoError(t, err)
     })
 
-    t.Run("Not Found", func(t *testing.T) {
-        mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM products WHERE id = $1`)).
-            WithArgs(productID).
-            WillReturnResult(sqlmock.NewResult(0, 0))
+    t.Run("Already Deleted", func(t *testing.T) {
+        mock.ExpectBegin()
+        mock.ExpectQuery(deleteQuery).
+            WithArgs(pgxmock.AnyArg(), userID, productID).
+            WillReturnRows(pgxmock.NewRows([]string{"date_time", "type", "reception_id"}))
+        mock.ExpectRollback()
 
-        err = repo.DeleteProduct(productID)
+        err = repo.DeleteProduct(productID, userID)
         assert.Equal(t, sql.ErrNoRows, err)
     })
 
     t.Run("DB Error", func(t *testing.T) {
-        mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM products WHERE id = $1`)).
-            WithArgs(productID).
+        mock.ExpectBegin()
+        mock.ExpectQuery(deleteQuery).
+            WithArgs(pgxmock.AnyArg(), userID, productID).
             WillReturnError(sql.ErrConnDone)
+        mock.ExpectRollback()
 
-        err = repo.DeleteProduct(productID)
+        err = repo.DeleteProduct(productID, userID)
         require.Error(t, err)
     })
 
     require.NoError(t, mock.ExpectationsWereMet())
-}
\ No newline at end of file
+}
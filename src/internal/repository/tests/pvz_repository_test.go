@@ -3,47 +3,50 @@ package repository_test
 import (
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/repository"
+	"avito-backend/src/pkg/database"
+	"avito-backend/src/pkg/geo"
 	"database/sql"
 	"regexp"
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestPVZRepository_GetPVZsWithReceptions(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	productID := uuid.New()
 	now := time.Now()
 
-	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id GROUP BY p.id, p.registration_date, p.city ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`)
-	receptionQuery := regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE r.pvz_id = $1`)
-	productQuery := regexp.QuoteMeta(`SELECT p.id, p.date_time, p.type, p.reception_id FROM products p WHERE p.reception_id = $1`)
+	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city, p.status, p.updated_at, p.address, p.latitude, p.longitude FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id WHERE p.status <> $1 GROUP BY p.id ORDER BY p.registration_date DESC LIMIT $2 OFFSET $3`)
+	receptionQuery := regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status, r.closed_at, r.closed_by_system FROM receptions r WHERE r.pvz_id = ANY($1) ORDER BY r.date_time`)
+	productQuery := regexp.QuoteMeta(`SELECT p.id, p.date_time, p.type, p.reception_id, p.deleted_at, p.deleted_by FROM products p WHERE p.reception_id = ANY($1) AND p.deleted_at IS NULL ORDER BY p.date_time`)
 
-	pvzRows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-		AddRow(pvzID, now, string(models.Moscow))
+	pvzRows := pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}).
+		AddRow(pvzID, now, string(models.Moscow), string(models.PVZActive), nil, nil, nil, nil)
 
-	receptionRows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-		AddRow(receptionID, now, pvzID, string(models.Closed))
+	receptionRows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "closed_at", "closed_by_system"}).
+		AddRow(receptionID, now, pvzID, string(models.Closed), now, true)
 
-	productRows := sqlmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
-		AddRow(productID, now, string(models.Electronics), receptionID)
+	productRows := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "deleted_at", "deleted_by"}).
+		AddRow(productID, now, string(models.Electronics), receptionID, nil, nil)
 
-	mock.ExpectQuery(pvzQuery).WillReturnRows(pvzRows)
-	mock.ExpectQuery(receptionQuery).WithArgs(pvzID).WillReturnRows(receptionRows)
-	mock.ExpectQuery(productQuery).WithArgs(receptionID).WillReturnRows(productRows)
+	mock.ExpectQuery(pvzQuery).WithArgs(models.PVZArchived, 10, 0).WillReturnRows(pvzRows)
+	mock.ExpectQuery(receptionQuery).WithArgs([]uuid.UUID{pvzID}).WillReturnRows(receptionRows)
+	mock.ExpectQuery(productQuery).WithArgs([]uuid.UUID{receptionID}).WillReturnRows(productRows)
 
-	result, err := repo.GetPVZsWithReceptions(time.Time{}, time.Time{}, 0, 10)
+	result, err := repo.GetPVZsWithReceptions(models.PVZFilter{Limit: 10})
 
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -55,24 +58,64 @@ func TestPVZRepository_GetPVZsWithReceptions(t *testing.T) {
 	require.Len(t, result[0].Receptions, 1)
 	assert.Equal(t, receptionID, result[0].Receptions[0].Reception.ID)
 	assert.Equal(t, models.Closed, result[0].Receptions[0].Reception.Status)
+	assert.True(t, result[0].Receptions[0].Reception.ClosedBySystem)
+	assert.NotNil(t, result[0].Receptions[0].Reception.ClosedAt)
 
 	require.Len(t, result[0].Receptions[0].Products, 1)
 	assert.Equal(t, productID, result[0].Receptions[0].Products[0].ID)
 	assert.Equal(t, models.Electronics, result[0].Receptions[0].Products[0].Type)
 }
 
+func TestPVZRepository_GetPVZsWithReceptions_IncludeDeleted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+	deletedBy := uuid.New()
+	now := time.Now()
+
+	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city, p.status, p.updated_at, p.address, p.latitude, p.longitude FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id WHERE p.status <> $1 GROUP BY p.id ORDER BY p.registration_date DESC LIMIT $2 OFFSET $3`)
+	receptionQuery := regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status, r.closed_at, r.closed_by_system FROM receptions r WHERE r.pvz_id = ANY($1) ORDER BY r.date_time`)
+	productQuery := regexp.QuoteMeta(`SELECT p.id, p.date_time, p.type, p.reception_id, p.deleted_at, p.deleted_by FROM products p WHERE p.reception_id = ANY($1) ORDER BY p.date_time`) + "$"
+
+	mock.ExpectQuery(pvzQuery).WithArgs(models.PVZArchived, 10, 0).WillReturnRows(pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}).
+		AddRow(pvzID, now, string(models.Moscow), string(models.PVZActive), nil, nil, nil, nil))
+	mock.ExpectQuery(receptionQuery).WithArgs([]uuid.UUID{pvzID}).WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "closed_at", "closed_by_system"}).
+		AddRow(receptionID, now, pvzID, string(models.InProgress), nil, false))
+	mock.ExpectQuery(productQuery).WithArgs([]uuid.UUID{receptionID}).WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "deleted_at", "deleted_by"}).
+		AddRow(productID, now, string(models.Shoes), receptionID, now, deletedBy.String()))
+
+	result, err := repo.GetPVZsWithReceptions(models.PVZFilter{Limit: 10, IncludeDeleted: true})
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Receptions, 1)
+	require.Len(t, result[0].Receptions[0].Products, 1)
+
+	product := result[0].Receptions[0].Products[0]
+	require.NotNil(t, product.DeletedAt)
+	require.NotNil(t, product.DeletedBy)
+	assert.Equal(t, deletedBy, *product.DeletedBy)
+}
+
 func TestPVZRepository_GetPVZsWithReceptions_Empty(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 
-	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id GROUP BY p.id, p.registration_date, p.city ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`)
+	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city, p.status, p.updated_at, p.address, p.latitude, p.longitude FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id WHERE p.status <> $1 GROUP BY p.id ORDER BY p.registration_date DESC LIMIT $2 OFFSET $3`)
 
-	mock.ExpectQuery(pvzQuery).WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}))
+	mock.ExpectQuery(pvzQuery).WithArgs(models.PVZArchived, 10, 0).WillReturnRows(pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}))
 
-	result, err := repo.GetPVZsWithReceptions(time.Time{}, time.Time{}, 0, 10)
+	result, err := repo.GetPVZsWithReceptions(models.PVZFilter{Limit: 10})
 
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -80,17 +123,17 @@ func TestPVZRepository_GetPVZsWithReceptions_Empty(t *testing.T) {
 }
 
 func TestPVZRepository_GetPVZsWithReceptions_DBError(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 
-	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id GROUP BY p.id, p.registration_date, p.city ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`)
+	pvzQuery := regexp.QuoteMeta(`SELECT p.id, p.registration_date, p.city, p.status, p.updated_at, p.address, p.latitude, p.longitude FROM pvz p LEFT JOIN receptions r ON p.id = r.pvz_id WHERE p.status <> $1 GROUP BY p.id ORDER BY p.registration_date DESC LIMIT $2 OFFSET $3`)
 
-	mock.ExpectQuery(pvzQuery).WillReturnError(sql.ErrConnDone)
+	mock.ExpectQuery(pvzQuery).WithArgs(models.PVZArchived, 10, 0).WillReturnError(sql.ErrConnDone)
 
-	result, err := repo.GetPVZsWithReceptions(time.Time{}, time.Time{}, 0, 10)
+	result, err := repo.GetPVZsWithReceptions(models.PVZFilter{Limit: 10})
 
 	require.Error(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -98,11 +141,11 @@ func TestPVZRepository_GetPVZsWithReceptions_DBError(t *testing.T) {
 }
 
 func TestPVZRepository_Create(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 	pvzID := uuid.New()
 	now := time.Now()
 
@@ -110,12 +153,13 @@ func TestPVZRepository_Create(t *testing.T) {
 		ID:               pvzID,
 		RegistrationDate: now,
 		City:             models.Moscow,
+		Status:           models.PVZActive,
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz (id,registration_date,city) VALUES ($1,$2,$3)`)).
-			WithArgs(pvz.ID, pvz.RegistrationDate, pvz.City).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz (id, registration_date, city, status, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6, $7)`)).
+			WithArgs(pvz.ID, pvz.RegistrationDate, pvz.City, pvz.Status, nil, pvz.Latitude, pvz.Longitude).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err := repo.Create(pvz)
 		require.NoError(t, err)
@@ -123,8 +167,8 @@ func TestPVZRepository_Create(t *testing.T) {
 	})
 
 	t.Run("DB Error", func(t *testing.T) {
-		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz (id,registration_date,city) VALUES ($1,$2,$3)`)).
-			WithArgs(pvz.ID, pvz.RegistrationDate, pvz.City).
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz (id, registration_date, city, status, address, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6, $7)`)).
+			WithArgs(pvz.ID, pvz.RegistrationDate, pvz.City, pvz.Status, nil, pvz.Latitude, pvz.Longitude).
 			WillReturnError(sql.ErrConnDone)
 
 		err := repo.Create(pvz)
@@ -134,19 +178,19 @@ func TestPVZRepository_Create(t *testing.T) {
 }
 
 func TestPVZRepository_GetByID(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 	pvzID := uuid.New()
 	now := time.Now()
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-			AddRow(pvzID, now, string(models.Moscow))
+		rows := pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}).
+			AddRow(pvzID, now, string(models.Moscow), string(models.PVZActive), nil, nil, nil, nil)
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city FROM pvz WHERE id = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE id = $1`)).
 			WithArgs(pvzID).
 			WillReturnRows(rows)
 
@@ -155,13 +199,14 @@ func TestPVZRepository_GetByID(t *testing.T) {
 		require.NotNil(t, pvz)
 		assert.Equal(t, pvzID, pvz.ID)
 		assert.Equal(t, models.Moscow, pvz.City)
+		assert.Equal(t, models.PVZActive, pvz.Status)
 		assert.Equal(t, now.Unix(), pvz.RegistrationDate.Unix()) 
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city FROM pvz WHERE id = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE id = $1`)).
 			WithArgs(pvzID).
-			WillReturnError(sql.ErrNoRows)
+			WillReturnError(pgx.ErrNoRows)
 
 		pvz, err := repo.GetByID(pvzID)
 		require.Error(t, err)
@@ -170,7 +215,7 @@ func TestPVZRepository_GetByID(t *testing.T) {
 	})
 
 	t.Run("DB Error", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city FROM pvz WHERE id = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE id = $1`)).
 			WithArgs(pvzID).
 			WillReturnError(sql.ErrConnDone)
 
@@ -180,3 +225,176 @@ func TestPVZRepository_GetByID(t *testing.T) {
 		assert.Nil(t, pvz)
 	})
 }
+
+func TestPVZRepository_Update(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+	lat, lon := 55.7961, 49.1064
+	pvz := &models.PVZ{
+		ID:        uuid.New(),
+		City:      models.Kazan,
+		Status:    models.PVZInactive,
+		Address:   "ул. Баумана, 1",
+		Latitude:  &lat,
+		Longitude: &lon,
+	}
+	updateQuery := regexp.QuoteMeta(`UPDATE pvz SET city = $1, status = $2, address = $3, latitude = $4, longitude = $5, updated_at = $6 WHERE id = $7`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(updateQuery).
+			WithArgs(pvz.City, pvz.Status, pvz.Address, pvz.Latitude, pvz.Longitude, pgxmock.AnyArg(), pvz.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATEQUERY", 1))
+
+		err := repo.Update(pvz)
+		require.NoError(t, err)
+		assert.NotNil(t, pvz.UpdatedAt)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectExec(updateQuery).
+			WithArgs(pvz.City, pvz.Status, pvz.Address, pvz.Latitude, pvz.Longitude, pgxmock.AnyArg(), pvz.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATEQUERY", 0))
+
+		err := repo.Update(pvz)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_GetInBoundingBox(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+	box := geo.BoundingBox{MinLat: 55, MaxLat: 56, MinLon: 37, MaxLon: 38}
+	query := regexp.QuoteMeta(`SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4 AND status <> $5`)
+
+	t.Run("Success", func(t *testing.T) {
+		pvzID := uuid.New()
+		rows := pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}).
+			AddRow(pvzID, time.Now(), string(models.Moscow), string(models.PVZActive), nil, "Тверская, 1", 55.7575, 37.6135)
+
+		mock.ExpectQuery(query).
+			WithArgs(box.MinLat, box.MaxLat, box.MinLon, box.MaxLon, models.PVZArchived).
+			WillReturnRows(rows)
+
+		pvzs, err := repo.GetInBoundingBox(box)
+		require.NoError(t, err)
+		require.Len(t, pvzs, 1)
+		assert.Equal(t, pvzID, pvzs[0].ID)
+		assert.Equal(t, "Тверская, 1", pvzs[0].Address)
+		require.NotNil(t, pvzs[0].Latitude)
+		assert.Equal(t, 55.7575, *pvzs[0].Latitude)
+	})
+
+	t.Run("DB Error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(box.MinLat, box.MaxLat, box.MinLon, box.MaxLon, models.PVZArchived).
+			WillReturnError(sql.ErrConnDone)
+
+		pvzs, err := repo.GetInBoundingBox(box)
+		require.Error(t, err)
+		assert.Nil(t, pvzs)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type staticRouter struct {
+	db database.Querier
+}
+
+func (r staticRouter) Reader() database.Querier {
+	return r.db
+}
+
+func TestPVZRepository_WithReplicas(t *testing.T) {
+	primary, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer primary.Close()
+
+	replica, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer replica.Close()
+
+	repo := repository.NewPVZRepository(primary).WithReplicas(staticRouter{db: replica})
+	pvzID := uuid.New()
+
+	replica.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE id = $1`)).
+		WithArgs(pvzID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}).
+			AddRow(pvzID, time.Now(), string(models.Moscow), string(models.PVZActive), nil, nil, nil, nil))
+
+	pvz, err := repo.GetByID(pvzID)
+	require.NoError(t, err)
+
+	primary.ExpectExec(regexp.QuoteMeta(`UPDATE pvz SET city = $1, status = $2, address = $3, latitude = $4, longitude = $5, updated_at = $6 WHERE id = $7`)).
+		WithArgs(pvz.City, pvz.Status, nil, pvz.Latitude, pvz.Longitude, pgxmock.AnyArg(), pvz.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	require.NoError(t, repo.Update(pvz))
+	assert.NoError(t, replica.ExpectationsWereMet())
+	assert.NoError(t, primary.ExpectationsWereMet())
+}
+
+func TestPVZRepository_LockPVZ(t *testing.T) {
+	lockQuery := regexp.QuoteMeta(`SELECT id FROM pvz WHERE id = $1 FOR UPDATE`)
+
+	t.Run("Reads Inside Transaction", func(t *testing.T) {
+		primary, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer primary.Close()
+
+		replica, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer replica.Close()
+
+		repo := repository.NewPVZRepository(primary).WithReplicas(staticRouter{db: replica})
+		pvzID := uuid.New()
+
+		primary.ExpectBegin()
+		primary.ExpectQuery(lockQuery).
+			WithArgs(pvzID).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(pvzID))
+		primary.ExpectQuery(regexp.QuoteMeta(`SELECT id, registration_date, city, status, updated_at, address, latitude, longitude FROM pvz WHERE id = $1`)).
+			WithArgs(pvzID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "registration_date", "city", "status", "updated_at", "address", "latitude", "longitude"}).
+				AddRow(pvzID, time.Now(), string(models.Moscow), string(models.PVZActive), nil, nil, nil, nil))
+		primary.ExpectCommit()
+
+		err = repo.LockPVZ(pvzID, func(locked repository.PVZRepositoryInterface) error {
+			_, err := locked.GetByID(pvzID)
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, replica.ExpectationsWereMet())
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+
+	t.Run("PVZ Not Found", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewPVZRepository(mock)
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(pvzID).WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+
+		err = repo.LockPVZ(pvzID, func(repository.PVZRepositoryInterface) error {
+			t.Fatal("fn не должна вызываться")
+			return nil
+		})
+
+		assert.Equal(t, sql.ErrNoRows, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -8,18 +8,19 @@ import (
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestPVZRepository_CreateReception(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	now := time.Now()
@@ -31,9 +32,12 @@ func TestPVZRepository_CreateReception(t *testing.T) {
 		Status:   models.InProgress,
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO receptions (id,date_time,pvz_id,status) VALUES ($1,$2,$3,$4)`)).
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO receptions (id, date_time, pvz_id, status) VALUES ($1, $2, $3, $4)`)).
 		WithArgs(reception.ID, reception.DateTime, reception.PVZID, reception.Status).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	expectOutboxEvent(mock, models.ReceptionCreated, receptionID)
+	mock.ExpectCommit()
 
 	err = repo.CreateReception(reception)
 	require.NoError(t, err)
@@ -41,20 +45,20 @@ func TestPVZRepository_CreateReception(t *testing.T) {
 }
 
 func TestPVZRepository_GetActiveReceptionByPVZID(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 	pvzID := uuid.New()
 	receptionID := uuid.New()
 	now := time.Now()
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
+		rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
 			AddRow(receptionID, now, pvzID, models.InProgress)
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE (r.pvz_id = $1 AND r.status = $2)`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE r.pvz_id = $1 AND r.status = $2`)).
 			WithArgs(pvzID, models.InProgress).
 			WillReturnRows(rows)
 
@@ -67,9 +71,9 @@ func TestPVZRepository_GetActiveReceptionByPVZID(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE (r.pvz_id = $1 AND r.status = $2)`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE r.pvz_id = $1 AND r.status = $2`)).
 			WithArgs(pvzID, models.InProgress).
-			WillReturnError(sql.ErrNoRows)
+			WillReturnError(pgx.ErrNoRows)
 
 		reception, err := repo.GetActiveReceptionByPVZID(pvzID)
 		require.NoError(t, err)
@@ -77,7 +81,7 @@ func TestPVZRepository_GetActiveReceptionByPVZID(t *testing.T) {
 	})
 
 	t.Run("DB Error", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE (r.pvz_id = $1 AND r.status = $2)`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r WHERE r.pvz_id = $1 AND r.status = $2`)).
 			WithArgs(pvzID, models.InProgress).
 			WillReturnError(sql.ErrConnDone)
 
@@ -88,54 +92,145 @@ func TestPVZRepository_GetActiveReceptionByPVZID(t *testing.T) {
 }
 
 func TestPVZRepository_UpdateReception(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewPVZRepository(db)
+	repo := repository.NewPVZRepository(mock)
 	receptionID := uuid.New()
 
 	t.Run("Success", func(t *testing.T) {
+		closedAt := time.Now()
 		reception := &models.Reception{
-			ID:     receptionID,
-			Status: models.Closed,
+			ID:       receptionID,
+			Status:   models.Closed,
+			ClosedAt: &closedAt,
 		}
 
-		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1 WHERE id = $2`)).
-			WithArgs(reception.Status, reception.ID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4`)).
+			WithArgs(reception.Status, reception.ClosedAt, false, reception.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		expectOutboxEvent(mock, models.ReceptionClosed, receptionID)
+		mock.ExpectCommit()
 
 		err = repo.UpdateReception(reception)
 		require.NoError(t, err)
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
+		closedAt := time.Now()
 		reception := &models.Reception{
-			ID:     receptionID,
-			Status: models.Closed,
+			ID:       receptionID,
+			Status:   models.Closed,
+			ClosedAt: &closedAt,
 		}
 
-		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1 WHERE id = $2`)).
-			WithArgs(reception.Status, reception.ID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4`)).
+			WithArgs(reception.Status, reception.ClosedAt, false, reception.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
 
 		err = repo.UpdateReception(reception)
 		assert.Equal(t, sql.ErrNoRows, err)
 	})
 
 	t.Run("DB Error", func(t *testing.T) {
+		closedAt := time.Now()
 		reception := &models.Reception{
-			ID:     receptionID,
-			Status: models.Closed,
+			ID:       receptionID,
+			Status:   models.Closed,
+			ClosedAt: &closedAt,
 		}
 
-		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1 WHERE id = $2`)).
-			WithArgs(reception.Status, reception.ID).
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4`)).
+			WithArgs(reception.Status, reception.ClosedAt, false, reception.ID).
 			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
 
 		err = repo.UpdateReception(reception)
 		require.Error(t, err)
 	})
 
+	t.Run("Reopened Without Event", func(t *testing.T) {
+		reception := &models.Reception{ID: receptionID, Status: models.InProgress}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4`)).
+			WithArgs(reception.Status, reception.ClosedAt, false, reception.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.UpdateReception(reception))
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_GetStaleReceptions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	idleSince := time.Now().Add(-4 * time.Hour)
+	receptionID := uuid.New()
+	pvzID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.date_time, r.pvz_id, r.status FROM receptions r LEFT JOIN products p ON p.reception_id = r.id WHERE r.status = $1 GROUP BY r.id HAVING GREATEST(r.date_time, MAX(p.date_time), MAX(p.deleted_at)) < $2 ORDER BY r.date_time`)).
+		WithArgs(models.InProgress, idleSince).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
+			AddRow(receptionID, idleSince.Add(-time.Hour), pvzID, string(models.InProgress)))
+
+	receptions, err := repo.GetStaleReceptions(idleSince)
+
+	require.NoError(t, err)
+	require.Len(t, receptions, 1)
+	assert.Equal(t, receptionID, receptions[0].ID)
+	assert.Equal(t, pvzID, receptions[0].PVZID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_CloseIdleReception(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	idleSince := time.Now().Add(-4 * time.Hour)
+	closedAt := time.Now()
+	reception := &models.Reception{
+		ID:             uuid.New(),
+		Status:         models.Closed,
+		ClosedAt:       &closedAt,
+		ClosedBySystem: true,
+	}
+	query := regexp.QuoteMeta(`UPDATE receptions SET status = $1, closed_at = $2, closed_by_system = $3 WHERE id = $4 AND status = $5 AND NOT EXISTS (SELECT 1 FROM products p WHERE p.reception_id = receptions.id AND (p.date_time >= $6 OR p.deleted_at >= $6))`)
+
+	t.Run("Closed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(models.Closed, reception.ClosedAt, true, reception.ID, models.InProgress, idleSince).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		expectOutboxEvent(mock, models.ReceptionClosed, reception.ID)
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.CloseIdleReception(reception, idleSince))
+	})
+
+	t.Run("Activity Happened", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(models.Closed, reception.ClosedAt, true, reception.ID, models.InProgress, idleSince).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		assert.Equal(t, sql.ErrNoRows, repo.CloseIdleReception(reception, idleSince))
+	})
+
 	require.NoError(t, mock.ExpectationsWereMet())
 }
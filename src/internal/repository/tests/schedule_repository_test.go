@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPVZRepository_GetSchedules(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	configuredID := uuid.New()
+	defaultID := uuid.New()
+	holiday := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	settingsQuery := regexp.QuoteMeta(`SELECT pvz_id, timezone, override_until FROM pvz_schedules WHERE pvz_id = ANY($1)`)
+	hoursQuery := regexp.QuoteMeta(`SELECT pvz_id, weekday, opens_minute, closes_minute FROM pvz_working_hours WHERE pvz_id = ANY($1) ORDER BY pvz_id, weekday`)
+	exceptionsQuery := regexp.QuoteMeta(`SELECT pvz_id, date, closed, opens_minute, closes_minute, comment FROM pvz_calendar_exceptions WHERE pvz_id = ANY($1) ORDER BY pvz_id, date`)
+
+	mock.ExpectQuery(settingsQuery).WithArgs([]uuid.UUID{configuredID, defaultID}).
+		WillReturnRows(pgxmock.NewRows([]string{"pvz_id", "timezone", "override_until"}).
+			AddRow(configuredID, "Asia/Yekaterinburg", nil))
+	mock.ExpectQuery(hoursQuery).WithArgs([]uuid.UUID{configuredID, defaultID}).
+		WillReturnRows(pgxmock.NewRows([]string{"pvz_id", "weekday", "opens_minute", "closes_minute"}).
+			AddRow(configuredID, 1, 540, 1260))
+	mock.ExpectQuery(exceptionsQuery).WithArgs([]uuid.UUID{configuredID, defaultID}).
+		WillReturnRows(pgxmock.NewRows([]string{"pvz_id", "date", "closed", "opens_minute", "closes_minute", "comment"}).
+			AddRow(configuredID, holiday, true, nil, nil, "Новый год"))
+
+	schedules, err := repo.GetSchedules([]uuid.UUID{configuredID, defaultID})
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, schedules, 2)
+
+	configured := schedules[configuredID]
+	assert.Equal(t, "Asia/Yekaterinburg", configured.Timezone)
+	assert.Nil(t, configured.OverrideUntil)
+	require.Len(t, configured.WorkingHours, 1)
+	assert.Equal(t, models.WorkingHours{Weekday: time.Monday, Opens: 540, Closes: 1260}, configured.WorkingHours[0])
+	require.Len(t, configured.Exceptions, 1)
+	assert.Equal(t, "2025-01-01", configured.Exceptions[0].Date)
+	assert.True(t, configured.Exceptions[0].Closed)
+	assert.Equal(t, "Новый год", configured.Exceptions[0].Comment)
+
+	assert.Equal(t, models.DefaultTimezone, schedules[defaultID].Timezone)
+	assert.Empty(t, schedules[defaultID].WorkingHours)
+}
+
+func TestPVZRepository_SaveSchedule(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	pvzID := uuid.New()
+	schedule := &models.PVZSchedule{
+		PVZID:    pvzID,
+		Timezone: models.DefaultTimezone,
+		WorkingHours: []models.WorkingHours{
+			{Weekday: time.Monday, Opens: 540, Closes: 1260},
+			{Weekday: time.Tuesday, Opens: 600, Closes: 1200},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz_schedules (pvz_id, timezone, override_until) VALUES ($1, $2, $3) ON CONFLICT (pvz_id) DO UPDATE SET timezone = EXCLUDED.timezone, override_until = EXCLUDED.override_until`)).
+		WithArgs(pvzID, models.DefaultTimezone, schedule.OverrideUntil).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM pvz_working_hours WHERE pvz_id = $1`)).
+		WithArgs(pvzID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 7))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz_working_hours (pvz_id,weekday,opens_minute,closes_minute) VALUES ($1,$2,$3,$4),($5,$6,$7,$8)`)).
+		WithArgs(pvzID, 1, 540, 1260, pvzID, 2, 600, 1200).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectCommit()
+
+	err = repo.SaveSchedule(schedule)
+
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_SaveSchedule_RollbackOnError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz_schedules`)).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = repo.SaveSchedule(models.DefaultSchedule(uuid.New()))
+
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_DeleteCalendarException(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	pvzID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM pvz_calendar_exceptions WHERE pvz_id = $1 AND date = $2`)
+
+	mock.ExpectExec(query).WithArgs(pvzID, "2025-01-01").WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	require.NoError(t, repo.DeleteCalendarException(pvzID, "2025-01-01"))
+
+	mock.ExpectExec(query).WithArgs(pvzID, "2025-01-02").WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	assert.Equal(t, sql.ErrNoRows, repo.DeleteCalendarException(pvzID, "2025-01-02"))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPVZRepository_GetSettings(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	pvzID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT pvz_id, max_products_per_reception, allowed_product_types, allow_empty_close, updated_at FROM pvz_settings WHERE pvz_id = $1`)
+
+	t.Run("Found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(pvzID).
+			WillReturnRows(pgxmock.NewRows([]string{"pvz_id", "max_products_per_reception", "allowed_product_types", "allow_empty_close", "updated_at"}).
+				AddRow(pvzID, 100, []string{"одежда", "обувь"}, true, time.Now()))
+
+		settings, err := repo.GetSettings(pvzID)
+
+		require.NoError(t, err)
+		assert.Equal(t, 100, settings.MaxProductsPerReception)
+		assert.Equal(t, []models.ProductType{models.Clothes, models.Shoes}, settings.AllowedProductTypes)
+		assert.True(t, settings.AllowEmptyClose)
+		assert.NotNil(t, settings.UpdatedAt)
+	})
+
+	t.Run("Not Configured", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(pvzID).WillReturnError(pgx.ErrNoRows)
+
+		settings, err := repo.GetSettings(pvzID)
+
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, settings)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_SaveSettings(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	settings := &models.PVZSettings{
+		PVZID:                   uuid.New(),
+		MaxProductsPerReception: 100,
+		AllowedProductTypes:     []models.ProductType{models.Electronics},
+		AllowEmptyClose:         false,
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pvz_settings (pvz_id, max_products_per_reception, allowed_product_types, allow_empty_close, updated_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (pvz_id) DO UPDATE SET`)).
+		WithArgs(settings.PVZID, 100, []string{"электроника"}, false, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.SaveSettings(settings)
+
+	require.NoError(t, err)
+	assert.NotNil(t, settings.UpdatedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPVZRepository_CountProductsInReception(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewPVZRepository(mock)
+
+	receptionID := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM products WHERE reception_id = $1 AND deleted_at IS NULL`)).
+		WithArgs(receptionID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(7))
+
+	count, err := repo.CountProductsInReception(receptionID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,24 +1,27 @@
 package repository_test
 
 import (
+	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/repository"
 	"database/sql"
 	"regexp"
 	"testing"
 
-	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestUserRepository_Create(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewUserRepository(db)
+	repo := repository.NewUserRepository(mock)
 	userID := uuid.New()
 	user := &models.User{
 		ID:           userID,
@@ -28,38 +31,47 @@ func TestUserRepository_Create(t *testing.T) {
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id,email,password_hash,role) VALUES ($1,$2,$3,$4)`)).
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, password_hash, role) VALUES ($1, $2, $3, $4)`)).
 			WithArgs(user.ID, user.Email, user.PasswordHash, user.Role).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err := repo.Create(user)
 		require.NoError(t, err)
 	})
 
 	t.Run("Duplicate Email", func(t *testing.T) {
-		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id,email,password_hash,role) VALUES ($1,$2,$3,$4)`)).
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, password_hash, role) VALUES ($1, $2, $3, $4)`)).
 			WithArgs(user.ID, user.Email, user.PasswordHash, user.Role).
-			WillReturnError(sql.ErrConnDone) 
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
 
 		err := repo.Create(user)
-		require.Error(t, err)
+		assert.Equal(t, apperrors.ErrUserAlreadyExists, err)
+	})
+
+	t.Run("DB Error", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, password_hash, role) VALUES ($1, $2, $3, $4)`)).
+			WithArgs(user.ID, user.Email, user.PasswordHash, user.Role).
+			WillReturnError(sql.ErrConnDone)
+
+		err := repo.Create(user)
+		assert.ErrorIs(t, err, sql.ErrConnDone)
 	})
 }
 
 func TestUserRepository_GetByEmail(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewUserRepository(db)
+	repo := repository.NewUserRepository(mock)
 	userID := uuid.New()
 	email := "test@example.com"
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
-			AddRow(userID, email, "hashed_password", string(models.EmployeeRole))
+		rows := pgxmock.NewRows([]string{"id", "email", "password_hash", "role", "token_version", "disabled"}).
+			AddRow(userID, email, "hashed_password", string(models.EmployeeRole), 0, false)
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role FROM users WHERE email = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role, token_version, disabled FROM users WHERE email = $1`)).
 			WithArgs(email).
 			WillReturnRows(rows)
 
@@ -70,9 +82,9 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role FROM users WHERE email = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role, token_version, disabled FROM users WHERE email = $1`)).
 			WithArgs(email).
-			WillReturnError(sql.ErrNoRows)
+			WillReturnError(pgx.ErrNoRows)
 
 		user, err := repo.GetByEmail(email)
 		require.Error(t, err)
@@ -81,18 +93,18 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 }
 
 func TestUserRepository_GetByID(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewUserRepository(db)
+	repo := repository.NewUserRepository(mock)
 	userID := uuid.New()
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
-			AddRow(userID, "test@example.com", "hashed_password", string(models.EmployeeRole))
+		rows := pgxmock.NewRows([]string{"id", "email", "password_hash", "role", "token_version", "disabled"}).
+			AddRow(userID, "test@example.com", "hashed_password", string(models.EmployeeRole), 0, false)
 
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role FROM users WHERE id = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role, token_version, disabled FROM users WHERE id = $1`)).
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -102,9 +114,9 @@ func TestUserRepository_GetByID(t *testing.T) {
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role FROM users WHERE id = $1`)).
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role, token_version, disabled FROM users WHERE id = $1`)).
 			WithArgs(userID).
-			WillReturnError(sql.ErrNoRows)
+			WillReturnError(pgx.ErrNoRows)
 
 		user, err := repo.GetByID(userID)
 		require.Error(t, err)
@@ -113,11 +125,11 @@ func TestUserRepository_GetByID(t *testing.T) {
 }
 
 func TestUserRepository_Update(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewUserRepository(db)
+	repo := repository.NewUserRepository(mock)
 	user := &models.User{
 		ID:           uuid.New(),
 		Email:        "updated@example.com",
@@ -126,18 +138,18 @@ func TestUserRepository_Update(t *testing.T) {
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email = $1, password_hash = $2, role = $3 WHERE id = $4`)).
-			WithArgs(user.Email, user.PasswordHash, user.Role, user.ID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email = $1, password_hash = $2, role = $3, token_version = $4, disabled = $5 WHERE id = $6`)).
+			WithArgs(user.Email, user.PasswordHash, user.Role, user.TokenVersion, user.Disabled, user.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 		err := repo.Update(user)
 		require.NoError(t, err)
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email = $1, password_hash = $2, role = $3 WHERE id = $4`)).
-			WithArgs(user.Email, user.PasswordHash, user.Role, user.ID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET email = $1, password_hash = $2, role = $3, token_version = $4, disabled = $5 WHERE id = $6`)).
+			WithArgs(user.Email, user.PasswordHash, user.Role, user.TokenVersion, user.Disabled, user.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 
 		err := repo.Update(user)
 		require.NoError(t, err) 
@@ -145,17 +157,17 @@ func TestUserRepository_Update(t *testing.T) {
 }
 
 func TestUserRepository_Delete(t *testing.T) {
-	db, mock, err := sqlmock.New()
+	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
-	defer db.Close()
+	defer mock.Close()
 
-	repo := repository.NewUserRepository(db)
+	repo := repository.NewUserRepository(mock)
 	userID := uuid.New()
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM users WHERE id = $1`)).
 			WithArgs(userID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
 
 		err := repo.Delete(userID)
 		require.NoError(t, err)
@@ -164,7 +176,7 @@ func TestUserRepository_Delete(t *testing.T) {
 	t.Run("Not Found", func(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM users WHERE id = $1`)).
 			WithArgs(userID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
 
 		err := repo.Delete(userID)
 		require.NoError(t, err)
@@ -178,4 +190,39 @@ func TestUserRepository_Delete(t *testing.T) {
 		err := repo.Delete(userID)
 		require.Error(t, err)
 	})
-}
\ No newline at end of file
+}
+func TestUserRepository_List(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewUserRepository(mock)
+	columns := []string{"id", "email", "password_hash", "role", "token_version", "disabled"}
+
+	t.Run("All", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role, token_version, disabled FROM users ORDER BY email LIMIT $1 OFFSET $2`)).
+			WithArgs(20, 0).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(uuid.New(), "a@example.com", "hash", string(models.ModeratorRole), 0, false).
+				AddRow(uuid.New(), "b@example.com", "hash", string(models.EmployeeRole), 3, true))
+
+		users, err := repo.List(models.UserFilter{Limit: 20})
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.True(t, users[1].Disabled)
+		assert.Equal(t, 3, users[1].TokenVersion)
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		role := models.EmployeeRole
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role, token_version, disabled FROM users WHERE role = $1 AND email ILIKE $2 ORDER BY email LIMIT $3 OFFSET $4`)).
+			WithArgs("employee", `%ivan\_%`, 10, 10).
+			WillReturnRows(pgxmock.NewRows(columns))
+
+		users, err := repo.List(models.UserFilter{Role: &role, Email: "ivan_", Offset: 10, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository_FanOutPendingEvents(t *testing.T) {
+	pendingQuery := regexp.QuoteMeta(`SELECT id, event_id, event_type, pvz_id, payload, created_at FROM outbox ` +
+		`WHERE webhooks_enqueued_at IS NULL ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED`)
+
+	t.Run("Enqueues And Marks In One Transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewWebhookRepository(db)
+		eventID := uuid.New()
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(pendingQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "event_type", "pvz_id", "payload", "created_at"}).
+				AddRow(int64(7), eventID, string(models.ReceptionClosed), pvzID, []byte(`{}`), time.Now()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhook_deliveries (id,subscription_id,event_id,event_type,payload,status,next_attempt_at,created_at) `+
+			`SELECT gen_random_uuid(), s.id, $1, $2, $3::jsonb, $4, $5, $6 FROM webhook_subscriptions s `+
+			`WHERE $7 = ANY(s.event_types) AND (s.pvz_id IS NULL OR s.pvz_id = $8) AND (s.city IS NULL OR s.city = (SELECT city FROM pvz WHERE id = $9)) `+
+			`ON CONFLICT (subscription_id, event_id) DO NOTHING`)).
+			WithArgs(eventID, models.ReceptionClosed, sqlmock.AnyArg(), models.WebhookPending, sqlmock.AnyArg(), sqlmock.AnyArg(),
+				models.ReceptionClosed, pvzID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE outbox SET webhooks_enqueued_at = $1 WHERE id IN ($2)`)).
+			WithArgs(sqlmock.AnyArg(), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		count, err := repo.FanOutPendingEvents(10)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback On Enqueue Error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := repository.NewWebhookRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(pendingQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "event_type", "pvz_id", "payload", "created_at"}).
+				AddRow(int64(7), uuid.New(), string(models.ReceptionClosed), uuid.New(), []byte(`{}`), time.Now()))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhook_deliveries`)).WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		_, err = repo.FanOutPendingEvents(10)
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWebhookRepository_ClaimDueDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	now := time.Now()
+	leaseUntil := now.Add(time.Minute)
+	deliveryID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE webhook_deliveries d SET next_attempt_at = $1 FROM webhook_subscriptions s `+
+		`WHERE s.id = d.subscription_id AND d.id IN (SELECT id FROM webhook_deliveries WHERE status = $2 AND next_attempt_at <= $3 ORDER BY next_attempt_at LIMIT 10 FOR UPDATE SKIP LOCKED) `+
+		`RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempt_count, d.created_at, s.url, s.secret`)).
+		WithArgs(leaseUntil, models.WebhookPending, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempt_count", "created_at", "url", "secret"}).
+			AddRow(deliveryID, uuid.New(), uuid.New(), string(models.ReceptionClosed), []byte(`{}`), string(models.WebhookPending), 2, now, "https://partner.example", "secret"))
+
+	dispatches, err := repo.ClaimDueDeliveries(now, leaseUntil, 10)
+
+	require.NoError(t, err)
+	require.Len(t, dispatches, 1)
+	assert.Equal(t, deliveryID, dispatches[0].Delivery.ID)
+	assert.Equal(t, 2, dispatches[0].Delivery.AttemptCount)
+	assert.Equal(t, "https://partner.example", dispatches[0].URL)
+	assert.Equal(t, "secret", dispatches[0].Secret)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ListDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	subscriptionID := uuid.New()
+	deliveryID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, subscription_id, event_id, event_type, payload, status, attempt_count, next_attempt_at, delivered_at, created_at FROM webhook_deliveries WHERE subscription_id = $1 ORDER BY created_at DESC LIMIT 20`)).
+		WithArgs(subscriptionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempt_count", "next_attempt_at", "delivered_at", "created_at"}).
+			AddRow(deliveryID, subscriptionID, uuid.New(), string(models.ReceptionClosed), []byte(`{}`), string(models.WebhookPending), 2, now, nil, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT delivery_id, number, attempted_at, status_code, error, duration_ms FROM webhook_delivery_attempts WHERE delivery_id IN ($1) ORDER BY delivery_id, number`)).
+		WithArgs(deliveryID).
+		WillReturnRows(sqlmock.NewRows([]string{"delivery_id", "number", "attempted_at", "status_code", "error", "duration_ms"}).
+			AddRow(deliveryID, 1, now, 500, "unexpected status 500", 120).
+			AddRow(deliveryID, 2, now, nil, "connection refused", 3))
+
+	deliveries, err := repo.ListDeliveries(subscriptionID, 20)
+
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Len(t, deliveries[0].Attempts, 2)
+	assert.Equal(t, 500, *deliveries[0].Attempts[0].StatusCode)
+	assert.Nil(t, deliveries[0].Attempts[1].StatusCode)
+	assert.Equal(t, "connection refused", deliveries[0].Attempts[1].Error)
+	assert.NotNil(t, deliveries[0].NextAttemptAt)
+	assert.Nil(t, deliveries[0].DeliveredAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_RecordAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookRepository(db)
+	deliveredAt := time.Now()
+	statusCode := 200
+	delivery := &models.WebhookDelivery{
+		ID:           uuid.New(),
+		Status:       models.WebhookDelivered,
+		AttemptCount: 1,
+		DeliveredAt:  &deliveredAt,
+	}
+	attempt := models.WebhookAttempt{Number: 1, AttemptedAt: deliveredAt, StatusCode: &statusCode, DurationMs: 42}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhook_delivery_attempts (delivery_id,number,attempted_at,status_code,error,duration_ms) VALUES ($1,$2,$3,$4,$5,$6)`)).
+		WithArgs(delivery.ID, 1, deliveredAt, &statusCode, nil, int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE webhook_deliveries SET status = $1, attempt_count = $2, next_attempt_at = $3, delivered_at = $4 WHERE id = $5`)).
+		WithArgs(models.WebhookDelivered, 1, nil, &deliveredAt, delivery.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.RecordAttempt(delivery, attempt))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,7 +3,7 @@ package repository
 import (
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
-	"database/sql"
+	"context"
 	"strings"
 
 	sq "github.com/Masterminds/squirrel"
@@ -18,98 +18,106 @@ type UserRepositoryInterface interface {
 	GetByID(id uuid.UUID) (*models.User, error)
 	Update(user *models.User) error
 	Delete(id uuid.UUID) error
+	List(filter models.UserFilter) ([]*models.User, error)
 }
 
+const (
+	userColumnsSQL = `id, email, password_hash, role, token_version, disabled`
+
+	createUserSQL     = `INSERT INTO users (id, email, password_hash, role) VALUES ($1, $2, $3, $4)`
+	getUserByEmailSQL = `SELECT ` + userColumnsSQL + ` FROM users WHERE email = $1`
+	getUserByIDSQL    = `SELECT ` + userColumnsSQL + ` FROM users WHERE id = $1`
+	updateUserSQL     = `UPDATE users SET email = $1, password_hash = $2, role = $3, token_version = $4, disabled = $5 WHERE id = $6`
+	deleteUserSQL     = `DELETE FROM users WHERE id = $1`
+)
+
 type UserRepository struct {
-	db *sql.DB
+	db PgxPool
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db PgxPool) *UserRepository {
 	return &UserRepository{db: db}
 }
 
 func (r *UserRepository) Create(user *models.User) error {
-	query := psql.Insert("users").
-		Columns("id", "email", "password_hash", "role").
-		Values(user.ID, user.Email, user.PasswordHash, user.Role)
-
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return err
-	}
-
-	_, err = r.db.Exec(sql, args...)
-	if err != nil {
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			return apperrors.ErrUserAlreadyExists
-		}
-		return err
+	_, err := r.db.Exec(context.Background(), createUserSQL, user.ID, user.Email, user.PasswordHash, user.Role)
+	if isUniqueViolation(err) {
+		return apperrors.ErrUserAlreadyExists
 	}
-	return nil
+	return err
 }
 
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
-	query := psql.Select("id", "email", "password_hash", "role").
-		From("users").
-		Where(sq.Eq{"email": email})
+	return scanUser(r.db.QueryRow(context.Background(), getUserByEmailSQL, email))
+}
 
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return nil, err
-	}
+func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
+	return scanUser(r.db.QueryRow(context.Background(), getUserByIDSQL, id))
+}
 
+func scanUser(row rowScanner) (*models.User, error) {
 	user := &models.User{}
-	err = r.db.QueryRow(sql, args...).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role)
+	err := row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.TokenVersion, &user.Disabled)
 	if err != nil {
-		return nil, err
+		return nil, noRows(err)
 	}
 
 	return user, nil
 }
 
-func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
-	query := psql.Select("id", "email", "password_hash", "role").
+func (r *UserRepository) Update(user *models.User) error {
+	_, err := r.db.Exec(context.Background(), updateUserSQL,
+		user.Email, user.PasswordHash, user.Role, user.TokenVersion, user.Disabled, user.ID)
+	return err
+}
+
+func (r *UserRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(context.Background(), deleteUserSQL, id)
+	return err
+}
+
+func (r *UserRepository) List(filter models.UserFilter) ([]*models.User, error) {
+	query := psql.Select(userColumnsSQL).
 		From("users").
-		Where(sq.Eq{"id": id})
+		OrderBy("email")
+
+	if filter.Role != nil {
+		query = query.Where(sq.Eq{"role": string(*filter.Role)})
+	}
+	if filter.Email != "" {
+		query = query.Where(sq.ILike{"email": "%" + escapeLike(filter.Email) + "%"})
+	}
 
-	sql, args, err := query.ToSql()
+	// Лимит и смещение параметрами, чтобы страницы не порождали разные подготовленные запросы
+	query = query.Suffix("LIMIT ? OFFSET ?", filter.Limit, filter.Offset)
+
+	sqlQuery, args, err := query.ToSql()
 	if err != nil {
 		return nil, err
 	}
 
-	user := &models.User{}
-	err = r.db.QueryRow(sql, args...).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role)
+	rows, err := r.db.Query(context.Background(), sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return user, nil
-}
-
-func (r *UserRepository) Update(user *models.User) error {
-	query := psql.Update("users").
-		Set("email", user.Email).
-		Set("password_hash", user.PasswordHash).
-		Set("role", user.Role).
-		Where(sq.Eq{"id": user.ID})
-
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return err
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.TokenVersion, &user.Disabled); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	_, err = r.db.Exec(sql, args...)
-	return err
+	return users, nil
 }
 
-func (r *UserRepository) Delete(id uuid.UUID) error {
-	query := psql.Delete("users").Where(sq.Eq{"id": id})
-
-	sql, args, err := query.ToSql()
-	if err != nil {
-		return err
-	}
-
-	_, err = r.db.Exec(sql, args...)
-	return err
+// Экранирует спецсимволы LIKE, чтобы они в поиске совпадали буквально
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type WebhookRepositoryInterface interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	GetSubscription(id uuid.UUID) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]*models.WebhookSubscription, error)
+	DeleteSubscription(id uuid.UUID) error
+	ListDeliveries(subscriptionID uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+	FanOutPendingEvents(limit int) (int, error)
+	ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.WebhookDispatch, error)
+	RecordAttempt(delivery *models.WebhookDelivery, attempt models.WebhookAttempt) error
+}
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+var webhookSubscriptionColumns = []string{"id", "url", "secret", "event_types", "pvz_id", "city", "created_at", "created_by"}
+
+func (r *WebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	eventTypes := make(pq.StringArray, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	query := psql.Insert("webhook_subscriptions").
+		Columns(webhookSubscriptionColumns...).
+		Values(subscription.ID, subscription.URL, subscription.Secret, eventTypes,
+			subscription.PVZID, subscription.City, subscription.CreatedAt, subscription.CreatedBy)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(sqlQuery, args...)
+	return err
+}
+
+func (r *WebhookRepository) GetSubscription(id uuid.UUID) (*models.WebhookSubscription, error) {
+	query := psql.Select(webhookSubscriptionColumns...).
+		From("webhook_subscriptions").
+		Where(sq.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhookSubscription(r.db.QueryRow(sqlQuery, args...))
+}
+
+func (r *WebhookRepository) ListSubscriptions() ([]*models.WebhookSubscription, error) {
+	query := psql.Select(webhookSubscriptionColumns...).
+		From("webhook_subscriptions").
+		OrderBy("created_at")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]*models.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// Доставки и попытки удаляются каскадно
+func (r *WebhookRepository) DeleteSubscription(id uuid.UUID) error {
+	query := psql.Delete("webhook_subscriptions").Where(sq.Eq{"id": id})
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Последние доставки подписки вместе с историей попыток
+func (r *WebhookRepository) ListDeliveries(subscriptionID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	query := psql.Select("id", "subscription_id", "event_id", "event_type", "payload", "status",
+		"attempt_count", "next_attempt_at", "delivered_at", "created_at").
+		From("webhook_deliveries").
+		Where(sq.Eq{"subscription_id": subscriptionID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit))
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	byID := make(map[uuid.UUID]*models.WebhookDelivery)
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{Attempts: make([]models.WebhookAttempt, 0)}
+		var payload []byte
+		var nextAttemptAt, deliveredAt nullTime
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &payload,
+			&delivery.Status, &delivery.AttemptCount, &nextAttemptAt, &deliveredAt, &delivery.CreatedAt); err != nil {
+			return nil, err
+		}
+		delivery.Payload = json.RawMessage(payload)
+		delivery.NextAttemptAt = nextAttemptAt.ptr()
+		delivery.DeliveredAt = deliveredAt.ptr()
+
+		deliveries = append(deliveries, delivery)
+		byID[delivery.ID] = delivery
+		ids = append(ids, delivery.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return deliveries, nil
+	}
+
+	if err := r.loadAttempts(ids, byID); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) loadAttempts(ids []uuid.UUID, byID map[uuid.UUID]*models.WebhookDelivery) error {
+	query := psql.Select("delivery_id", "number", "attempted_at", "status_code", "error", "duration_ms").
+		From("webhook_delivery_attempts").
+		Where(sq.Eq{"delivery_id": ids}).
+		OrderBy("delivery_id", "number")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var deliveryID uuid.UUID
+		var attempt models.WebhookAttempt
+		var statusCode sql.NullInt64
+		var attemptErr sql.NullString
+		if err := rows.Scan(&deliveryID, &attempt.Number, &attempt.AttemptedAt, &statusCode, &attemptErr, &attempt.DurationMs); err != nil {
+			return err
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			attempt.StatusCode = &code
+		}
+		attempt.Error = attemptErr.String
+
+		if delivery, ok := byID[deliveryID]; ok {
+			delivery.Attempts = append(delivery.Attempts, attempt)
+		}
+	}
+
+	return rows.Err()
+}
+
+// Раскладывает еще не обработанные события outbox по подходящим подпискам. Выборка, создание доставок
+// и отметка событий выполняются в одной транзакции, поэтому каждое событие раскладывается ровно один раз
+// и не зависит от доставки событий внешнему публикатору. Возвращает количество обработанных событий
+func (r *WebhookRepository) FanOutPendingEvents(limit int) (int, error) {
+	pending := psql.Select("id", "event_id", "event_type", "pvz_id", "payload", "created_at").
+		From("outbox").
+		Where(sq.Eq{"webhooks_enqueued_at": nil}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sqlQuery, args, err := pending.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(sqlQuery, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		event := &models.OutboxEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.EventID, &event.Type, &event.PVZID, &payload, &event.CreatedAt); err != nil {
+			return 0, err
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+		ids = append(ids, event.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	for _, event := range events {
+		if err := enqueueDeliveries(tx, event); err != nil {
+			return 0, err
+		}
+	}
+
+	mark := psql.Update("outbox").
+		Set("webhooks_enqueued_at", time.Now()).
+		Where(sq.Eq{"id": ids})
+
+	markSQL, markArgs, err := mark.ToSql()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(markSQL, markArgs...); err != nil {
+		return 0, err
+	}
+
+	return len(events), tx.Commit()
+}
+
+// Создает доставки для всех подходящих подписок; уже созданные доставки события не дублируются
+func enqueueDeliveries(tx *sql.Tx, event *models.OutboxEvent) error {
+	envelope, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	source := sq.Select().
+		Column("gen_random_uuid()").
+		Column("s.id").
+		Column("?", event.EventID).
+		Column("?", event.Type).
+		Column("?::jsonb", string(envelope)).
+		Column("?", models.WebhookPending).
+		Column("?", now).
+		Column("?", now).
+		From("webhook_subscriptions s").
+		Where("? = ANY(s.event_types)", event.Type).
+		Where(sq.Or{sq.Eq{"s.pvz_id": nil}, sq.Eq{"s.pvz_id": event.PVZID}}).
+		Where(sq.Or{sq.Eq{"s.city": nil}, sq.Expr("s.city = (SELECT city FROM pvz WHERE id = ?)", event.PVZID)})
+
+	query := psql.Insert("webhook_deliveries").
+		Columns("id", "subscription_id", "event_id", "event_type", "payload", "status", "next_attempt_at", "created_at").
+		Select(source).
+		Suffix("ON CONFLICT (subscription_id, event_id) DO NOTHING")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(sqlQuery, args...)
+	return err
+}
+
+// Захватывает готовые к отправке доставки, откладывая их до leaseUntil. Если процесс упадет
+// во время отправки, доставка снова станет доступной после истечения аренды
+func (r *WebhookRepository) ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.WebhookDispatch, error) {
+	due := sq.Select("id").
+		From("webhook_deliveries").
+		Where(sq.Eq{"status": models.WebhookPending}).
+		Where(sq.LtOrEq{"next_attempt_at": now}).
+		OrderBy("next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	dueSQL, dueArgs, err := due.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := psql.Update("webhook_deliveries d").
+		Set("next_attempt_at", leaseUntil).
+		From("webhook_subscriptions s").
+		Where("s.id = d.subscription_id").
+		Where("d.id IN ("+dueSQL+")", dueArgs...).
+		Suffix("RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempt_count, d.created_at, s.url, s.secret")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dispatches := make([]*models.WebhookDispatch, 0)
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{}
+		dispatch := &models.WebhookDispatch{Delivery: delivery}
+		var payload []byte
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &payload,
+			&delivery.Status, &delivery.AttemptCount, &delivery.CreatedAt, &dispatch.URL, &dispatch.Secret); err != nil {
+			return nil, err
+		}
+		delivery.Payload = json.RawMessage(payload)
+		dispatches = append(dispatches, dispatch)
+	}
+
+	return dispatches, rows.Err()
+}
+
+// Сохраняет попытку и новое состояние доставки (статус, счетчик, время следующей попытки)
+func (r *WebhookRepository) RecordAttempt(delivery *models.WebhookDelivery, attempt models.WebhookAttempt) error {
+	var attemptErr any
+	if attempt.Error != "" {
+		attemptErr = attempt.Error
+	}
+
+	insert := psql.Insert("webhook_delivery_attempts").
+		Columns("delivery_id", "number", "attempted_at", "status_code", "error", "duration_ms").
+		Values(delivery.ID, attempt.Number, attempt.AttemptedAt, attempt.StatusCode, attemptErr, attempt.DurationMs)
+
+	update := psql.Update("webhook_deliveries").
+		Set("status", delivery.Status).
+		Set("attempt_count", delivery.AttemptCount).
+		Set("next_attempt_at", delivery.NextAttemptAt).
+		Set("delivered_at", delivery.DeliveredAt).
+		Where(sq.Eq{"id": delivery.ID})
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []sq.Sqlizer{insert, update} {
+		sqlQuery, args, err := query.ToSql()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqlQuery, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+	var eventTypes pq.StringArray
+	var pvzID uuid.NullUUID
+	var city sql.NullString
+	var createdBy uuid.NullUUID
+
+	err := row.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes,
+		&pvzID, &city, &subscription.CreatedAt, &createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.EventTypes = make([]models.EventType, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, models.EventType(eventType))
+	}
+	if pvzID.Valid {
+		subscription.PVZID = &pvzID.UUID
+	}
+	if city.Valid {
+		value := models.City(city.String)
+		subscription.City = &value
+	}
+	if createdBy.Valid {
+		subscription.CreatedBy = &createdBy.UUID
+	}
+
+	return subscription, nil
+}
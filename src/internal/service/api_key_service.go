@@ -0,0 +1,199 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix = "pvzk_"
+	// Первые символы ключа хранятся открыто, чтобы ключ можно было узнать в списке
+	apiKeyVisiblePrefixLength = len(apiKeyPrefix) + 8
+	// last_used_at обновляется не чаще раза в минуту, чтобы не писать в БД на каждый запрос
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyServiceInterface interface {
+	CreateKey(input models.APIKey) (*models.APIKey, string, error)
+	ListKeys() ([]*models.APIKey, error)
+	RevokeKey(id, actorID uuid.UUID) error
+	AuthenticateAPIKey(rawKey string) (*models.APIKey, error)
+}
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepositoryInterface
+	pvzRepo    repository.PVZRepositoryInterface
+	auditRepo  repository.AuditRepositoryInterface
+	now        func() time.Time
+}
+
+type APIKeyServiceOption func(*APIKeyService)
+
+func WithAPIKeyClock(now func() time.Time) APIKeyServiceOption {
+	return func(s *APIKeyService) {
+		s.now = now
+	}
+}
+
+// auditRepo может быть nil: тогда создание и отзыв ключей не записываются в журнал
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepositoryInterface, pvzRepo repository.PVZRepositoryInterface, auditRepo repository.AuditRepositoryInterface, opts ...APIKeyServiceOption) APIKeyServiceInterface {
+	s := &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		pvzRepo:    pvzRepo,
+		auditRepo:  auditRepo,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Возвращает сохраненный ключ и сам ключ в открытом виде; второй раз его получить нельзя
+func (s *APIKeyService) CreateKey(input models.APIKey) (*models.APIKey, string, error) {
+	now := s.now()
+
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, "", apperrors.ErrInvalidAPIKeyScope
+	}
+	if !input.Role.IsValid() {
+		return nil, "", apperrors.ErrInvalidRole
+	}
+	// Список прав может только сузить права роли
+	for _, permission := range input.Permissions {
+		if !permission.IsValid() || !input.Role.Can(permission) {
+			return nil, "", apperrors.ErrInvalidAPIKeyScope
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, "", apperrors.ErrInvalidAPIKeyScope
+	}
+	for _, pvzID := range input.PVZIDs {
+		if _, err := s.pvzRepo.GetByID(pvzID); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, "", apperrors.ErrPVZNotFound
+			}
+			return nil, "", err
+		}
+	}
+
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		ID:          uuid.New(),
+		Name:        strings.TrimSpace(input.Name),
+		Prefix:      rawKey[:apiKeyVisiblePrefixLength],
+		KeyHash:     hashAPIKey(rawKey),
+		Role:        input.Role,
+		Permissions: input.Permissions,
+		PVZIDs:      input.PVZIDs,
+		ExpiresAt:   input.ExpiresAt,
+		CreatedAt:   now,
+		CreatedBy:   input.CreatedBy,
+	}
+	if key.Permissions == nil {
+		key.Permissions = make([]models.Permission, 0)
+	}
+	if key.PVZIDs == nil {
+		key.PVZIDs = make([]uuid.UUID, 0)
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	s.audit(models.AuditAPIKeyCreated, key, key.CreatedBy)
+	return key, rawKey, nil
+}
+
+func (s *APIKeyService) ListKeys() ([]*models.APIKey, error) {
+	return s.apiKeyRepo.List()
+}
+
+func (s *APIKeyService) RevokeKey(id, actorID uuid.UUID) error {
+	err := s.apiKeyRepo.Revoke(id, s.now())
+	if err == sql.ErrNoRows {
+		return apperrors.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.audit(models.AuditAPIKeyRevoked, &models.APIKey{ID: id}, actorRef(actorID))
+	return nil
+}
+
+// Неизвестный, отозванный и просроченный ключ неотличимы для клиента: все дают ErrInvalidAPIKey
+func (s *APIKeyService) AuthenticateAPIKey(rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(hashAPIKey(rawKey))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !key.Active(now) {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+			slog.Error("ошибка обновления времени использования API-ключа", "api_key_id", key.ID, "error", err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+func (s *APIKeyService) audit(action models.AuditAction, key *models.APIKey, actorID *uuid.UUID) {
+	if s.auditRepo == nil {
+		return
+	}
+
+	details := map[string]any{"apiKeyId": key.ID}
+	if key.Name != "" {
+		details["name"] = key.Name
+		details["role"] = key.Role
+	}
+	encoded, _ := json.Marshal(details)
+
+	entry := &models.AuditEntry{Action: action, ActorID: actorID, Details: encoded, CreatedAt: s.now()}
+	if err := s.auditRepo.Record(entry); err != nil {
+		slog.Error("ошибка записи в журнал аудита", "action", action, "error", err)
+	}
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
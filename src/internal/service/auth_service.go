@@ -3,8 +3,20 @@ package service
 import (
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/notify"
 	"avito-backend/src/internal/repository"
 	"avito-backend/src/pkg/jwt"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -13,19 +25,82 @@ import (
 type AuthServiceInterface interface {
 	GenerateToken(role string) (string, error)
 	Register(email, password, role string) (*models.User, error)
-	Login(email, password string) (string, error)
+	Login(email, password, clientIP string) (string, error)
+	Unlock(email, clientIP string, actorID uuid.UUID) error
+	ChangePassword(userID uuid.UUID, oldPassword, newPassword string) (string, error)
+	RequestPasswordReset(email string) error
+	ResetPassword(token, newPassword string) error
+	ValidateSession(userID string, tokenVersion int) error
+}
+
+// Пороги блокировки входа; нулевой порог отключает подсчет по этому признаку
+type LoginProtection struct {
+	MaxFailuresPerEmail int
+	MaxFailuresPerIP    int
+	// Неудачи старше окна не учитываются
+	FailureWindow time.Duration
+	// Первая блокировка длится LockoutBase, каждая следующая вдвое дольше, но не больше LockoutMax
+	LockoutBase time.Duration
+	LockoutMax  time.Duration
 }
 
 type AuthService struct {
 	userRepo     repository.UserRepositoryInterface
 	tokenManager *jwt.TokenManager
+	now          func() time.Time
+
+	attemptsRepo repository.LoginAttemptsRepositoryInterface
+	auditRepo    repository.AuditRepositoryInterface
+	protection   LoginProtection
+
+	passwordPolicy PasswordPolicy
+	resetRepo      repository.PasswordResetRepositoryInterface
+	notifier       notify.Notifier
+	resetTokenTTL  time.Duration
+}
+
+type AuthServiceOption func(*AuthService)
+
+func WithAuthClock(now func() time.Time) AuthServiceOption {
+	return func(s *AuthService) {
+		s.now = now
+	}
 }
 
-func NewAuthService(userRepo repository.UserRepositoryInterface, tokenManager *jwt.TokenManager) AuthServiceInterface {
-	return &AuthService{
+// Включает учет неудачных попыток входа и блокировку по email и IP
+func WithLoginProtection(attemptsRepo repository.LoginAttemptsRepositoryInterface, auditRepo repository.AuditRepositoryInterface, protection LoginProtection) AuthServiceOption {
+	return func(s *AuthService) {
+		s.attemptsRepo = attemptsRepo
+		s.auditRepo = auditRepo
+		s.protection = protection
+	}
+}
+
+func WithPasswordPolicy(policy PasswordPolicy) AuthServiceOption {
+	return func(s *AuthService) {
+		s.passwordPolicy = policy
+	}
+}
+
+// Включает сброс пароля по одноразовому токену, который отправляется через notifier
+func WithPasswordReset(resetRepo repository.PasswordResetRepositoryInterface, notifier notify.Notifier, ttl time.Duration) AuthServiceOption {
+	return func(s *AuthService) {
+		s.resetRepo = resetRepo
+		s.notifier = notifier
+		s.resetTokenTTL = ttl
+	}
+}
+
+func NewAuthService(userRepo repository.UserRepositoryInterface, tokenManager *jwt.TokenManager, opts ...AuthServiceOption) AuthServiceInterface {
+	s := &AuthService{
 		userRepo:     userRepo,
 		tokenManager: tokenManager,
+		now:          time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *AuthService) validateRole(role string) error {
@@ -45,6 +120,10 @@ func (s *AuthService) Register(email, password, role string) (*models.User, erro
 		return nil, apperrors.ErrUserAlreadyExists
 	}
 
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		return nil, err
+	}
+
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -64,17 +143,348 @@ func (s *AuthService) Register(email, password, role string) (*models.User, erro
 	return user, nil
 }
 
-func (s *AuthService) Login(email, password string) (string, error) {
+// Заблокированный вход возвращает ErrAccountLocked без проверки пароля. Счетчики ведутся и для
+// несуществующих email, поэтому по блокировке нельзя узнать, зарегистрирован ли пользователь
+func (s *AuthService) Login(email, password, clientIP string) (string, error) {
+	emailKey := normalizeEmail(email)
+
+	locked, err := s.isLocked(emailKey, clientIP)
+	if err != nil {
+		return "", err
+	}
+	if locked {
+		return "", apperrors.ErrAccountLocked
+	}
+
 	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
+		// Сравниваем с фиктивным хешем, чтобы время ответа не выдавало отсутствие пользователя
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		s.registerFailure(emailKey, clientIP)
 		return "", apperrors.ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.registerFailure(emailKey, clientIP)
 		return "", apperrors.ErrInvalidCredentials
 	}
 
-	return s.tokenManager.GenerateToken(user.Role)
+	// Проверяем после пароля, чтобы отключение не выдавало существование учетной записи
+	if user.Disabled {
+		return "", apperrors.ErrAccountDisabled
+	}
+
+	// Счетчик по IP не сбрасываем: иначе один известный пароль позволял бы перебирать остальные
+	if s.attemptsRepo != nil && s.protection.MaxFailuresPerEmail > 0 {
+		if err := s.attemptsRepo.DeleteAttempts(models.LoginScopeEmail, emailKey); err != nil && err != sql.ErrNoRows {
+			slog.Error("ошибка сброса счетчика попыток входа", "error", err)
+		}
+	}
+
+	return s.tokenManager.GenerateUserToken(user.ID.String(), user.Role, user.TokenVersion)
+}
+
+// Смена пароля отзывает все выданные ранее токены, поэтому вызывающему возвращается новый
+func (s *AuthService) ChangePassword(userID uuid.UUID, oldPassword, newPassword string) (string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err == sql.ErrNoRows {
+		return "", apperrors.ErrTokenRevoked
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)); err != nil {
+		return "", apperrors.ErrInvalidCredentials
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return "", err
+	}
+	s.audit(&models.AuditEntry{Action: models.AuditPasswordChanged, ActorID: &user.ID, UserID: &user.ID, Email: user.Email})
+
+	return s.tokenManager.GenerateUserToken(user.ID.String(), user.Role, user.TokenVersion)
+}
+
+// Ответ не зависит от того, существует ли пользователь: иначе по нему можно перебирать email
+func (s *AuthService) RequestPasswordReset(email string) error {
+	if s.resetRepo == nil {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if err := s.resetRepo.CreateToken(&models.PasswordResetToken{
+		TokenHash: hashResetToken(token),
+		UserID:    user.ID,
+		ExpiresAt: now.Add(s.resetTokenTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	message := notify.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body:    fmt.Sprintf("Токен для сброса пароля: %s\nДействует до %s", token, now.Add(s.resetTokenTTL).Format(time.RFC3339)),
+	}
+	if err := s.notifier.Send(context.Background(), message); err != nil {
+		slog.Error("ошибка отправки токена сброса пароля", "error", err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	if s.resetRepo == nil || token == "" {
+		return apperrors.ErrInvalidResetToken
+	}
+
+	// Проверяем пароль до использования токена, чтобы слабый пароль не сжигал его
+	if err := s.passwordPolicy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	userID, err := s.resetRepo.ConsumeToken(hashResetToken(token), s.now())
+	if err == sql.ErrNoRows {
+		return apperrors.ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return err
+	}
+	s.audit(&models.AuditEntry{Action: models.AuditPasswordReset, UserID: &user.ID, Email: user.Email})
+
+	return nil
+}
+
+// Токен отозван, если пользователь удален, сменил пароль или роль после выдачи токена.
+// Для отключенного пользователя возвращается ErrAccountDisabled
+func (s *AuthService) ValidateSession(userID string, tokenVersion int) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return apperrors.ErrTokenRevoked
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrTokenRevoked
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.Disabled {
+		return apperrors.ErrAccountDisabled
+	}
+	if user.TokenVersion != tokenVersion {
+		return apperrors.ErrTokenRevoked
+	}
+	return nil
+}
+
+func (s *AuthService) setPassword(user *models.User, password string) error {
+	if err := s.passwordPolicy.Validate(password); err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = string(passwordHash)
+	user.TokenVersion++
+	return s.userRepo.Update(user)
+}
+
+func generateResetToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuthService) Unlock(email, clientIP string, actorID uuid.UUID) error {
+	emailKey := normalizeEmail(email)
+	if emailKey == "" && clientIP == "" {
+		return apperrors.ErrValidationFailed
+	}
+	if s.attemptsRepo == nil {
+		return nil
+	}
+
+	if emailKey != "" {
+		if err := s.attemptsRepo.DeleteAttempts(models.LoginScopeEmail, emailKey); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		s.audit(&models.AuditEntry{Action: models.AuditAccountUnlocked, ActorID: actorRef(actorID), Email: emailKey})
+	}
+	if clientIP != "" {
+		if err := s.attemptsRepo.DeleteAttempts(models.LoginScopeIP, clientIP); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		s.audit(&models.AuditEntry{Action: models.AuditIPUnlocked, ActorID: actorRef(actorID), IP: clientIP})
+	}
+
+	return nil
+}
+
+func (s *AuthService) isLocked(emailKey, clientIP string) (bool, error) {
+	if s.attemptsRepo == nil {
+		return false, nil
+	}
+
+	now := s.now()
+	for _, target := range s.lockTargets(emailKey, clientIP) {
+		attempts, err := s.attemptsRepo.GetAttempts(target.scope, target.key)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if attempts.IsLocked(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Ошибки учета попыток не мешают ответу пользователю, они только логируются
+func (s *AuthService) registerFailure(emailKey, clientIP string) {
+	if s.attemptsRepo == nil {
+		return
+	}
+
+	now := s.now()
+	for _, target := range s.lockTargets(emailKey, clientIP) {
+		lockedNow := false
+		attempts, err := s.attemptsRepo.UpdateAttempts(target.scope, target.key, func(attempts *models.LoginAttempts) {
+			if attempts.LastFailedAt != nil && now.Sub(*attempts.LastFailedAt) > s.protection.FailureWindow {
+				attempts.FailedCount = 0
+			}
+			attempts.FailedCount++
+			attempts.LastFailedAt = &now
+
+			if attempts.FailedCount >= target.maxFailures {
+				attempts.Lockouts++
+				lockedUntil := now.Add(s.lockoutDuration(attempts.Lockouts))
+				attempts.LockedUntil = &lockedUntil
+				attempts.FailedCount = 0
+				lockedNow = true
+			}
+		})
+		if err != nil {
+			slog.Error("ошибка учета неудачной попытки входа", "scope", target.scope, "error", err)
+			continue
+		}
+		if !lockedNow {
+			continue
+		}
+
+		metrics.LoginLockoutsTotal.WithLabelValues(string(target.scope)).Inc()
+		slog.Warn("вход временно заблокирован", "scope", target.scope,
+			"locked_until", attempts.LockedUntil.Format(time.RFC3339), "lockouts", attempts.Lockouts)
+
+		entry := &models.AuditEntry{Action: models.AuditAccountLocked, Email: target.key}
+		if target.scope == models.LoginScopeIP {
+			entry = &models.AuditEntry{Action: models.AuditIPLocked, IP: target.key}
+		}
+		s.audit(entry)
+	}
+}
+
+type lockTarget struct {
+	scope       models.LoginScope
+	key         string
+	maxFailures int
+}
+
+func (s *AuthService) lockTargets(emailKey, clientIP string) []lockTarget {
+	targets := make([]lockTarget, 0, 2)
+	if emailKey != "" && s.protection.MaxFailuresPerEmail > 0 {
+		targets = append(targets, lockTarget{models.LoginScopeEmail, emailKey, s.protection.MaxFailuresPerEmail})
+	}
+	if clientIP != "" && s.protection.MaxFailuresPerIP > 0 {
+		targets = append(targets, lockTarget{models.LoginScopeIP, clientIP, s.protection.MaxFailuresPerIP})
+	}
+	return targets
+}
+
+func (s *AuthService) lockoutDuration(lockouts int) time.Duration {
+	limit := max(s.protection.LockoutMax, s.protection.LockoutBase)
+	duration := s.protection.LockoutBase
+	for i := 1; i < lockouts && duration < limit; i++ {
+		duration *= 2
+	}
+	return min(duration, limit)
+}
+
+func (s *AuthService) audit(entry *models.AuditEntry) {
+	if s.auditRepo == nil {
+		return
+	}
+	entry.CreatedAt = s.now()
+	if err := s.auditRepo.Record(entry); err != nil {
+		slog.Error("ошибка записи в журнал аудита", "action", entry.Action, "error", err)
+	}
+}
+
+// Для токенов из dummyLogin идентификатора нет
+func actorRef(actorID uuid.UUID) *uuid.UUID {
+	if actorID == uuid.Nil {
+		return nil
+	}
+	return &actorID
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
 }
 
 // Выведено в отдельную функцию чтобы не тащить tokenManager в AuthHandler
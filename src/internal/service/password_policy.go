@@ -0,0 +1,128 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const (
+	PasswordClassLower   = "lower"
+	PasswordClassUpper   = "upper"
+	PasswordClassDigit   = "digit"
+	PasswordClassSpecial = "special"
+
+	// bcrypt учитывает только первые 72 байта пароля
+	maxPasswordBytes = 72
+)
+
+var weakPasswordClassKeys = map[string]string{
+	PasswordClassLower:   apperrors.KeyWeakPasswordLower,
+	PasswordClassUpper:   apperrors.KeyWeakPasswordUpper,
+	PasswordClassDigit:   apperrors.KeyWeakPasswordDigit,
+	PasswordClassSpecial: apperrors.KeyWeakPasswordSpecial,
+}
+
+// Требования к паролю при регистрации и смене; нулевое значение проверяет только длину для bcrypt
+type PasswordPolicy struct {
+	MinLength       int
+	RequiredClasses []string
+	denylist        map[string]bool
+}
+
+// Собирает политику из конфигурации; denylistPath — файл с распространенными паролями, по одному в строке
+func NewPasswordPolicy(minLength int, requiredClasses []string, denylistPath string) (PasswordPolicy, error) {
+	if minLength < 0 || minLength > maxPasswordBytes {
+		return PasswordPolicy{}, fmt.Errorf("invalid password min length %d", minLength)
+	}
+	for _, class := range requiredClasses {
+		switch class {
+		case PasswordClassLower, PasswordClassUpper, PasswordClassDigit, PasswordClassSpecial:
+		default:
+			return PasswordPolicy{}, fmt.Errorf("unknown password character class %q", class)
+		}
+	}
+
+	policy := PasswordPolicy{MinLength: minLength, RequiredClasses: requiredClasses}
+	if denylistPath == "" {
+		return policy, nil
+	}
+
+	denylist, err := loadPasswordDenylist(denylistPath)
+	if err != nil {
+		return PasswordPolicy{}, err
+	}
+	policy.denylist = denylist
+	return policy, nil
+}
+
+// Возвращает ErrWeakPassword с описанием первого нарушенного требования
+func (p PasswordPolicy) Validate(password string) error {
+	if len(password) > maxPasswordBytes {
+		return weakPassword(apperrors.KeyWeakPasswordTooLong, map[string]any{"maxBytes": maxPasswordBytes})
+	}
+	if len([]rune(password)) < p.MinLength {
+		return weakPassword(apperrors.KeyWeakPasswordTooShort, map[string]any{"minLength": p.MinLength})
+	}
+
+	for _, class := range p.RequiredClasses {
+		if !containsClass(password, class) {
+			return weakPassword(weakPasswordClassKeys[class], map[string]any{"requiredClass": class})
+		}
+	}
+
+	if p.denylist[strings.ToLower(password)] {
+		return weakPassword(apperrors.KeyWeakPasswordCommon, nil)
+	}
+
+	return nil
+}
+
+// Сообщение для клиента содержит нарушенное требование; key — уточненный ключ weak_password из apperrors
+func weakPassword(key string, details map[string]any) error {
+	err := apperrors.ErrWeakPassword.WithMessageKey(key)
+	if details != nil {
+		err = err.WithDetails(details)
+	}
+	return err
+}
+
+func containsClass(password, class string) bool {
+	for _, r := range password {
+		switch {
+		case class == PasswordClassLower && unicode.IsLower(r),
+			class == PasswordClassUpper && unicode.IsUpper(r),
+			class == PasswordClassDigit && unicode.IsDigit(r),
+			class == PasswordClassSpecial && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r):
+			return true
+		}
+	}
+	return false
+}
+
+// Пустые строки и строки, начинающиеся с #, пропускаются
+func loadPasswordDenylist(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open password denylist: %w", err)
+	}
+	defer file.Close()
+
+	denylist := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read password denylist: %w", err)
+	}
+
+	return denylist, nil
+}
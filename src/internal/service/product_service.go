@@ -22,6 +22,17 @@ func (s *PVZService) CreateProduct(pvzID uuid.UUID, productType string) (*models
 	if err != nil {
 		return nil, err
 	}
+	if err := s.ensureOpen(pvzID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.loadSettings(pvzID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.AllowsProductType(pType) {
+		return nil, apperrors.ErrProductTypeNotAllowed
+	}
 
 	activeReception, err := s.pvzRepo.GetActiveReceptionByPVZID(pvzID)
 	if err != nil {
@@ -38,14 +49,15 @@ func (s *PVZService) CreateProduct(pvzID uuid.UUID, productType string) (*models
 		ReceptionID: activeReception.ID,
 	}
 
-	if err := s.pvzRepo.CreateProduct(product); err != nil {
+	// Лимит товаров проверяется в той же транзакции, что и вставка
+	if err := s.pvzRepo.CreateProduct(product, settings.MaxProductsPerReception); err != nil {
 		return nil, err
 	}
 
 	return product, nil
 }
 
-func (s *PVZService) DeleteLastProduct(pvzID uuid.UUID) error {
+func (s *PVZService) DeleteLastProduct(pvzID uuid.UUID, deletedBy uuid.UUID) error {
 	_, err := s.pvzRepo.GetByID(pvzID)
 	if err == sql.ErrNoRows {
 		return apperrors.ErrPVZNotFound
@@ -73,5 +85,5 @@ func (s *PVZService) DeleteLastProduct(pvzID uuid.UUID) error {
 		return apperrors.ErrNoProductsToDelete
 	}
 
-	return s.pvzRepo.DeleteProduct(lastProduct.ID)
+	return s.pvzRepo.DeleteProduct(lastProduct.ID, deletedBy)
 }
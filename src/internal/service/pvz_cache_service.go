@@ -0,0 +1,317 @@
+package service
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/cache"
+	"avito-backend/src/pkg/metrics"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	pvzListKeyPrefix = "pvz-list:"
+	// Новая приемка может добавить ПВЗ на страницу с фильтром по датам, который включает текущий момент
+	newReceptionsTag = "pvz-list:new-receptions"
+)
+
+type PVZCacheConfig struct {
+	// Сколько страница отдается из кеша без обращения к БД
+	TTL time.Duration
+	// Сколько после TTL или инвалидации страницу еще можно отдать, если БД недоступна
+	StaleTTL time.Duration
+	// Максимальное отставание реплик, с которых сервис читает список. Столько после инвалидации
+	// загруженная страница сохраняется устаревшей: реплика могла еще не получить изменение,
+	// и старые данные продержались бы в кеше весь TTL. 0 - чтение только из primary
+	ReplicaLag time.Duration
+	// По умолчанию time.Now
+	Now func() time.Time
+}
+
+// Кеш выдачи GET /pvz перед PVZService.GetPVZsWithReceptions. Страница инвалидируется, когда
+// меняются приемки или товары ее ПВЗ: изменения этого экземпляра - сразу, остальных - по событиям
+// outbox. Остальные вызовы передаются сервису без изменений
+type CachedPVZService struct {
+	PVZServiceInterface
+	store  cache.Store
+	config PVZCacheConfig
+	// Увеличивается при каждой инвалидации; страница, загруженная во время инвалидации, сразу
+	// сохраняется устаревшей, иначе она перезаписала бы инвалидацию старыми данными
+	generation atomic.Uint64
+	// Время последней инвалидации в наносекундах Unix, для окна ReplicaLag
+	invalidatedAt atomic.Int64
+	// Одновременные промахи по одной странице загружают ее из БД один раз
+	loads singleflight.Group
+}
+
+type pvzListLoad struct {
+	pvzs  []*models.PVZWithReceptions
+	value []byte
+}
+
+func NewCachedPVZService(service PVZServiceInterface, store cache.Store, config PVZCacheConfig) *CachedPVZService {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &CachedPVZService{
+		PVZServiceInterface: service,
+		store:               store,
+		config:              config,
+	}
+}
+
+func (s *CachedPVZService) GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error) {
+	key := pvzListKey(filter)
+	now := s.config.Now()
+
+	entry, found := s.store.Get(key)
+	age := now.Sub(entry.StoredAt)
+	if found && !entry.Stale && age < s.config.TTL {
+		if pvzs, err := decodePVZList(entry); err == nil {
+			metrics.PVZCacheRequestsTotal.WithLabelValues("hit").Inc()
+			return pvzs, nil
+		}
+	}
+	if found && age >= s.config.TTL+s.config.StaleTTL {
+		s.store.Delete(key)
+		found = false
+	}
+
+	// Запрос после инвалидации не присоединяется к загрузке, начатой до нее
+	generation := s.generation.Load()
+	result, err, shared := s.loads.Do(fmt.Sprintf("%s#%d", key, generation), func() (any, error) {
+		return s.load(key, filter, generation)
+	})
+	if err != nil {
+		// Невалидный фильтр в кеш не попадает, поэтому ошибка здесь - ошибка БД
+		if found {
+			if stale, decodeErr := decodePVZList(entry); decodeErr == nil {
+				metrics.PVZCacheRequestsTotal.WithLabelValues("stale").Inc()
+				slog.Warn("БД недоступна, список ПВЗ отдан из кеша", "age", age.String(), "error", err)
+				return stale, nil
+			}
+		}
+		return nil, err
+	}
+	metrics.PVZCacheRequestsTotal.WithLabelValues("miss").Inc()
+
+	// Каждый из дождавшихся общей загрузки получает свою копию, как при попадании в кеш
+	loaded := result.(*pvzListLoad)
+	if shared && loaded.value != nil {
+		if pvzs, err := decodePVZList(cache.Entry{Value: loaded.value}); err == nil {
+			return pvzs, nil
+		}
+	}
+	return loaded.pvzs, nil
+}
+
+// Загружает страницу и сохраняет ее в кеш. Страница сразу устаревшая, если во время загрузки
+// прошла инвалидация или реплика еще может отставать от последней инвалидации
+func (s *CachedPVZService) load(key string, filter models.PVZFilter, generation uint64) (*pvzListLoad, error) {
+	pvzs, err := s.PVZServiceInterface.GetPVZsWithReceptions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(pvzs)
+	if err != nil {
+		return &pvzListLoad{pvzs: pvzs}, nil
+	}
+	now := s.config.Now()
+	s.store.Set(key, cache.Entry{
+		Value:    value,
+		Tags:     pvzListTags(filter, pvzs, now),
+		StoredAt: now,
+		Stale:    s.generation.Load() != generation || s.withinReplicaLag(now),
+	})
+	return &pvzListLoad{pvzs: pvzs, value: value}, nil
+}
+
+func (s *CachedPVZService) withinReplicaLag(now time.Time) bool {
+	if s.config.ReplicaLag <= 0 {
+		return false
+	}
+	return now.Sub(time.Unix(0, s.invalidatedAt.Load())) < s.config.ReplicaLag
+}
+
+// Инвалидирует страницы, которые затрагивает событие приемки или товара, в том числе
+// от другого экземпляра сервиса
+func (s *CachedPVZService) InvalidateEvent(event *models.OutboxEvent) {
+	tags := []string{pvzTag(event.PVZID)}
+	if event.Type == models.ReceptionCreated {
+		tags = append(tags, newReceptionsTag)
+	}
+	s.invalidate("event", tags...)
+}
+
+// Инвалидирует весь кеш, например когда часть событий могла быть пропущена
+func (s *CachedPVZService) InvalidateAll(reason string) {
+	s.invalidatedAt.Store(s.config.Now().UnixNano())
+	s.generation.Add(1)
+	s.store.InvalidateAll()
+	metrics.PVZCacheInvalidationsTotal.WithLabelValues(reason).Inc()
+}
+
+func (s *CachedPVZService) invalidate(reason string, tags ...string) {
+	s.invalidatedAt.Store(s.config.Now().UnixNano())
+	s.generation.Add(1)
+	s.store.Invalidate(tags...)
+	metrics.PVZCacheInvalidationsTotal.WithLabelValues(reason).Inc()
+}
+
+// Изменение внутри транзакции идет мимо методов кеша, и какое именно оно было, здесь не видно,
+// поэтому после фиксации сбрасывается весь кеш
+func (s *CachedPVZService) MutateIfVersion(pvzID uuid.UUID, resource PVZResource, matches func(version string) bool, mutate func(PVZServiceInterface) error) error {
+	err := s.PVZServiceInterface.MutateIfVersion(pvzID, resource, matches, mutate)
+	if err == nil {
+		s.InvalidateAll("write")
+	}
+	return err
+}
+
+// Новый ПВЗ сдвигает все страницы, а смена статуса может убрать ПВЗ из выдачи или вернуть в нее
+func (s *CachedPVZService) Create(input models.PVZCreate) (*models.PVZ, error) {
+	pvz, err := s.PVZServiceInterface.Create(input)
+	if err == nil {
+		s.InvalidateAll("write")
+	}
+	return pvz, err
+}
+
+func (s *CachedPVZService) ChangeStatus(pvzID uuid.UUID, status models.PVZStatus) (*models.PVZ, error) {
+	pvz, err := s.PVZServiceInterface.ChangeStatus(pvzID, status)
+	if err == nil {
+		s.InvalidateAll("write")
+	}
+	return pvz, err
+}
+
+func (s *CachedPVZService) Update(pvzID uuid.UUID, update models.PVZUpdate) (*models.PVZ, error) {
+	pvz, err := s.PVZServiceInterface.Update(pvzID, update)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return pvz, err
+}
+
+func (s *CachedPVZService) CreateReception(pvzID uuid.UUID) (*models.Reception, error) {
+	reception, err := s.PVZServiceInterface.CreateReception(pvzID)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID), newReceptionsTag)
+	}
+	return reception, err
+}
+
+func (s *CachedPVZService) CreateProduct(pvzID uuid.UUID, productType string) (*models.Product, error) {
+	product, err := s.PVZServiceInterface.CreateProduct(pvzID, productType)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return product, err
+}
+
+func (s *CachedPVZService) DeleteLastProduct(pvzID uuid.UUID, deletedBy uuid.UUID) error {
+	err := s.PVZServiceInterface.DeleteLastProduct(pvzID, deletedBy)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return err
+}
+
+func (s *CachedPVZService) CloseLastReception(pvzID uuid.UUID) (*models.Reception, error) {
+	reception, err := s.PVZServiceInterface.CloseLastReception(pvzID)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return reception, err
+}
+
+func (s *CachedPVZService) CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error) {
+	closed, err := s.PVZServiceInterface.CloseStaleReceptions(idleFor)
+	if len(closed) > 0 {
+		tags := make([]string, 0, len(closed))
+		for _, reception := range closed {
+			tags = append(tags, pvzTag(reception.PVZID))
+		}
+		s.invalidate("write", tags...)
+	}
+	return closed, err
+}
+
+// График определяет признак isOpen в выдаче
+func (s *CachedPVZService) UpdateSchedule(pvzID uuid.UUID, update models.PVZScheduleUpdate) (*models.PVZSchedule, error) {
+	schedule, err := s.PVZServiceInterface.UpdateSchedule(pvzID, update)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return schedule, err
+}
+
+func (s *CachedPVZService) SetCalendarException(pvzID uuid.UUID, exception models.CalendarException) (*models.PVZSchedule, error) {
+	schedule, err := s.PVZServiceInterface.SetCalendarException(pvzID, exception)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return schedule, err
+}
+
+func (s *CachedPVZService) DeleteCalendarException(pvzID uuid.UUID, date string) error {
+	err := s.PVZServiceInterface.DeleteCalendarException(pvzID, date)
+	if err == nil {
+		s.invalidate("write", pvzTag(pvzID))
+	}
+	return err
+}
+
+// Ключ не зависит от порядка ПВЗ в области доступа и от дат, которые репозиторий не применяет
+func pvzListKey(filter models.PVZFilter) string {
+	var key strings.Builder
+	key.WriteString(pvzListKeyPrefix)
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() {
+		fmt.Fprintf(&key, "from=%s&to=%s&",
+			filter.StartDate.UTC().Format(time.RFC3339Nano), filter.EndDate.UTC().Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(&key, "offset=%d&limit=%d&deleted=%t&archived=%t",
+		filter.Offset, filter.Limit, filter.IncludeDeleted, filter.IncludeArchived)
+
+	if len(filter.PVZIDs) > 0 {
+		ids := make([]string, 0, len(filter.PVZIDs))
+		for _, id := range filter.PVZIDs {
+			ids = append(ids, id.String())
+		}
+		slices.Sort(ids)
+		key.WriteString("&pvz=")
+		key.WriteString(strings.Join(slices.Compact(ids), ","))
+	}
+	return key.String()
+}
+
+func pvzListTags(filter models.PVZFilter, pvzs []*models.PVZWithReceptions, now time.Time) []string {
+	tags := make([]string, 0, len(pvzs)+1)
+	for _, item := range pvzs {
+		tags = append(tags, pvzTag(item.PVZ.ID))
+	}
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && !filter.EndDate.Before(now) {
+		tags = append(tags, newReceptionsTag)
+	}
+	return tags
+}
+
+func pvzTag(pvzID uuid.UUID) string {
+	return "pvz:" + pvzID.String()
+}
+
+func decodePVZList(entry cache.Entry) ([]*models.PVZWithReceptions, error) {
+	var pvzs []*models.PVZWithReceptions
+	if err := json.Unmarshal(entry.Value, &pvzs); err != nil {
+		return nil, err
+	}
+	return pvzs, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/geo"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// ПВЗ ближе этого расстояния считается дублем уже существующего
+	duplicateRadiusKm = 0.015
+	maxNearbyRadiusKm = 50
+	maxAddressLength  = 255
+)
+
+func (s *PVZService) FindNearby(lat, lon, radiusKm float64) ([]*models.PVZWithDistance, error) {
+	center := geo.Point{Lat: lat, Lon: lon}
+	if !center.IsValid() {
+		return nil, apperrors.ErrInvalidCoordinates
+	}
+	// NaN не проходит ни одно сравнение, поэтому проверяется отдельно
+	if math.IsNaN(radiusKm) || radiusKm <= 0 || radiusKm > maxNearbyRadiusKm {
+		return nil, apperrors.ErrInvalidRadius
+	}
+
+	candidates, err := s.readRepo.GetInBoundingBox(geo.BoundingBoxAround(center, radiusKm))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.PVZWithDistance, 0, len(candidates))
+	for _, pvz := range candidates {
+		if pvz.Latitude == nil || pvz.Longitude == nil {
+			continue
+		}
+
+		distance := geo.DistanceKm(center, geo.Point{Lat: *pvz.Latitude, Lon: *pvz.Longitude})
+		if distance > radiusKm {
+			continue
+		}
+
+		result = append(result, &models.PVZWithDistance{PVZ: pvz, DistanceKm: distance})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DistanceKm < result[j].DistanceKm
+	})
+
+	return result, nil
+}
+
+// Координаты задаются только парой
+func validateCoordinates(lat, lon *float64) error {
+	if lat == nil && lon == nil {
+		return nil
+	}
+	if lat == nil || lon == nil {
+		return apperrors.ErrInvalidCoordinates
+	}
+	if !(geo.Point{Lat: *lat, Lon: *lon}).IsValid() {
+		return apperrors.ErrInvalidCoordinates
+	}
+	return nil
+}
+
+func normalizeAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if len([]rune(address)) > maxAddressLength {
+		return "", apperrors.ErrValidationFailed
+	}
+	return address, nil
+}
@@ -4,40 +4,124 @@ import (
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/repository"
+	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type PVZServiceInterface interface {
-	Create(city string) (*models.PVZ, error)
+	Create(input models.PVZCreate) (*models.PVZ, error)
+	Update(pvzID uuid.UUID, update models.PVZUpdate) (*models.PVZ, error)
+	ChangeStatus(pvzID uuid.UUID, status models.PVZStatus) (*models.PVZ, error)
+	FindNearby(lat, lon, radiusKm float64) ([]*models.PVZWithDistance, error)
 	CreateReception(pvzID uuid.UUID) (*models.Reception, error)
 	CreateProduct(pvzID uuid.UUID, productType string) (*models.Product, error)
-	DeleteLastProduct(pvzID uuid.UUID) error
+	DeleteLastProduct(pvzID uuid.UUID, deletedBy uuid.UUID) error
 	CloseLastReception(pvzID uuid.UUID) (*models.Reception, error)
-	GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error)
+	CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error)
+	GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error)
+	GetPVZ(pvzID uuid.UUID) (*models.PVZWithReceptions, error)
+	CurrentVersion(pvzID uuid.UUID, resource PVZResource) (string, error)
+	MutateIfVersion(pvzID uuid.UUID, resource PVZResource, matches func(version string) bool, mutate func(PVZServiceInterface) error) error
+	GetSchedule(pvzID uuid.UUID) (*models.PVZSchedule, error)
+	UpdateSchedule(pvzID uuid.UUID, update models.PVZScheduleUpdate) (*models.PVZSchedule, error)
+	SetCalendarException(pvzID uuid.UUID, exception models.CalendarException) (*models.PVZSchedule, error)
+	DeleteCalendarException(pvzID uuid.UUID, date string) error
+	GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error)
+	UpdateSettings(pvzID uuid.UUID, update models.PVZSettingsUpdate) (*models.PVZSettings, error)
 }
 
 type PVZService struct {
-	pvzRepo repository.PVZRepositoryInterface
+	pvzRepo         repository.PVZRepositoryInterface
+	now             func() time.Time
+	defaultSettings models.PVZSettings
+
+	// Репозиторий для чтения без последующей записи: список ПВЗ, поиск рядом, график и настройки.
+	// Изменения и проверки перед ними всегда читают из pvzRepo, то есть из primary
+	readRepo repository.PVZRepositoryInterface
+}
+
+// Ресурс ПВЗ, версия которого проверяется по If-Match перед изменением
+type PVZResource string
+
+const (
+	// ПВЗ вместе с приемками и товарами, как его отдает GET /pvz/{pvzId}
+	ResourcePVZ      PVZResource = "pvz"
+	ResourceSchedule PVZResource = "schedule"
+	ResourceSettings PVZResource = "settings"
+)
+
+type PVZServiceOption func(*PVZService)
+
+func WithClock(now func() time.Time) PVZServiceOption {
+	return func(s *PVZService) {
+		s.now = now
+	}
+}
+
+// Чтение через реплики; без опции все запросы идут в основной репозиторий
+func WithReadReplica(readRepo repository.PVZRepositoryInterface) PVZServiceOption {
+	return func(s *PVZService) {
+		s.readRepo = readRepo
+	}
 }
 
-func NewPVZService(pvzRepo repository.PVZRepositoryInterface) PVZServiceInterface {
-	return &PVZService{
-		pvzRepo: pvzRepo,
+// Глобальные настройки, действующие для ПВЗ без собственных настроек
+func WithDefaultSettings(settings models.PVZSettings) PVZServiceOption {
+	return func(s *PVZService) {
+		s.defaultSettings = settings
 	}
 }
 
-func (s *PVZService) Create(city string) (*models.PVZ, error) {
-	cityEnum := models.City(city)
-	if !cityEnum.IsValid() {
+func NewPVZService(pvzRepo repository.PVZRepositoryInterface, opts ...PVZServiceOption) PVZServiceInterface {
+	s := &PVZService{
+		pvzRepo:  pvzRepo,
+		readRepo: pvzRepo,
+		now:      time.Now,
+		defaultSettings: models.PVZSettings{
+			AllowedProductTypes: models.AllProductTypes(),
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *PVZService) Create(input models.PVZCreate) (*models.PVZ, error) {
+	if !input.City.IsValid() {
 		return nil, apperrors.ErrInvalidCity
 	}
 
+	address, err := normalizeAddress(input.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCoordinates(input.Latitude, input.Longitude); err != nil {
+		return nil, err
+	}
+
+	if input.Latitude != nil && !input.Force {
+		duplicates, err := s.FindNearby(*input.Latitude, *input.Longitude, duplicateRadiusKm)
+		if err != nil {
+			return nil, err
+		}
+		if len(duplicates) > 0 {
+			return nil, apperrors.ErrPVZDuplicateLocation
+		}
+	}
+
 	pvz := &models.PVZ{
 		ID:               uuid.New(),
 		RegistrationDate: time.Now(),
-		City:             cityEnum,
+		City:             input.City,
+		Status:           models.PVZActive,
+		Address:          address,
+		Latitude:         input.Latitude,
+		Longitude:        input.Longitude,
 	}
 
 	if err := s.pvzRepo.Create(pvz); err != nil {
@@ -47,19 +131,196 @@ func (s *PVZService) Create(city string) (*models.PVZ, error) {
 	return pvz, nil
 }
 
-func (s *PVZService) GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error) {
-	if !startDate.IsZero() && !endDate.IsZero() && startDate.After(endDate) {
+func (s *PVZService) Update(pvzID uuid.UUID, update models.PVZUpdate) (*models.PVZ, error) {
+	if update.City != nil && !update.City.IsValid() {
+		return nil, apperrors.ErrInvalidCity
+	}
+
+	pvz, err := s.getPVZ(pvzID)
+	if err != nil {
+		return nil, err
+	}
+	if pvz.Status == models.PVZArchived {
+		return nil, apperrors.ErrPVZArchived
+	}
+
+	if update.City != nil {
+		pvz.City = *update.City
+	}
+	if update.Address != nil {
+		address, err := normalizeAddress(*update.Address)
+		if err != nil {
+			return nil, err
+		}
+		pvz.Address = address
+	}
+	if update.Latitude != nil || update.Longitude != nil {
+		if err := validateCoordinates(update.Latitude, update.Longitude); err != nil {
+			return nil, err
+		}
+		pvz.Latitude = update.Latitude
+		pvz.Longitude = update.Longitude
+	}
+
+	if err := s.pvzRepo.Update(pvz); err != nil {
+		return nil, err
+	}
+
+	return pvz, nil
+}
+
+// Архивный ПВЗ вернуть нельзя, а выводить из работы можно только без открытой приемки
+func (s *PVZService) ChangeStatus(pvzID uuid.UUID, status models.PVZStatus) (*models.PVZ, error) {
+	if !status.IsValid() {
+		return nil, apperrors.ErrValidationFailed
+	}
+
+	pvz, err := s.getPVZ(pvzID)
+	if err != nil {
+		return nil, err
+	}
+	if pvz.Status == models.PVZArchived {
+		return nil, apperrors.ErrPVZArchived
+	}
+	if pvz.Status == status {
+		return pvz, nil
+	}
+
+	if status != models.PVZActive {
+		activeReception, err := s.pvzRepo.GetActiveReceptionByPVZID(pvzID)
+		if err != nil {
+			return nil, err
+		}
+		if activeReception != nil {
+			return nil, apperrors.ErrPVZHasActiveReception
+		}
+	}
+
+	pvz.Status = status
+	if err := s.pvzRepo.Update(pvz); err != nil {
+		return nil, err
+	}
+
+	return pvz, nil
+}
+
+func (s *PVZService) getPVZ(pvzID uuid.UUID) (*models.PVZ, error) {
+	return findPVZ(s.pvzRepo, pvzID)
+}
+
+func findPVZ(repo repository.PVZRepositoryInterface, pvzID uuid.UUID) (*models.PVZ, error) {
+	pvz, err := repo.GetByID(pvzID)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrPVZNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pvz, nil
+}
+
+func (s *PVZService) GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error) {
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.StartDate.After(filter.EndDate) {
 		return nil, apperrors.ErrInvalidDateRange
 	}
 
-	if offset < 0 || limit <= 0 {
+	if filter.Offset < 0 || filter.Limit <= 0 {
 		return nil, apperrors.ErrInvalidPagination
 	}
 
-	pvzs, err := s.pvzRepo.GetPVZsWithReceptions(startDate, endDate, offset, limit)
+	pvzs, err := s.readRepo.GetPVZsWithReceptions(filter)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.fillOpenState(s.readRepo, pvzs); err != nil {
+		return nil, err
+	}
+
 	return pvzs, nil
 }
+
+func (s *PVZService) GetPVZ(pvzID uuid.UUID) (*models.PVZWithReceptions, error) {
+	return s.loadPVZ(s.readRepo, pvzID)
+}
+
+// Архивный ПВЗ тоже отдается, удаленные товары - нет
+func (s *PVZService) loadPVZ(repo repository.PVZRepositoryInterface, pvzID uuid.UUID) (*models.PVZWithReceptions, error) {
+	pvzs, err := repo.GetPVZsWithReceptions(models.PVZFilter{
+		Limit:           1,
+		IncludeArchived: true,
+		PVZIDs:          []uuid.UUID{pvzID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pvzs) == 0 {
+		return nil, apperrors.ErrPVZNotFound
+	}
+
+	if err := s.fillOpenState(repo, pvzs); err != nil {
+		return nil, err
+	}
+	return pvzs[0], nil
+}
+
+// Версия читается из primary: с отстающей реплики устаревшая версия клиента совпала бы с текущей
+func (s *PVZService) CurrentVersion(pvzID uuid.UUID, resource PVZResource) (string, error) {
+	switch resource {
+	case ResourcePVZ:
+		pvz, err := s.loadPVZ(s.pvzRepo, pvzID)
+		if err != nil {
+			return "", err
+		}
+		return pvz.Version(), nil
+	case ResourceSchedule:
+		if _, err := s.getPVZ(pvzID); err != nil {
+			return "", err
+		}
+		schedule, err := s.loadSchedule(pvzID)
+		if err != nil {
+			return "", err
+		}
+		return schedule.Version(), nil
+	case ResourceSettings:
+		if _, err := s.getPVZ(pvzID); err != nil {
+			return "", err
+		}
+		settings, err := s.loadSettings(pvzID)
+		if err != nil {
+			return "", err
+		}
+		return settings.Version(), nil
+	}
+	return "", fmt.Errorf("unknown resource %q", resource)
+}
+
+// Проверяет версию ресурса и выполняет mutate в одной транзакции под блокировкой строки ПВЗ,
+// поэтому параллельный запрос не изменит ресурс между проверкой и записью. mutate получает
+// сервис, который работает в этой транзакции. Если ПВЗ нет или версия не подходит,
+// возвращает ErrPreconditionFailed
+func (s *PVZService) MutateIfVersion(pvzID uuid.UUID, resource PVZResource, matches func(version string) bool, mutate func(PVZServiceInterface) error) error {
+	err := s.pvzRepo.LockPVZ(pvzID, func(repo repository.PVZRepositoryInterface) error {
+		locked := s.withRepo(repo)
+		version, err := locked.CurrentVersion(pvzID, resource)
+		if err != nil {
+			return err
+		}
+		if !matches(version) {
+			return apperrors.ErrPreconditionFailed
+		}
+		return mutate(locked)
+	})
+	if err == sql.ErrNoRows {
+		return apperrors.ErrPreconditionFailed
+	}
+	return err
+}
+
+// Копия сервиса, которая читает и пишет только через repo, например в транзакции
+func (s *PVZService) withRepo(repo repository.PVZRepositoryInterface) *PVZService {
+	copied := *s
+	copied.pvzRepo = repo
+	copied.readRepo = repo
+	return &copied
+}
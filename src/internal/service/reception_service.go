@@ -10,13 +10,19 @@ import (
 )
 
 func (s *PVZService) CreateReception(pvzID uuid.UUID) (*models.Reception, error) {
-	_, err := s.pvzRepo.GetByID(pvzID)
+	pvz, err := s.pvzRepo.GetByID(pvzID)
 	if err == sql.ErrNoRows {
 		return nil, apperrors.ErrPVZNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
+	if pvz.Status != models.PVZActive {
+		return nil, apperrors.ErrPVZInactive
+	}
+	if err := s.ensureOpen(pvzID); err != nil {
+		return nil, err
+	}
 
 	activeReception, err := s.pvzRepo.GetActiveReceptionByPVZID(pvzID)
 	if err != nil {
@@ -60,7 +66,23 @@ func (s *PVZService) CloseLastReception(pvzID uuid.UUID) (*models.Reception, err
 		return nil, apperrors.ErrReceptionAlreadyClosed
 	}
 
+	settings, err := s.loadSettings(pvzID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.AllowEmptyClose {
+		count, err := s.pvzRepo.CountProductsInReception(reception.ID)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, apperrors.ErrNoProductsInReception
+		}
+	}
+
+	closedAt := s.now()
 	reception.Status = models.Closed
+	reception.ClosedAt = &closedAt
 	err = s.pvzRepo.UpdateReception(reception)
 	if err != nil {
 		return nil, err
@@ -68,3 +90,34 @@ func (s *PVZService) CloseLastReception(pvzID uuid.UUID) (*models.Reception, err
 
 	return reception, nil
 }
+
+// Закрывает от имени системы приемки, по которым не было активности дольше idleFor.
+// Настройка allowEmptyClose здесь не учитывается, иначе пустая приемка блокировала бы ПВЗ
+func (s *PVZService) CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error) {
+	idleSince := s.now().Add(-idleFor)
+
+	stale, err := s.pvzRepo.GetStaleReceptions(idleSince)
+	if err != nil {
+		return nil, err
+	}
+
+	closed := make([]*models.Reception, 0, len(stale))
+	for _, reception := range stale {
+		closedAt := s.now()
+		reception.Status = models.Closed
+		reception.ClosedAt = &closedAt
+		reception.ClosedBySystem = true
+
+		err := s.pvzRepo.CloseIdleReception(reception, idleSince)
+		if err == sql.ErrNoRows {
+			// Приемку успели закрыть вручную или в нее добавили товар
+			continue
+		}
+		if err != nil {
+			return closed, err
+		}
+		closed = append(closed, reception)
+	}
+
+	return closed, nil
+}
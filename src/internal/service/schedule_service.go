@@ -0,0 +1,199 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxCommentLength = 255
+
+func (s *PVZService) GetSchedule(pvzID uuid.UUID) (*models.PVZSchedule, error) {
+	if _, err := findPVZ(s.readRepo, pvzID); err != nil {
+		return nil, err
+	}
+
+	return loadSchedule(s.readRepo, pvzID)
+}
+
+func (s *PVZService) UpdateSchedule(pvzID uuid.UUID, update models.PVZScheduleUpdate) (*models.PVZSchedule, error) {
+	timezone := strings.TrimSpace(update.Timezone)
+	if timezone == "" {
+		timezone = models.DefaultTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, apperrors.ErrInvalidSchedule
+	}
+
+	if err := validateWorkingHours(update.WorkingHours); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureEditable(pvzID); err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.loadSchedule(pvzID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Timezone = timezone
+	schedule.WorkingHours = update.WorkingHours
+	if schedule.WorkingHours == nil {
+		schedule.WorkingHours = []models.WorkingHours{}
+	}
+	schedule.OverrideUntil = update.OverrideUntil
+
+	if err := s.pvzRepo.SaveSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *PVZService) SetCalendarException(pvzID uuid.UUID, exception models.CalendarException) (*models.PVZSchedule, error) {
+	if err := validateCalendarException(&exception); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureEditable(pvzID); err != nil {
+		return nil, err
+	}
+
+	if err := s.pvzRepo.SaveCalendarException(pvzID, exception); err != nil {
+		return nil, err
+	}
+
+	return s.loadSchedule(pvzID)
+}
+
+func (s *PVZService) DeleteCalendarException(pvzID uuid.UUID, date string) error {
+	if _, err := time.Parse(models.DateLayout, date); err != nil {
+		return apperrors.ErrInvalidSchedule
+	}
+
+	if err := s.ensureEditable(pvzID); err != nil {
+		return err
+	}
+
+	err := s.pvzRepo.DeleteCalendarException(pvzID, date)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrCalendarExceptionNotFound
+	}
+	return err
+}
+
+func (s *PVZService) ensureEditable(pvzID uuid.UUID) error {
+	pvz, err := s.getPVZ(pvzID)
+	if err != nil {
+		return err
+	}
+	if pvz.Status == models.PVZArchived {
+		return apperrors.ErrPVZArchived
+	}
+	return nil
+}
+
+func (s *PVZService) loadSchedule(pvzID uuid.UUID) (*models.PVZSchedule, error) {
+	return loadSchedule(s.pvzRepo, pvzID)
+}
+
+func loadSchedule(repo repository.PVZRepositoryInterface, pvzID uuid.UUID) (*models.PVZSchedule, error) {
+	schedules, err := repo.GetSchedules([]uuid.UUID{pvzID})
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, ok := schedules[pvzID]
+	if !ok {
+		return models.DefaultSchedule(pvzID), nil
+	}
+	return schedule, nil
+}
+
+// Операции с приемкой разрешены в часы работы ПВЗ или при действующем override модератора
+func (s *PVZService) ensureOpen(pvzID uuid.UUID) error {
+	schedule, err := s.loadSchedule(pvzID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if schedule.OverrideActive(now) || schedule.IsOpenAt(now) {
+		return nil
+	}
+	return apperrors.ErrPVZClosed
+}
+
+func (s *PVZService) fillOpenState(repo repository.PVZRepositoryInterface, pvzs []*models.PVZWithReceptions) error {
+	if len(pvzs) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(pvzs))
+	for _, item := range pvzs {
+		ids = append(ids, item.PVZ.ID)
+	}
+
+	schedules, err := repo.GetSchedules(ids)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for _, item := range pvzs {
+		schedule, ok := schedules[item.PVZ.ID]
+		if !ok {
+			schedule = models.DefaultSchedule(item.PVZ.ID)
+		}
+		isOpen := item.PVZ.Status == models.PVZActive && schedule.IsOpenAt(now)
+		item.PVZ.IsOpen = &isOpen
+	}
+	return nil
+}
+
+func validateWorkingHours(hours []models.WorkingHours) error {
+	seen := make(map[time.Weekday]bool, len(hours))
+	for _, h := range hours {
+		if h.Weekday < time.Sunday || h.Weekday > time.Saturday || seen[h.Weekday] {
+			return apperrors.ErrInvalidSchedule
+		}
+		if !validInterval(h.Opens, h.Closes) {
+			return apperrors.ErrInvalidSchedule
+		}
+		seen[h.Weekday] = true
+	}
+	return nil
+}
+
+func validateCalendarException(exception *models.CalendarException) error {
+	if _, err := time.Parse(models.DateLayout, exception.Date); err != nil {
+		return apperrors.ErrInvalidSchedule
+	}
+
+	exception.Comment = strings.TrimSpace(exception.Comment)
+	if len([]rune(exception.Comment)) > maxCommentLength {
+		return apperrors.ErrValidationFailed
+	}
+
+	if exception.Closed {
+		exception.Opens = nil
+		exception.Closes = nil
+		return nil
+	}
+
+	if exception.Opens == nil || exception.Closes == nil || !validInterval(*exception.Opens, *exception.Closes) {
+		return apperrors.ErrInvalidSchedule
+	}
+	return nil
+}
+
+func validInterval(opens, closes models.ClockTime) bool {
+	return opens >= 0 && closes <= models.EndOfDay && opens < closes
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+func (s *PVZService) GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	if _, err := findPVZ(s.readRepo, pvzID); err != nil {
+		return nil, err
+	}
+
+	return s.settingsFrom(s.readRepo, pvzID)
+}
+
+func (s *PVZService) UpdateSettings(pvzID uuid.UUID, update models.PVZSettingsUpdate) (*models.PVZSettings, error) {
+	if err := s.ensureEditable(pvzID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.loadSettings(pvzID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.MaxProductsPerReception != nil {
+		if *update.MaxProductsPerReception < 0 {
+			return nil, apperrors.ErrValidationFailed
+		}
+		settings.MaxProductsPerReception = *update.MaxProductsPerReception
+	}
+	if update.AllowedProductTypes != nil {
+		allowedTypes, err := normalizeProductTypes(update.AllowedProductTypes)
+		if err != nil {
+			return nil, err
+		}
+		settings.AllowedProductTypes = allowedTypes
+	}
+	if update.AllowEmptyClose != nil {
+		settings.AllowEmptyClose = *update.AllowEmptyClose
+	}
+
+	if err := s.pvzRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *PVZService) loadSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	return s.settingsFrom(s.pvzRepo, pvzID)
+}
+
+// Без собственных настроек ПВЗ действуют глобальные
+func (s *PVZService) settingsFrom(repo repository.PVZRepositoryInterface, pvzID uuid.UUID) (*models.PVZSettings, error) {
+	settings, err := repo.GetSettings(pvzID)
+	if err == sql.ErrNoRows {
+		defaults := s.defaultSettings
+		defaults.PVZID = pvzID
+		defaults.AllowedProductTypes = append([]models.ProductType(nil), s.defaultSettings.AllowedProductTypes...)
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// Пустой список запрещал бы прием любых товаров, поэтому считается ошибкой
+func normalizeProductTypes(productTypes []models.ProductType) ([]models.ProductType, error) {
+	if len(productTypes) == 0 {
+		return nil, apperrors.ErrValidationFailed
+	}
+
+	seen := make(map[models.ProductType]bool, len(productTypes))
+	result := make([]models.ProductType, 0, len(productTypes))
+	for _, productType := range productTypes {
+		if !productType.IsValid() {
+			return nil, apperrors.ErrInvalidProductType
+		}
+		if seen[productType] {
+			continue
+		}
+		seen[productType] = true
+		result = append(result, productType)
+	}
+
+	return result, nil
+}
+
+// Собирает глобальные настройки из конфигурации, проверяя типы товаров
+func NewDefaultSettings(maxProducts int, allowedTypes []string, allowEmptyClose bool) (models.PVZSettings, error) {
+	productTypes := make([]models.ProductType, 0, len(allowedTypes))
+	for _, productType := range allowedTypes {
+		productTypes = append(productTypes, models.ProductType(productType))
+	}
+
+	normalized, err := normalizeProductTypes(productTypes)
+	if err != nil {
+		return models.PVZSettings{}, err
+	}
+	if maxProducts < 0 {
+		return models.PVZSettings{}, apperrors.ErrValidationFailed
+	}
+
+	return models.PVZSettings{
+		MaxProductsPerReception: maxProducts,
+		AllowedProductTypes:     normalized,
+		AllowEmptyClose:         allowEmptyClose,
+	}, nil
+}
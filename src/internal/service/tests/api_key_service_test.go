@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Хранит ключи в памяти и ищет их по хешу, как таблица api_keys
+type memoryAPIKeys struct {
+	keys    map[string]*models.APIKey
+	touches int
+}
+
+func newMemoryAPIKeys() *memoryAPIKeys {
+	return &memoryAPIKeys{keys: make(map[string]*models.APIKey)}
+}
+
+func (m *memoryAPIKeys) Create(key *models.APIKey) error {
+	stored := *key
+	m.keys[key.KeyHash] = &stored
+	return nil
+}
+
+func (m *memoryAPIKeys) GetByHash(keyHash string) (*models.APIKey, error) {
+	key, ok := m.keys[keyHash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (m *memoryAPIKeys) List() ([]*models.APIKey, error) {
+	keys := make([]*models.APIKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *memoryAPIKeys) Revoke(id uuid.UUID, now time.Time) error {
+	for _, key := range m.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			key.RevokedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memoryAPIKeys) TouchLastUsed(id uuid.UUID, now time.Time) error {
+	for _, key := range m.keys {
+		if key.ID == id {
+			key.LastUsedAt = &now
+			m.touches++
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	keys := newMemoryAPIKeys()
+	pvzRepo := new(MockPVZRepository)
+	audit := new(MockAuditRepository)
+	apiKeyService := service.NewAPIKeyService(keys, pvzRepo, audit, service.WithAPIKeyClock(func() time.Time { return now }))
+
+	pvzID := uuid.New()
+	moderatorID := uuid.New()
+	expiresAt := now.Add(24 * time.Hour)
+	pvzRepo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID}, nil)
+	audit.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditAPIKeyCreated && *entry.ActorID == moderatorID
+	})).Return(nil)
+
+	key, rawKey, err := apiKeyService.CreateKey(models.APIKey{
+		Name:        "etl",
+		Role:        models.EmployeeRole,
+		Permissions: []models.Permission{models.PermPVZRead},
+		PVZIDs:      []uuid.UUID{pvzID},
+		ExpiresAt:   &expiresAt,
+		CreatedBy:   &moderatorID,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawKey, key.Prefix))
+	assert.NotContains(t, keys.keys, rawKey, "ключ хранится только в виде хеша")
+	audit.AssertExpectations(t)
+
+	authenticated, err := apiKeyService.AuthenticateAPIKey(rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, authenticated.ID)
+	assert.True(t, authenticated.Allows(models.PermPVZRead))
+	assert.False(t, authenticated.Allows(models.PermReceptionCreate), "список прав сужает права роли")
+	assert.True(t, authenticated.AllowsPVZ(pvzID))
+	assert.False(t, authenticated.AllowsPVZ(uuid.New()))
+
+	now = now.Add(30 * time.Second)
+	_, err = apiKeyService.AuthenticateAPIKey(rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, 1, keys.touches, "время использования обновляется не чаще раза в минуту")
+
+	now = now.Add(time.Minute)
+	_, err = apiKeyService.AuthenticateAPIKey(rawKey)
+	require.NoError(t, err)
+	assert.Equal(t, 2, keys.touches)
+
+	now = expiresAt
+	_, err = apiKeyService.AuthenticateAPIKey(rawKey)
+	assert.Equal(t, apperrors.ErrInvalidAPIKey, err, "просроченный ключ")
+
+	_, err = apiKeyService.AuthenticateAPIKey("pvzk_unknown")
+	assert.Equal(t, apperrors.ErrInvalidAPIKey, err)
+}
+
+func TestAPIKeyService_CreateKey_Validation(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	missingPVZ := uuid.New()
+	pvzRepo := new(MockPVZRepository)
+	pvzRepo.On("GetByID", missingPVZ).Return(nil, sql.ErrNoRows)
+	apiKeyService := service.NewAPIKeyService(newMemoryAPIKeys(), pvzRepo, nil)
+
+	tests := []struct {
+		name  string
+		input models.APIKey
+		want  error
+	}{
+		{name: "Без имени", input: models.APIKey{Role: models.EmployeeRole}, want: apperrors.ErrInvalidAPIKeyScope},
+		{name: "Неизвестная роль", input: models.APIKey{Name: "etl", Role: "admin"}, want: apperrors.ErrInvalidRole},
+		{
+			name:  "Право вне роли",
+			input: models.APIKey{Name: "etl", Role: models.EmployeeRole, Permissions: []models.Permission{models.PermPVZCreate}},
+			want:  apperrors.ErrInvalidAPIKeyScope,
+		},
+		{name: "Срок в прошлом", input: models.APIKey{Name: "etl", Role: models.EmployeeRole, ExpiresAt: &past}, want: apperrors.ErrInvalidAPIKeyScope},
+		{
+			name:  "Несуществующий ПВЗ",
+			input: models.APIKey{Name: "etl", Role: models.EmployeeRole, PVZIDs: []uuid.UUID{missingPVZ}},
+			want:  apperrors.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := apiKeyService.CreateKey(tt.input)
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestAPIKeyService_RevokeKey(t *testing.T) {
+	keys := newMemoryAPIKeys()
+	apiKeyService := service.NewAPIKeyService(keys, new(MockPVZRepository), nil)
+
+	key, rawKey, err := apiKeyService.CreateKey(models.APIKey{Name: "partner", Role: models.EmployeeRole})
+	require.NoError(t, err)
+
+	require.NoError(t, apiKeyService.RevokeKey(key.ID, uuid.New()))
+
+	_, err = apiKeyService.AuthenticateAPIKey(rawKey)
+	assert.Equal(t, apperrors.ErrInvalidAPIKey, err, "отозванный ключ")
+
+	err = apiKeyService.RevokeKey(key.ID, uuid.New())
+	assert.Equal(t, apperrors.ErrAPIKeyNotFound, err, "повторный отзыв")
+}
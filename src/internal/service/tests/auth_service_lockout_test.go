@@ -0,0 +1,207 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	localjwt "avito-backend/src/pkg/jwt"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Счетчики попыток в памяти; поведение совпадает с таблицей login_attempts
+type memoryLoginAttempts struct {
+	items map[string]*models.LoginAttempts
+}
+
+func newMemoryLoginAttempts() *memoryLoginAttempts {
+	return &memoryLoginAttempts{items: make(map[string]*models.LoginAttempts)}
+}
+
+func (r *memoryLoginAttempts) GetAttempts(scope models.LoginScope, key string) (*models.LoginAttempts, error) {
+	attempts, ok := r.items[string(scope)+":"+key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *attempts
+	return &copied, nil
+}
+
+func (r *memoryLoginAttempts) UpdateAttempts(scope models.LoginScope, key string, update func(attempts *models.LoginAttempts)) (*models.LoginAttempts, error) {
+	attempts, ok := r.items[string(scope)+":"+key]
+	if !ok {
+		attempts = &models.LoginAttempts{Scope: scope, Key: key}
+		r.items[string(scope)+":"+key] = attempts
+	}
+	update(attempts)
+	copied := *attempts
+	return &copied, nil
+}
+
+func (r *memoryLoginAttempts) DeleteAttempts(scope models.LoginScope, key string) error {
+	if _, ok := r.items[string(scope)+":"+key]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.items, string(scope)+":"+key)
+	return nil
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(entry *models.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func newProtectedAuthService(t *testing.T, now *time.Time) (service.AuthServiceInterface, *MockUserRepository, *memoryLoginAttempts, *MockAuditRepository) {
+	t.Helper()
+
+	userRepo := new(MockUserRepository)
+	attempts := newMemoryLoginAttempts()
+	audit := new(MockAuditRepository)
+	protection := service.LoginProtection{
+		MaxFailuresPerEmail: 3,
+		MaxFailuresPerIP:    10,
+		FailureWindow:       15 * time.Minute,
+		LockoutBase:         time.Minute,
+		LockoutMax:          3 * time.Minute,
+	}
+
+	authService := service.NewAuthService(userRepo, localjwt.NewTokenManager("test-secret", 24*time.Hour),
+		service.WithAuthClock(func() time.Time { return *now }),
+		service.WithLoginProtection(attempts, audit, protection))
+	return authService, userRepo, attempts, audit
+}
+
+func TestAuthService_Login_Lockout(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	authService, userRepo, _, audit := newProtectedAuthService(t, &now)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	userRepo.On("GetByEmail", "user@example.com").Return(&models.User{
+		ID: uuid.New(), Email: "user@example.com", PasswordHash: string(hash), Role: "employee",
+	}, nil)
+	audit.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditAccountLocked && entry.Email == "user@example.com"
+	})).Return(nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := authService.Login("user@example.com", "wrong", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrInvalidCredentials, err)
+	}
+
+	_, err = authService.Login("User@Example.com", "correct-password", "10.0.0.1")
+	assert.Equal(t, apperrors.ErrAccountLocked, err, "верный пароль не помогает во время блокировки")
+
+	now = now.Add(time.Minute + time.Second)
+	token, err := authService.Login("user@example.com", "correct-password", "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	audit.AssertNumberOfCalls(t, "Record", 1)
+}
+
+func TestAuthService_Login_LockoutGrows(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	authService, userRepo, attempts, audit := newProtectedAuthService(t, &now)
+
+	userRepo.On("GetByEmail", "ghost@example.com").Return(nil, sql.ErrNoRows)
+	audit.On("Record", mock.Anything).Return(nil)
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for _, lockout := range expected {
+		for i := 0; i < 3; i++ {
+			_, err := authService.Login("ghost@example.com", "guess", "")
+			require.Equal(t, apperrors.ErrInvalidCredentials, err, "несуществующий email блокируется так же, как существующий")
+		}
+
+		state, err := attempts.GetAttempts(models.LoginScopeEmail, "ghost@example.com")
+		require.NoError(t, err)
+		require.NotNil(t, state.LockedUntil)
+		assert.Equal(t, lockout, state.LockedUntil.Sub(now))
+
+		now = *state.LockedUntil
+	}
+}
+
+func TestAuthService_Login_FailureWindow(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	authService, userRepo, attempts, _ := newProtectedAuthService(t, &now)
+
+	userRepo.On("GetByEmail", "user@example.com").Return(nil, sql.ErrNoRows)
+
+	for i := 0; i < 2; i++ {
+		authService.Login("user@example.com", "wrong", "")
+	}
+	now = now.Add(time.Hour)
+	authService.Login("user@example.com", "wrong", "")
+
+	state, err := attempts.GetAttempts(models.LoginScopeEmail, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.FailedCount, "старые неудачи вне окна не учитываются")
+	assert.Nil(t, state.LockedUntil)
+}
+
+func TestAuthService_Login_IPLockout(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	authService, userRepo, _, audit := newProtectedAuthService(t, &now)
+
+	userRepo.On("GetByEmail", mock.Anything).Return(nil, sql.ErrNoRows)
+	audit.On("Record", mock.Anything).Return(nil)
+
+	// Перебор разных email с одного IP: по email порог не достигается, по IP — да
+	for i := 0; i < 10; i++ {
+		_, err := authService.Login(uuid.NewString()+"@example.com", "guess", "10.0.0.9")
+		require.Equal(t, apperrors.ErrInvalidCredentials, err)
+	}
+
+	_, err := authService.Login("another@example.com", "guess", "10.0.0.9")
+	assert.Equal(t, apperrors.ErrAccountLocked, err)
+
+	_, err = authService.Login("another@example.com", "guess", "10.0.0.10")
+	assert.Equal(t, apperrors.ErrInvalidCredentials, err, "другой IP не заблокирован")
+
+	audit.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditIPLocked && entry.IP == "10.0.0.9"
+	}))
+}
+
+func TestAuthService_Unlock(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	authService, userRepo, attempts, audit := newProtectedAuthService(t, &now)
+	moderatorID := uuid.New()
+
+	userRepo.On("GetByEmail", "user@example.com").Return(nil, sql.ErrNoRows)
+	audit.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditAccountLocked
+	})).Return(nil)
+	audit.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditAccountUnlocked && entry.ActorID != nil && *entry.ActorID == moderatorID
+	})).Return(nil).Once()
+
+	for i := 0; i < 3; i++ {
+		authService.Login("user@example.com", "wrong", "")
+	}
+	_, err := authService.Login("user@example.com", "wrong", "")
+	require.Equal(t, apperrors.ErrAccountLocked, err)
+
+	require.NoError(t, authService.Unlock(" User@example.com", "", moderatorID))
+
+	_, err = attempts.GetAttempts(models.LoginScopeEmail, "user@example.com")
+	assert.Equal(t, sql.ErrNoRows, err)
+	_, err = authService.Login("user@example.com", "wrong", "")
+	assert.Equal(t, apperrors.ErrInvalidCredentials, err)
+
+	assert.Equal(t, apperrors.ErrValidationFailed, authService.Unlock("", "", moderatorID))
+	audit.AssertExpectations(t)
+}
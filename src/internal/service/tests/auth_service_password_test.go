@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/notify"
+	"avito-backend/src/internal/service"
+	localjwt "avito-backend/src/pkg/jwt"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Токены сброса в памяти; ConsumeToken повторяет условие UPDATE из репозитория
+type memoryResetTokens struct {
+	tokens map[string]*models.PasswordResetToken
+	used   map[string]bool
+}
+
+func newMemoryResetTokens() *memoryResetTokens {
+	return &memoryResetTokens{tokens: make(map[string]*models.PasswordResetToken), used: make(map[string]bool)}
+}
+
+func (r *memoryResetTokens) CreateToken(token *models.PasswordResetToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *memoryResetTokens) ConsumeToken(tokenHash string, now time.Time) (uuid.UUID, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || r.used[tokenHash] || !token.ExpiresAt.After(now) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	r.used[tokenHash] = true
+	return token.UserID, nil
+}
+
+type captureNotifier struct {
+	messages []notify.Message
+}
+
+func (n *captureNotifier) Send(ctx context.Context, message notify.Message) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+var resetTokenPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+type passwordFixture struct {
+	service  service.AuthServiceInterface
+	userRepo *MockUserRepository
+	tokens   *memoryResetTokens
+	notifier *captureNotifier
+	audit    *MockAuditRepository
+	user     *models.User
+}
+
+func newPasswordFixture(t *testing.T, now *time.Time) *passwordFixture {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password1"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: uuid.New(), Email: "user@example.com", PasswordHash: string(hash), Role: "employee", TokenVersion: 2}
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByEmail", "user@example.com").Return(user, nil)
+	userRepo.On("GetByEmail", mock.Anything).Return(nil, sql.ErrNoRows)
+	userRepo.On("GetByID", user.ID).Return(user, nil)
+	userRepo.On("GetByID", mock.Anything).Return(nil, sql.ErrNoRows)
+	userRepo.On("Update", user).Return(nil)
+
+	policy, err := service.NewPasswordPolicy(8, []string{service.PasswordClassDigit}, "")
+	require.NoError(t, err)
+
+	tokens := newMemoryResetTokens()
+	notifier := &captureNotifier{}
+	audit := new(MockAuditRepository)
+	audit.On("Record", mock.Anything).Return(nil)
+
+	authService := service.NewAuthService(userRepo, localjwt.NewTokenManager("test-secret", 24*time.Hour),
+		service.WithAuthClock(func() time.Time { return *now }),
+		service.WithLoginProtection(newMemoryLoginAttempts(), audit, service.LoginProtection{}),
+		service.WithPasswordPolicy(policy),
+		service.WithPasswordReset(tokens, notifier, 30*time.Minute))
+
+	return &passwordFixture{authService, userRepo, tokens, notifier, audit, user}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+
+	_, err := f.service.ChangePassword(f.user.ID, "wrong", "new-password1")
+	assert.Equal(t, apperrors.ErrInvalidCredentials, err)
+
+	_, err = f.service.ChangePassword(f.user.ID, "old-password1", "weak")
+	assert.ErrorIs(t, err, apperrors.ErrWeakPassword)
+	assert.Equal(t, 2, f.user.TokenVersion, "неудачная смена не отзывает токены")
+
+	token, err := f.service.ChangePassword(f.user.ID, "old-password1", "new-password1")
+	require.NoError(t, err)
+
+	claims, err := localjwt.NewTokenManager("test-secret", 24*time.Hour).ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, 3, claims.TokenVersion)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(f.user.PasswordHash), []byte("new-password1")))
+
+	assert.ErrorIs(t, f.service.ValidateSession(f.user.ID.String(), 2), apperrors.ErrTokenRevoked, "старые токены отозваны")
+	assert.NoError(t, f.service.ValidateSession(f.user.ID.String(), 3))
+
+	f.audit.AssertCalled(t, "Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditPasswordChanged && entry.UserID != nil && *entry.UserID == f.user.ID
+	}))
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+
+	require.NoError(t, f.service.RequestPasswordReset("user@example.com"))
+	require.Len(t, f.notifier.messages, 1)
+	assert.Equal(t, "user@example.com", f.notifier.messages[0].To)
+
+	token := resetTokenPattern.FindString(f.notifier.messages[0].Body)
+	require.NotEmpty(t, token)
+	_, stored := f.tokens.tokens[token]
+	assert.False(t, stored, "в хранилище попадает только хеш токена")
+
+	assert.ErrorIs(t, f.service.ResetPassword(token, "weak"), apperrors.ErrWeakPassword)
+	require.NoError(t, f.service.ResetPassword(token, "reset-password1"))
+	assert.Equal(t, 3, f.user.TokenVersion)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(f.user.PasswordHash), []byte("reset-password1")))
+
+	assert.Equal(t, apperrors.ErrInvalidResetToken, f.service.ResetPassword(token, "another-password1"), "токен одноразовый")
+	assert.Equal(t, apperrors.ErrInvalidResetToken, f.service.ResetPassword("unknown", "another-password1"))
+}
+
+func TestAuthService_PasswordReset_Expired(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+
+	require.NoError(t, f.service.RequestPasswordReset("user@example.com"))
+	token := resetTokenPattern.FindString(f.notifier.messages[0].Body)
+
+	now = now.Add(31 * time.Minute)
+	assert.Equal(t, apperrors.ErrInvalidResetToken, f.service.ResetPassword(token, "reset-password1"))
+	assert.Equal(t, 2, f.user.TokenVersion)
+}
+
+func TestAuthService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+
+	assert.NoError(t, f.service.RequestPasswordReset("ghost@example.com"))
+	assert.Empty(t, f.notifier.messages)
+	assert.Empty(t, f.tokens.tokens)
+}
+
+func TestAuthService_ValidateSession_UnknownUser(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+
+	assert.ErrorIs(t, f.service.ValidateSession(uuid.NewString(), 0), apperrors.ErrTokenRevoked)
+	assert.ErrorIs(t, f.service.ValidateSession("not-a-uuid", 0), apperrors.ErrTokenRevoked)
+}
+
+func TestAuthService_Register_WeakPassword(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+
+	_, err := f.service.Register("new@example.com", "password", "employee")
+	assert.ErrorIs(t, err, apperrors.ErrWeakPassword)
+	f.userRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuthService_DisabledUser(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := newPasswordFixture(t, &now)
+	f.user.Disabled = true
+
+	_, err := f.service.Login("user@example.com", "wrong", "")
+	assert.Equal(t, apperrors.ErrInvalidCredentials, err, "без верного пароля отключение не раскрывается")
+
+	_, err = f.service.Login("user@example.com", "old-password1", "")
+	assert.Equal(t, apperrors.ErrAccountDisabled, err)
+
+	assert.ErrorIs(t, f.service.ValidateSession(f.user.ID.String(), f.user.TokenVersion), apperrors.ErrAccountDisabled)
+
+	require.NoError(t, f.service.RequestPasswordReset("user@example.com"))
+	assert.Empty(t, f.notifier.messages, "отключенному пользователю токен сброса не отправляется")
+}
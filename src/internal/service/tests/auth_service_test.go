@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"testing"
+	"time"
 
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
@@ -46,6 +47,14 @@ func (m *MockUserRepository) Update(user *models.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) List(filter models.UserFilter) ([]*models.User, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Delete(id uuid.UUID) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -53,7 +62,7 @@ func (m *MockUserRepository) Delete(id uuid.UUID) error {
 
 func TestAuthService_Register(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	tokenManager := localjwt.NewTokenManager("test-secret", "24h")
+	tokenManager := localjwt.NewTokenManager("test-secret", 24*time.Hour)
 	service := service.NewAuthService(mockRepo, tokenManager)
 
 	tests := []struct {
@@ -119,7 +128,7 @@ func TestAuthService_Register(t *testing.T) {
 
 func TestAuthService_Login(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	tokenManager := localjwt.NewTokenManager("test-secret", "24h")
+	tokenManager := localjwt.NewTokenManager("test-secret", 24*time.Hour)
 	service := service.NewAuthService(mockRepo, tokenManager)
 
 	password := "password123"
@@ -190,7 +199,7 @@ func TestAuthService_Login(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			tt.mockBehavior(mockRepo)
 
-			token, err := service.Login(tt.email, tt.password)
+			token, err := service.Login(tt.email, tt.password, "")
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -219,7 +228,7 @@ func TestAuthService_Login(t *testing.T) {
 }
 
 func TestAuthService_GenerateToken(t *testing.T) {
-	tokenManager := localjwt.NewTokenManager("test-secret", "24h")
+	tokenManager := localjwt.NewTokenManager("test-secret", 24*time.Hour)
 	userRepo := &repository.UserRepository{}
 	service := service.NewAuthService(userRepo, tokenManager)
 
@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/service"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	denylist := filepath.Join(t.TempDir(), "common.txt")
+	require.NoError(t, os.WriteFile(denylist, []byte("# комментарий\n\nPassword1\nqwerty123\n"), 0o600))
+
+	policy, err := service.NewPasswordPolicy(8, []string{service.PasswordClassLower, service.PasswordClassDigit}, denylist)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "Valid", password: "correct-horse-1"},
+		{name: "Unicode counted by runes", password: "пароль12"},
+		{name: "Too short", password: "abc12", wantErr: true},
+		{name: "No digit", password: "onlyletters", wantErr: true},
+		{name: "No lowercase", password: "UPPER12345", wantErr: true},
+		{name: "Denylisted ignoring case", password: "PASSWORD1", wantErr: true},
+		{name: "Denylisted", password: "qwerty123", wantErr: true},
+		{name: "Longer than bcrypt limit", password: strings.Repeat("a1", 37), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperrors.ErrWeakPassword)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewPasswordPolicy_Invalid(t *testing.T) {
+	_, err := service.NewPasswordPolicy(8, []string{"emoji"}, "")
+	assert.Error(t, err)
+
+	_, err = service.NewPasswordPolicy(100, nil, "")
+	assert.Error(t, err)
+
+	_, err = service.NewPasswordPolicy(8, nil, filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestPasswordPolicy_Special(t *testing.T) {
+	policy, err := service.NewPasswordPolicy(0, []string{service.PasswordClassUpper, service.PasswordClassSpecial}, "")
+	require.NoError(t, err)
+
+	assert.NoError(t, policy.Validate("Secret!"))
+	assert.ErrorIs(t, policy.Validate("Secret"), apperrors.ErrWeakPassword)
+	assert.ErrorIs(t, policy.Validate("secret!"), apperrors.ErrWeakPassword)
+}
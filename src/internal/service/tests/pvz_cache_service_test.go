@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"avito-backend/src/pkg/cache"
+	"avito-backend/src/pkg/metrics"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newCachedPVZService(repo *MockPVZRepository, now *time.Time) *service.CachedPVZService {
+	return service.NewCachedPVZService(service.NewPVZService(repo), cache.NewLRU(10), service.PVZCacheConfig{
+		TTL:      time.Minute,
+		StaleTTL: 5 * time.Minute,
+		Now:      func() time.Time { return *now },
+	})
+}
+
+func pvzPage(pvzID uuid.UUID) []*models.PVZWithReceptions {
+	return []*models.PVZWithReceptions{{
+		PVZ:        &models.PVZ{ID: pvzID, City: models.Moscow, Status: models.PVZActive},
+		Receptions: make([]models.ReceptionWithProducts, 0),
+	}}
+}
+
+func TestCachedPVZService_InvalidateEvent(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pvzID := uuid.New()
+	repo := new(MockPVZRepository)
+	repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(pvzPage(pvzID), nil)
+	repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+
+	svc := newCachedPVZService(repo, &now)
+	filter := models.PVZFilter{Limit: 10}
+	hits := testutil.ToFloat64(metrics.PVZCacheRequestsTotal.WithLabelValues("hit"))
+
+	for i := 0; i < 3; i++ {
+		pvzs, err := svc.GetPVZsWithReceptions(filter)
+		require.NoError(t, err)
+		require.Len(t, pvzs, 1)
+		assert.Equal(t, pvzID, pvzs[0].PVZ.ID)
+	}
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 1)
+	assert.Equal(t, hits+2, testutil.ToFloat64(metrics.PVZCacheRequestsTotal.WithLabelValues("hit")))
+
+	// Порядок ПВЗ в области доступа не влияет на ключ
+	otherID := uuid.New()
+	_, err := svc.GetPVZsWithReceptions(models.PVZFilter{Limit: 10, PVZIDs: []uuid.UUID{pvzID, otherID}})
+	require.NoError(t, err)
+	_, err = svc.GetPVZsWithReceptions(models.PVZFilter{Limit: 10, PVZIDs: []uuid.UUID{otherID, pvzID}})
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 2)
+
+	svc.InvalidateEvent(&models.OutboxEvent{Type: models.ProductAdded, PVZID: uuid.New()})
+	_, err = svc.GetPVZsWithReceptions(filter)
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 2)
+
+	svc.InvalidateEvent(&models.OutboxEvent{Type: models.ProductAdded, PVZID: pvzID})
+	_, err = svc.GetPVZsWithReceptions(filter)
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 3)
+}
+
+func TestCachedPVZService_NewReceptionInDateRange(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockPVZRepository)
+	repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(pvzPage(uuid.New()), nil)
+	repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+
+	svc := newCachedPVZService(repo, &now)
+	past := models.PVZFilter{StartDate: now.AddDate(0, -1, 0), EndDate: now.AddDate(0, 0, -1), Limit: 10}
+	current := models.PVZFilter{StartDate: now.AddDate(0, -1, 0), EndDate: now.AddDate(0, 0, 1), Limit: 10}
+
+	for _, filter := range []models.PVZFilter{past, current} {
+		_, err := svc.GetPVZsWithReceptions(filter)
+		require.NoError(t, err)
+	}
+
+	// Приемка в другом ПВЗ может попасть только в период, который включает текущий момент
+	svc.InvalidateEvent(&models.OutboxEvent{Type: models.ReceptionCreated, PVZID: uuid.New()})
+
+	for _, filter := range []models.PVZFilter{past, current} {
+		_, err := svc.GetPVZsWithReceptions(filter)
+		require.NoError(t, err)
+	}
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 3)
+}
+
+func TestCachedPVZService_ServeStale(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pvzID := uuid.New()
+	dbErr := errors.New("connection refused")
+	repo := new(MockPVZRepository)
+	repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(pvzPage(pvzID), nil).Once()
+	repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(nil, dbErr)
+	repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+
+	svc := newCachedPVZService(repo, &now)
+	filter := models.PVZFilter{Limit: 10}
+
+	_, err := svc.GetPVZsWithReceptions(filter)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	stale := testutil.ToFloat64(metrics.PVZCacheRequestsTotal.WithLabelValues("stale"))
+	pvzs, err := svc.GetPVZsWithReceptions(filter)
+	require.NoError(t, err, "пока БД недоступна, отдается устаревшая страница")
+	require.Len(t, pvzs, 1)
+	assert.Equal(t, pvzID, pvzs[0].PVZ.ID)
+	assert.Equal(t, stale+1, testutil.ToFloat64(metrics.PVZCacheRequestsTotal.WithLabelValues("stale")))
+
+	now = now.Add(5 * time.Minute)
+	_, err = svc.GetPVZsWithReceptions(filter)
+	assert.Equal(t, dbErr, err, "слишком старая страница не отдается")
+
+	_, err = svc.GetPVZsWithReceptions(models.PVZFilter{Limit: 20})
+	assert.Equal(t, dbErr, err)
+}
+
+func TestCachedPVZService_ReplicaLagWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pvzID := uuid.New()
+	repo := new(MockPVZRepository)
+	repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(pvzPage(pvzID), nil)
+	repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+
+	svc := service.NewCachedPVZService(service.NewPVZService(repo), cache.NewLRU(10), service.PVZCacheConfig{
+		TTL:        time.Minute,
+		StaleTTL:   5 * time.Minute,
+		ReplicaLag: 5 * time.Second,
+		Now:        func() time.Time { return now },
+	})
+	filter := models.PVZFilter{Limit: 10}
+
+	svc.InvalidateEvent(&models.OutboxEvent{Type: models.ProductAdded, PVZID: pvzID})
+
+	// Реплика могла еще не получить изменение, поэтому страница не кешируется
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetPVZsWithReceptions(filter)
+		require.NoError(t, err)
+	}
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 2)
+
+	now = now.Add(5 * time.Second)
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetPVZsWithReceptions(filter)
+		require.NoError(t, err)
+	}
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 3)
+}
+
+func TestCachedPVZService_SingleLoad(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pvzID := uuid.New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	repo := new(MockPVZRepository)
+	repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(pvzPage(pvzID), nil).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).Once()
+	repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+
+	svc := newCachedPVZService(repo, &now)
+	filter := models.PVZFilter{Limit: 10}
+
+	const callers = 5
+	results := make([][]*models.PVZWithReceptions, callers)
+	var wg sync.WaitGroup
+	load := func(i int) {
+		defer wg.Done()
+		pvzs, err := svc.GetPVZsWithReceptions(filter)
+		assert.NoError(t, err)
+		results[i] = pvzs
+	}
+
+	wg.Add(callers)
+	go load(0)
+	<-started
+	for i := 1; i < callers; i++ {
+		go load(i)
+	}
+	// Остальные запросы успевают присоединиться к загрузке, пока она заблокирована
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	repo.AssertNumberOfCalls(t, "GetPVZsWithReceptions", 1)
+	for _, pvzs := range results {
+		require.Len(t, pvzs, 1)
+		assert.Equal(t, pvzID, pvzs[0].PVZ.ID)
+	}
+	assert.NotSame(t, results[0][0], results[1][0], "каждый получает свою копию страницы")
+}
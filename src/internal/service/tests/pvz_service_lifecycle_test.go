@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPVZService_Update(t *testing.T) {
+	kazan := models.Kazan
+	invalidCity := models.City("Тверь")
+
+	tests := []struct {
+		name         string
+		update       models.PVZUpdate
+		mockBehavior func(repo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name:   "Success",
+			update: models.PVZUpdate{City: &kazan},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
+					City:   models.Moscow,
+					Status: models.PVZActive,
+				}, nil)
+				repo.On("Update", mock.MatchedBy(func(pvz *models.PVZ) bool {
+					return pvz.City == models.Kazan
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "Invalid City",
+			update:       models.PVZUpdate{City: &invalidCity},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidCity,
+		},
+		{
+			name:   "PVZ Not Found",
+			update: models.PVZUpdate{City: &kazan},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: apperrors.ErrPVZNotFound,
+		},
+		{
+			name:   "Archived PVZ",
+			update: models.PVZUpdate{City: &kazan},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
+					City:   models.Moscow,
+					Status: models.PVZArchived,
+				}, nil)
+			},
+			wantErr: apperrors.ErrPVZArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			pvz, err := service.Update(uuid.New(), tt.update)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, pvz)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, models.Kazan, pvz.City)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       models.PVZStatus
+		mockBehavior func(repo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name:   "Deactivate",
+			status: models.PVZInactive,
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZActive}, nil)
+				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(nil, nil)
+				repo.On("Update", mock.AnythingOfType("*models.PVZ")).Return(nil)
+			},
+		},
+		{
+			name:   "Activate",
+			status: models.PVZActive,
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZInactive}, nil)
+				repo.On("Update", mock.AnythingOfType("*models.PVZ")).Return(nil)
+			},
+		},
+		{
+			name:   "Archive With Active Reception",
+			status: models.PVZArchived,
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZActive}, nil)
+				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
+					Status: models.InProgress,
+				}, nil)
+			},
+			wantErr: apperrors.ErrPVZHasActiveReception,
+		},
+		{
+			name:   "Activate Archived",
+			status: models.PVZActive,
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZArchived}, nil)
+			},
+			wantErr: apperrors.ErrPVZArchived,
+		},
+		{
+			name:         "Invalid Status",
+			status:       models.PVZStatus("closed"),
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			pvz, err := service.ChangeStatus(uuid.New(), tt.status)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, pvz)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.status, pvz.Status)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_CreateReception_InactivePVZ(t *testing.T) {
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
+		City:   models.Moscow,
+		Status: models.PVZInactive,
+	}, nil)
+	service := service.NewPVZService(mockRepo)
+
+	reception, err := service.CreateReception(uuid.New())
+
+	assert.Equal(t, apperrors.ErrPVZInactive, err)
+	assert.Nil(t, reception)
+	mockRepo.AssertExpectations(t)
+}
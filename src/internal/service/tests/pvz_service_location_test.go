@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestPVZService_FindNearby(t *testing.T) {
+	near := &models.PVZ{ID: uuid.New(), Latitude: floatPtr(55.7560), Longitude: floatPtr(37.6175)}
+	farther := &models.PVZ{ID: uuid.New(), Latitude: floatPtr(55.7650), Longitude: floatPtr(37.6173)}
+	outside := &models.PVZ{ID: uuid.New(), Latitude: floatPtr(55.7999), Longitude: floatPtr(37.6999)}
+
+	tests := []struct {
+		name         string
+		lat, lon     float64
+		radiusKm     float64
+		mockBehavior func(repo *MockPVZRepository)
+		wantIDs      []uuid.UUID
+		wantErr      error
+	}{
+		{
+			name:     "Sorted By Distance",
+			lat:      55.7558,
+			lon:      37.6173,
+			radiusKm: 2,
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetInBoundingBox", mock.AnythingOfType("geo.BoundingBox")).
+					Return([]*models.PVZ{farther, outside, near}, nil)
+			},
+			wantIDs: []uuid.UUID{near.ID, farther.ID},
+		},
+		{
+			name:         "Invalid Coordinates",
+			lat:          95,
+			lon:          37.6173,
+			radiusKm:     2,
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidCoordinates,
+		},
+		{
+			name:         "Invalid Radius",
+			lat:          55.7558,
+			lon:          37.6173,
+			radiusKm:     0,
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidRadius,
+		},
+		{
+			name:         "NaN Radius",
+			lat:          55.7558,
+			lon:          37.6173,
+			radiusKm:     math.NaN(),
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidRadius,
+		},
+		{
+			name:     "Repository Error",
+			lat:      55.7558,
+			lon:      37.6173,
+			radiusKm: 2,
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetInBoundingBox", mock.AnythingOfType("geo.BoundingBox")).Return(nil, errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			result, err := service.FindNearby(tt.lat, tt.lon, tt.radiusKm)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, result, len(tt.wantIDs))
+				for i, id := range tt.wantIDs {
+					assert.Equal(t, id, result[i].PVZ.ID)
+				}
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_Create_WithLocation(t *testing.T) {
+	existing := &models.PVZ{ID: uuid.New(), Latitude: floatPtr(55.75580), Longitude: floatPtr(37.61730)}
+
+	tests := []struct {
+		name         string
+		input        models.PVZCreate
+		mockBehavior func(repo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			input: models.PVZCreate{
+				City:      models.Moscow,
+				Address:   "  Тверская, 1 ",
+				Latitude:  floatPtr(55.7558),
+				Longitude: floatPtr(37.6173),
+			},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetInBoundingBox", mock.AnythingOfType("geo.BoundingBox")).Return([]*models.PVZ{}, nil)
+				repo.On("Create", mock.MatchedBy(func(pvz *models.PVZ) bool {
+					return pvz.Address == "Тверская, 1" && *pvz.Latitude == 55.7558
+				})).Return(nil)
+			},
+		},
+		{
+			name: "Duplicate Location",
+			input: models.PVZCreate{
+				City:      models.Moscow,
+				Latitude:  floatPtr(55.75585),
+				Longitude: floatPtr(37.61730),
+			},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetInBoundingBox", mock.AnythingOfType("geo.BoundingBox")).Return([]*models.PVZ{existing}, nil)
+			},
+			wantErr: apperrors.ErrPVZDuplicateLocation,
+		},
+		{
+			name: "Duplicate Location Forced",
+			input: models.PVZCreate{
+				City:      models.Moscow,
+				Latitude:  floatPtr(55.75585),
+				Longitude: floatPtr(37.61730),
+				Force:     true,
+			},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("Create", mock.AnythingOfType("*models.PVZ")).Return(nil)
+			},
+		},
+		{
+			name: "Latitude Without Longitude",
+			input: models.PVZCreate{
+				City:     models.Moscow,
+				Latitude: floatPtr(55.7558),
+			},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidCoordinates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			pvz, err := service.Create(tt.input)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, pvz)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, pvz)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -31,11 +31,13 @@ func TestPVZService_CreateProduct(t *testing.T) {
 					ID:   uuid.New(),
 					City: "Москва",
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
 					Status: models.InProgress,
 				}, nil)
-				repo.On("CreateProduct", mock.AnythingOfType("*models.Product")).Return(nil)
+				repo.On("CreateProduct", mock.AnythingOfType("*models.Product"), 0).Return(nil)
 			},
 			wantErr: nil,
 		},
@@ -48,11 +50,13 @@ func TestPVZService_CreateProduct(t *testing.T) {
 					ID:   uuid.New(),
 					City: "Москва",
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
 					Status: models.InProgress,
 				}, nil)
-				repo.On("CreateProduct", mock.AnythingOfType("*models.Product")).Return(nil)
+				repo.On("CreateProduct", mock.AnythingOfType("*models.Product"), 0).Return(nil)
 			},
 			wantErr: nil,
 		},
@@ -65,11 +69,13 @@ func TestPVZService_CreateProduct(t *testing.T) {
 					ID:   uuid.New(),
 					City: "Москва",
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
 					Status: models.InProgress,
 				}, nil)
-				repo.On("CreateProduct", mock.AnythingOfType("*models.Product")).Return(nil)
+				repo.On("CreateProduct", mock.AnythingOfType("*models.Product"), 0).Return(nil)
 			},
 			wantErr: nil,
 		},
@@ -91,6 +97,8 @@ func TestPVZService_CreateProduct(t *testing.T) {
 					ID:   uuid.New(),
 					City: "Москва",
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(nil, nil)
 			},
 			wantErr: apperrors.ErrNoActiveReception,
@@ -154,7 +162,7 @@ func TestPVZService_DeleteLastProduct(t *testing.T) {
 					Type:        models.Electronics,
 					ReceptionID: uuid.New(),
 				}, nil)
-				repo.On("DeleteProduct", mock.AnythingOfType("uuid.UUID")).Return(nil)
+				repo.On("DeleteProduct", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(nil)
 			},
 			wantErr: nil,
 		},
@@ -227,7 +235,7 @@ func TestPVZService_DeleteLastProduct(t *testing.T) {
 					Type:        models.Electronics,
 					ReceptionID: uuid.New(),
 				}, nil)
-				repo.On("DeleteProduct", mock.AnythingOfType("uuid.UUID")).Return(errors.New("db error"))
+				repo.On("DeleteProduct", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(errors.New("db error"))
 			},
 			wantErr: errors.New("db error"),
 		},
@@ -239,7 +247,7 @@ func TestPVZService_DeleteLastProduct(t *testing.T) {
 			tt.mockBehavior(mockRepo)
 			service := service.NewPVZService(mockRepo)
 
-			err := service.DeleteLastProduct(tt.pvzID)
+			err := service.DeleteLastProduct(tt.pvzID, uuid.New())
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -25,9 +26,11 @@ func TestPVZService_CreateReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(nil, nil)
 				repo.On("CreateReception", mock.AnythingOfType("*models.Reception")).Return(nil)
 			},
@@ -46,9 +49,11 @@ func TestPVZService_CreateReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
 					Status: models.InProgress,
@@ -61,9 +66,11 @@ func TestPVZService_CreateReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(nil, errors.New("db error"))
 			},
 			wantErr: errors.New("db error"),
@@ -107,13 +114,16 @@ func TestPVZService_CloseLastReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
 					Status: models.InProgress,
 				}, nil)
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+				repo.On("CountProductsInReception", mock.AnythingOfType("uuid.UUID")).Return(3, nil)
 				repo.On("UpdateReception", mock.MatchedBy(func(r *models.Reception) bool {
 					return r.Status == models.Closed
 				})).Return(nil)
@@ -133,8 +143,9 @@ func TestPVZService_CloseLastReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(nil, nil)
 			},
@@ -145,8 +156,9 @@ func TestPVZService_CloseLastReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
@@ -168,8 +180,9 @@ func TestPVZService_CloseLastReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrConnDone)
 			},
@@ -180,13 +193,16 @@ func TestPVZService_CloseLastReception(t *testing.T) {
 			pvzID: uuid.New(),
 			mockBehavior: func(repo *MockPVZRepository) {
 				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{
-					ID:   uuid.New(),
-					City: "Москва",
+					ID:     uuid.New(),
+					City:   "Москва",
+					Status: models.PVZActive,
 				}, nil)
 				repo.On("GetActiveReceptionByPVZID", mock.AnythingOfType("uuid.UUID")).Return(&models.Reception{
 					ID:     uuid.New(),
 					Status: models.InProgress,
 				}, nil)
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+				repo.On("CountProductsInReception", mock.AnythingOfType("uuid.UUID")).Return(3, nil)
 				repo.On("UpdateReception", mock.MatchedBy(func(r *models.Reception) bool {
 					return r.Status == models.Closed
 				})).Return(sql.ErrConnDone)
@@ -216,3 +232,38 @@ func TestPVZService_CloseLastReception(t *testing.T) {
 		})
 	}
 }
+
+func TestPVZService_CloseStaleReceptions(t *testing.T) {
+	now := time.Date(2025, 3, 3, 23, 0, 0, 0, time.UTC)
+	idleSince := now.Add(-4 * time.Hour)
+
+	stale := &models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.InProgress}
+	touched := &models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.InProgress}
+
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("GetStaleReceptions", idleSince).Return([]*models.Reception{stale, touched}, nil)
+	mockRepo.On("CloseIdleReception", stale, idleSince).Return(nil)
+	mockRepo.On("CloseIdleReception", touched, idleSince).Return(sql.ErrNoRows)
+	service := service.NewPVZService(mockRepo, service.WithClock(func() time.Time { return now }))
+
+	closed, err := service.CloseStaleReceptions(4 * time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Reception{stale}, closed)
+	assert.Equal(t, models.Closed, stale.Status)
+	assert.True(t, stale.ClosedBySystem)
+	assert.Equal(t, &now, stale.ClosedAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPVZService_CloseStaleReceptions_RepositoryError(t *testing.T) {
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("GetStaleReceptions", mock.AnythingOfType("time.Time")).Return(nil, errors.New("db error"))
+	service := service.NewPVZService(mockRepo)
+
+	closed, err := service.CloseStaleReceptions(time.Hour)
+
+	assert.EqualError(t, err, "db error")
+	assert.Nil(t, closed)
+	mockRepo.AssertExpectations(t)
+}
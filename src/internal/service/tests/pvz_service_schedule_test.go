@@ -0,0 +1,269 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Понедельник, 2025-03-03; по Москве UTC+3
+var (
+	mondayNight   = time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	mondayMorning = time.Date(2025, 3, 3, 7, 0, 0, 0, time.UTC)
+)
+
+func clockAt(t time.Time) service.PVZServiceOption {
+	return service.WithClock(func() time.Time { return t })
+}
+
+func weekdaySchedule(pvzID uuid.UUID) *models.PVZSchedule {
+	schedule := models.DefaultSchedule(pvzID)
+	for day := time.Monday; day <= time.Friday; day++ {
+		schedule.WorkingHours = append(schedule.WorkingHours, models.WorkingHours{Weekday: day, Opens: 9 * 60, Closes: 21 * 60})
+	}
+	return schedule
+}
+
+func TestPVZService_CreateReception_WorkingHours(t *testing.T) {
+	pvzID := uuid.New()
+	overrideUntil := mondayNight.Add(time.Hour)
+
+	holiday := weekdaySchedule(pvzID)
+	holiday.Exceptions = []models.CalendarException{{Date: "2025-03-03", Closed: true}}
+
+	overridden := weekdaySchedule(pvzID)
+	overridden.OverrideUntil = &overrideUntil
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		schedule *models.PVZSchedule
+		wantErr  error
+	}{
+		{name: "Open", now: mondayMorning, schedule: weekdaySchedule(pvzID)},
+		{name: "Outside Working Hours", now: mondayNight, schedule: weekdaySchedule(pvzID), wantErr: apperrors.ErrPVZClosed},
+		{name: "Holiday", now: mondayMorning, schedule: holiday, wantErr: apperrors.ErrPVZClosed},
+		{name: "Moderator Override", now: mondayNight, schedule: overridden},
+		{name: "Expired Override", now: mondayNight.Add(2 * time.Hour), schedule: overridden, wantErr: apperrors.ErrPVZClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			mockRepo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID, Status: models.PVZActive}, nil)
+			mockRepo.On("GetSchedules", []uuid.UUID{pvzID}).Return(map[uuid.UUID]*models.PVZSchedule{pvzID: tt.schedule}, nil)
+			if tt.wantErr == nil {
+				mockRepo.On("GetActiveReceptionByPVZID", pvzID).Return(nil, nil)
+				mockRepo.On("CreateReception", mock.AnythingOfType("*models.Reception")).Return(nil)
+			}
+			service := service.NewPVZService(mockRepo, clockAt(tt.now))
+
+			reception, err := service.CreateReception(pvzID)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, reception)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, reception)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_CreateProduct_OutsideWorkingHours(t *testing.T) {
+	pvzID := uuid.New()
+
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID, Status: models.PVZActive}, nil)
+	mockRepo.On("GetSchedules", []uuid.UUID{pvzID}).Return(map[uuid.UUID]*models.PVZSchedule{pvzID: weekdaySchedule(pvzID)}, nil)
+	service := service.NewPVZService(mockRepo, clockAt(mondayNight))
+
+	product, err := service.CreateProduct(pvzID, string(models.Electronics))
+
+	assert.Equal(t, apperrors.ErrPVZClosed, err)
+	assert.Nil(t, product)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPVZService_GetPVZsWithReceptions_OpenState(t *testing.T) {
+	openID := uuid.New()
+	closedID := uuid.New()
+
+	yekaterinburg := weekdaySchedule(closedID)
+	yekaterinburg.Timezone = "Asia/Yekaterinburg"
+	yekaterinburg.Exceptions = []models.CalendarException{{Date: "2025-03-03", Opens: clockPtr(9 * 60), Closes: clockPtr(12 * 60)}}
+
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return([]*models.PVZWithReceptions{
+		{PVZ: &models.PVZ{ID: openID, Status: models.PVZActive}},
+		{PVZ: &models.PVZ{ID: closedID, Status: models.PVZActive}},
+	}, nil)
+	mockRepo.On("GetSchedules", []uuid.UUID{openID, closedID}).Return(map[uuid.UUID]*models.PVZSchedule{
+		openID:   weekdaySchedule(openID),
+		closedID: yekaterinburg,
+	}, nil)
+	service := service.NewPVZService(mockRepo, clockAt(mondayMorning))
+
+	pvzs, err := service.GetPVZsWithReceptions(models.PVZFilter{Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, pvzs, 2)
+	require.NotNil(t, pvzs[0].PVZ.IsOpen)
+	assert.True(t, *pvzs[0].PVZ.IsOpen)
+	// В Екатеринбурге уже 12:00, сокращенный день закончился
+	require.NotNil(t, pvzs[1].PVZ.IsOpen)
+	assert.False(t, *pvzs[1].PVZ.IsOpen)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPVZService_UpdateSchedule(t *testing.T) {
+	hours := []models.WorkingHours{{Weekday: time.Monday, Opens: 9 * 60, Closes: 21 * 60}}
+
+	tests := []struct {
+		name         string
+		update       models.PVZScheduleUpdate
+		mockBehavior func(repo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name:   "Success",
+			update: models.PVZScheduleUpdate{Timezone: "Europe/Kaliningrad", WorkingHours: hours},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZActive}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+				repo.On("SaveSchedule", mock.MatchedBy(func(schedule *models.PVZSchedule) bool {
+					return schedule.Timezone == "Europe/Kaliningrad" && len(schedule.WorkingHours) == 1
+				})).Return(nil)
+			},
+		},
+		{
+			name:         "Invalid Timezone",
+			update:       models.PVZScheduleUpdate{Timezone: "Mars/Olympus", WorkingHours: hours},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidSchedule,
+		},
+		{
+			name: "Duplicate Weekday",
+			update: models.PVZScheduleUpdate{WorkingHours: []models.WorkingHours{
+				{Weekday: time.Monday, Opens: 9 * 60, Closes: 12 * 60},
+				{Weekday: time.Monday, Opens: 13 * 60, Closes: 21 * 60},
+			}},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidSchedule,
+		},
+		{
+			name: "Closes Before Opens",
+			update: models.PVZScheduleUpdate{WorkingHours: []models.WorkingHours{
+				{Weekday: time.Monday, Opens: 21 * 60, Closes: 9 * 60},
+			}},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidSchedule,
+		},
+		{
+			name:   "Archived PVZ",
+			update: models.PVZScheduleUpdate{WorkingHours: hours},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZArchived}, nil)
+			},
+			wantErr: apperrors.ErrPVZArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			schedule, err := service.UpdateSchedule(uuid.New(), tt.update)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, schedule)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, hours, schedule.WorkingHours)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_SetCalendarException(t *testing.T) {
+	tests := []struct {
+		name         string
+		exception    models.CalendarException
+		mockBehavior func(repo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name:      "Holiday",
+			exception: models.CalendarException{Date: "2025-01-01", Closed: true, Opens: clockPtr(600), Comment: " Новый год "},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZActive}, nil)
+				repo.On("SaveCalendarException", mock.AnythingOfType("uuid.UUID"), models.CalendarException{
+					Date: "2025-01-01", Closed: true, Comment: "Новый год",
+				}).Return(nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+			},
+		},
+		{
+			name:         "Invalid Date",
+			exception:    models.CalendarException{Date: "01.01.2025", Closed: true},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidSchedule,
+		},
+		{
+			name:         "Short Day Without Hours",
+			exception:    models.CalendarException{Date: "2025-12-31", Opens: clockPtr(600)},
+			mockBehavior: func(repo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidSchedule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			schedule, err := service.SetCalendarException(uuid.New(), tt.exception)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, schedule)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, schedule)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_DeleteCalendarException_NotFound(t *testing.T) {
+	pvzID := uuid.New()
+
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("GetByID", pvzID).Return(&models.PVZ{Status: models.PVZActive}, nil)
+	mockRepo.On("DeleteCalendarException", pvzID, "2025-01-01").Return(sql.ErrNoRows)
+	service := service.NewPVZService(mockRepo)
+
+	assert.Equal(t, apperrors.ErrCalendarExceptionNotFound, service.DeleteCalendarException(pvzID, "2025-01-01"))
+	mockRepo.AssertExpectations(t)
+}
+
+func clockPtr(minutes int) *models.ClockTime {
+	clock := models.ClockTime(minutes)
+	return &clock
+}
@@ -0,0 +1,310 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"avito-backend/src/internal/service"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPVZService_CreateProduct_Settings(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+
+	tests := []struct {
+		name         string
+		productType  models.ProductType
+		settings     *models.PVZSettings
+		mockBehavior func(repo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name:        "Type Not Allowed",
+			productType: models.Electronics,
+			settings:    &models.PVZSettings{AllowedProductTypes: []models.ProductType{models.Clothes, models.Shoes}},
+			wantErr:     apperrors.ErrProductTypeNotAllowed,
+		},
+		{
+			name:        "Limit Reached",
+			productType: models.Clothes,
+			settings:    &models.PVZSettings{MaxProductsPerReception: 10, AllowedProductTypes: models.AllProductTypes()},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetActiveReceptionByPVZID", pvzID).Return(&models.Reception{ID: receptionID, Status: models.InProgress}, nil)
+				repo.On("CreateProduct", mock.AnythingOfType("*models.Product"), 10).Return(apperrors.ErrReceptionProductLimit)
+			},
+			wantErr: apperrors.ErrReceptionProductLimit,
+		},
+		{
+			name:        "Below Limit",
+			productType: models.Clothes,
+			settings:    &models.PVZSettings{MaxProductsPerReception: 10, AllowedProductTypes: models.AllProductTypes()},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetActiveReceptionByPVZID", pvzID).Return(&models.Reception{ID: receptionID, Status: models.InProgress}, nil)
+				repo.On("CreateProduct", mock.AnythingOfType("*models.Product"), 10).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			mockRepo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID, Status: models.PVZActive}, nil)
+			mockRepo.On("GetSchedules", []uuid.UUID{pvzID}).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+			mockRepo.On("GetSettings", pvzID).Return(tt.settings, nil)
+			if tt.mockBehavior != nil {
+				tt.mockBehavior(mockRepo)
+			}
+			service := service.NewPVZService(mockRepo)
+
+			product, err := service.CreateProduct(pvzID, string(tt.productType))
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, product)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, receptionID, product.ReceptionID)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_CloseLastReception_EmptyReception(t *testing.T) {
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+
+	tests := []struct {
+		name            string
+		defaults        models.PVZSettings
+		wantErr         error
+		expectsCount    bool
+		expectsUpdating bool
+	}{
+		{
+			name:         "Forbidden By Default",
+			defaults:     models.PVZSettings{AllowedProductTypes: models.AllProductTypes()},
+			wantErr:      apperrors.ErrNoProductsInReception,
+			expectsCount: true,
+		},
+		{
+			name:            "Allowed By Global Settings",
+			defaults:        models.PVZSettings{AllowedProductTypes: models.AllProductTypes(), AllowEmptyClose: true},
+			expectsUpdating: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			mockRepo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID, Status: models.PVZActive}, nil)
+			mockRepo.On("GetActiveReceptionByPVZID", pvzID).Return(&models.Reception{ID: receptionID, Status: models.InProgress}, nil)
+			mockRepo.On("GetSettings", pvzID).Return(nil, sql.ErrNoRows)
+			if tt.expectsCount {
+				mockRepo.On("CountProductsInReception", receptionID).Return(0, nil)
+			}
+			if tt.expectsUpdating {
+				mockRepo.On("UpdateReception", mock.AnythingOfType("*models.Reception")).Return(nil)
+			}
+			service := service.NewPVZService(mockRepo, service.WithDefaultSettings(tt.defaults))
+
+			reception, err := service.CloseLastReception(pvzID)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, reception)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, models.Closed, reception.Status)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPVZService_UpdateSettings(t *testing.T) {
+	limit := 200
+	negative := -1
+	allowEmpty := true
+
+	tests := []struct {
+		name         string
+		update       models.PVZSettingsUpdate
+		mockBehavior func(repo *MockPVZRepository)
+		want         *models.PVZSettings
+		wantErr      error
+	}{
+		{
+			name: "Merges With Defaults",
+			update: models.PVZSettingsUpdate{
+				MaxProductsPerReception: &limit,
+				AllowedProductTypes:     []models.ProductType{models.Shoes, models.Shoes, models.Clothes},
+			},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+				repo.On("SaveSettings", mock.AnythingOfType("*models.PVZSettings")).Return(nil)
+			},
+			want: &models.PVZSettings{
+				MaxProductsPerReception: 200,
+				AllowedProductTypes:     []models.ProductType{models.Shoes, models.Clothes},
+				AllowEmptyClose:         false,
+			},
+		},
+		{
+			name:   "Keeps Stored Values",
+			update: models.PVZSettingsUpdate{AllowEmptyClose: &allowEmpty},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZSettings{
+					MaxProductsPerReception: 50,
+					AllowedProductTypes:     []models.ProductType{models.Electronics},
+				}, nil)
+				repo.On("SaveSettings", mock.AnythingOfType("*models.PVZSettings")).Return(nil)
+			},
+			want: &models.PVZSettings{
+				MaxProductsPerReception: 50,
+				AllowedProductTypes:     []models.ProductType{models.Electronics},
+				AllowEmptyClose:         true,
+			},
+		},
+		{
+			name:   "Negative Limit",
+			update: models.PVZSettingsUpdate{MaxProductsPerReception: &negative},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: apperrors.ErrValidationFailed,
+		},
+		{
+			name:   "Unknown Product Type",
+			update: models.PVZSettingsUpdate{AllowedProductTypes: []models.ProductType{"мебель"}},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: apperrors.ErrInvalidProductType,
+		},
+		{
+			name:   "Empty Product Types",
+			update: models.PVZSettingsUpdate{AllowedProductTypes: []models.ProductType{}},
+			mockBehavior: func(repo *MockPVZRepository) {
+				repo.On("GetSettings", mock.AnythingOfType("uuid.UUID")).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: apperrors.ErrValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockPVZRepository)
+			mockRepo.On("GetByID", mock.AnythingOfType("uuid.UUID")).Return(&models.PVZ{Status: models.PVZActive}, nil)
+			tt.mockBehavior(mockRepo)
+			service := service.NewPVZService(mockRepo)
+
+			settings, err := service.UpdateSettings(uuid.New(), tt.update)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, settings)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want.MaxProductsPerReception, settings.MaxProductsPerReception)
+				assert.Equal(t, tt.want.AllowedProductTypes, settings.AllowedProductTypes)
+				assert.Equal(t, tt.want.AllowEmptyClose, settings.AllowEmptyClose)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewDefaultSettings(t *testing.T) {
+	settings, err := service.NewDefaultSettings(1000, []string{"одежда", "обувь"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, settings.MaxProductsPerReception)
+	assert.Equal(t, []models.ProductType{models.Clothes, models.Shoes}, settings.AllowedProductTypes)
+
+	_, err = service.NewDefaultSettings(1000, []string{"мебель"}, false)
+	assert.Equal(t, apperrors.ErrInvalidProductType, err)
+}
+
+// Репозиторий, который хранит настройки в памяти и блокирует ПВЗ мьютексом, как FOR UPDATE
+type lockingSettingsRepository struct {
+	*MockPVZRepository
+	lock     sync.Mutex
+	settings models.PVZSettings
+}
+
+func (r *lockingSettingsRepository) LockPVZ(pvzID uuid.UUID, fn func(repo repository.PVZRepositoryInterface) error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return fn(r)
+}
+
+func (r *lockingSettingsRepository) GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	settings := r.settings
+	return &settings, nil
+}
+
+func (r *lockingSettingsRepository) SaveSettings(settings *models.PVZSettings) error {
+	r.settings = *settings
+	return nil
+}
+
+func TestPVZService_MutateIfVersion_ConcurrentWriters(t *testing.T) {
+	pvzID := uuid.New()
+	repo := &lockingSettingsRepository{
+		MockPVZRepository: new(MockPVZRepository),
+		settings:          models.PVZSettings{PVZID: pvzID, MaxProductsPerReception: 10, AllowedProductTypes: models.AllProductTypes()},
+	}
+	repo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID, Status: models.PVZActive}, nil)
+	pvzService := service.NewPVZService(repo)
+
+	version := repo.settings.Version()
+	matches := func(current string) bool { return current == version }
+	update := func(limit int) func(service.PVZServiceInterface) error {
+		return func(svc service.PVZServiceInterface) error {
+			_, err := svc.UpdateSettings(pvzID, models.PVZSettingsUpdate{MaxProductsPerReception: &limit})
+			return err
+		}
+	}
+
+	var secondErr error
+	var wg sync.WaitGroup
+	firstErr := pvzService.MutateIfVersion(pvzID, service.ResourceSettings, matches, func(svc service.PVZServiceInterface) error {
+		// Второй писатель с той же версией приходит, пока первый держит блокировку
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			secondErr = pvzService.MutateIfVersion(pvzID, service.ResourceSettings, matches, update(30))
+		}()
+		time.Sleep(20 * time.Millisecond)
+		return update(20)(svc)
+	})
+	wg.Wait()
+
+	require.NoError(t, firstErr)
+	assert.Equal(t, apperrors.ErrPreconditionFailed, secondErr)
+	assert.Equal(t, 20, repo.settings.MaxProductsPerReception)
+}
+
+func TestPVZService_MutateIfVersion_PVZNotFound(t *testing.T) {
+	pvzID := uuid.New()
+	mockRepo := new(MockPVZRepository)
+	mockRepo.On("LockPVZ", pvzID).Return(sql.ErrNoRows)
+	pvzService := service.NewPVZService(mockRepo)
+
+	err := pvzService.MutateIfVersion(pvzID, service.ResourceSettings, func(string) bool { return true }, func(service.PVZServiceInterface) error {
+		t.Fatal("изменение не должно выполняться")
+		return nil
+	})
+
+	assert.Equal(t, apperrors.ErrPreconditionFailed, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -3,7 +3,10 @@ package service_test
 import (
 	"avito-backend/src/internal/apperrors"
 	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
 	"avito-backend/src/internal/service"
+	"avito-backend/src/pkg/geo"
+	"database/sql"
 	"errors"
 	"testing"
 	"time"
@@ -30,6 +33,14 @@ func (m *MockPVZRepository) GetByID(id uuid.UUID) (*models.PVZ, error) {
 	return args.Get(0).(*models.PVZ), args.Error(1)
 }
 
+func (m *MockPVZRepository) LockPVZ(pvzID uuid.UUID, fn func(repo repository.PVZRepositoryInterface) error) error {
+	args := m.Called(pvzID)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(m)
+}
+
 func (m *MockPVZRepository) CreateReception(reception *models.Reception) error {
 	args := m.Called(reception)
 	return args.Error(0)
@@ -43,13 +54,13 @@ func (m *MockPVZRepository) GetActiveReceptionByPVZID(pvzID uuid.UUID) (*models.
 	return args.Get(0).(*models.Reception), args.Error(1)
 }
 
-func (m *MockPVZRepository) CreateProduct(product *models.Product) error {
-	args := m.Called(product)
+func (m *MockPVZRepository) CreateProduct(product *models.Product, maxProducts int) error {
+	args := m.Called(product, maxProducts)
 	return args.Error(0)
 }
 
-func (m *MockPVZRepository) DeleteProduct(pvzID uuid.UUID) error {
-	args := m.Called(pvzID)
+func (m *MockPVZRepository) DeleteProduct(productID uuid.UUID, deletedBy uuid.UUID) error {
+	args := m.Called(productID, deletedBy)
 	return args.Error(0)
 }
 
@@ -66,8 +77,75 @@ func (m *MockPVZRepository) UpdateReception(reception *models.Reception) error {
 	return args.Error(0)
 }
 
-func (m *MockPVZRepository) GetPVZsWithReceptions(startDate, endDate time.Time, offset, limit int) ([]*models.PVZWithReceptions, error) {
-	args := m.Called(startDate, endDate, offset, limit)
+func (m *MockPVZRepository) Update(pvz *models.PVZ) error {
+	args := m.Called(pvz)
+	return args.Error(0)
+}
+
+func (m *MockPVZRepository) GetInBoundingBox(box geo.BoundingBox) ([]*models.PVZ, error) {
+	args := m.Called(box)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PVZ), args.Error(1)
+}
+
+func (m *MockPVZRepository) GetStaleReceptions(idleSince time.Time) ([]*models.Reception, error) {
+	args := m.Called(idleSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Reception), args.Error(1)
+}
+
+func (m *MockPVZRepository) CloseIdleReception(reception *models.Reception, idleSince time.Time) error {
+	args := m.Called(reception, idleSince)
+	return args.Error(0)
+}
+
+func (m *MockPVZRepository) CountProductsInReception(receptionID uuid.UUID) (int, error) {
+	args := m.Called(receptionID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPVZRepository) GetSettings(pvzID uuid.UUID) (*models.PVZSettings, error) {
+	args := m.Called(pvzID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PVZSettings), args.Error(1)
+}
+
+func (m *MockPVZRepository) SaveSettings(settings *models.PVZSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+func (m *MockPVZRepository) GetSchedules(pvzIDs []uuid.UUID) (map[uuid.UUID]*models.PVZSchedule, error) {
+	args := m.Called(pvzIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*models.PVZSchedule), args.Error(1)
+}
+
+func (m *MockPVZRepository) SaveSchedule(schedule *models.PVZSchedule) error {
+	args := m.Called(schedule)
+	return args.Error(0)
+}
+
+func (m *MockPVZRepository) SaveCalendarException(pvzID uuid.UUID, exception models.CalendarException) error {
+	args := m.Called(pvzID, exception)
+	return args.Error(0)
+}
+
+func (m *MockPVZRepository) DeleteCalendarException(pvzID uuid.UUID, date string) error {
+	args := m.Called(pvzID, date)
+	return args.Error(0)
+}
+
+func (m *MockPVZRepository) GetPVZsWithReceptions(filter models.PVZFilter) ([]*models.PVZWithReceptions, error) {
+	args := m.Called(filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -133,7 +211,7 @@ func TestPVZService_Create(t *testing.T) {
 			tt.mockBehavior(mockRepo)
 			service := service.NewPVZService(mockRepo)
 
-			pvz, err := service.Create(tt.city)
+			pvz, err := service.Create(models.PVZCreate{City: models.City(tt.city)})
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -168,7 +246,7 @@ func TestPVZService_GetPVZsWithReceptions(t *testing.T) {
 			offset:    0,
 			limit:     10,
 			mockBehavior: func(repo *MockPVZRepository) {
-				repo.On("GetPVZsWithReceptions", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 0, 10).Return(
+				repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(
 					[]*models.PVZWithReceptions{
 						{
 							PVZ: &models.PVZ{
@@ -179,6 +257,7 @@ func TestPVZService_GetPVZsWithReceptions(t *testing.T) {
 							Receptions: make([]models.ReceptionWithProducts, 0),
 						},
 					}, nil)
+				repo.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
 			},
 			wantErr: nil,
 		},
@@ -207,7 +286,7 @@ func TestPVZService_GetPVZsWithReceptions(t *testing.T) {
 			offset:    0,
 			limit:     10,
 			mockBehavior: func(repo *MockPVZRepository) {
-				repo.On("GetPVZsWithReceptions", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 0, 10).Return(
+				repo.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(
 					nil, errors.New("db error"))
 			},
 			wantErr: errors.New("db error"),
@@ -220,7 +299,12 @@ func TestPVZService_GetPVZsWithReceptions(t *testing.T) {
 			tt.mockBehavior(mockRepo)
 			service := service.NewPVZService(mockRepo)
 
-			pvzs, err := service.GetPVZsWithReceptions(tt.startDate, tt.endDate, tt.offset, tt.limit)
+			pvzs, err := service.GetPVZsWithReceptions(models.PVZFilter{
+				StartDate: tt.startDate,
+				EndDate:   tt.endDate,
+				Offset:    tt.offset,
+				Limit:     tt.limit,
+			})
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -234,3 +318,61 @@ func TestPVZService_GetPVZsWithReceptions(t *testing.T) {
 		})
 	}
 }
+
+func TestPVZService_ReadReplica(t *testing.T) {
+	pvzID := uuid.New()
+	primary := new(MockPVZRepository)
+	replica := new(MockPVZRepository)
+
+	replica.On("GetPVZsWithReceptions", mock.AnythingOfType("models.PVZFilter")).Return(
+		[]*models.PVZWithReceptions{{
+			PVZ:        &models.PVZ{ID: pvzID, City: models.City("Москва")},
+			Receptions: make([]models.ReceptionWithProducts, 0),
+		}}, nil)
+	replica.On("GetSchedules", mock.Anything).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+	replica.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID, City: models.City("Москва")}, nil)
+	replica.On("GetSettings", pvzID).Return(nil, sql.ErrNoRows)
+
+	svc := service.NewPVZService(primary, service.WithReadReplica(replica))
+
+	pvzs, err := svc.GetPVZsWithReceptions(models.PVZFilter{
+		StartDate: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+		Limit:     10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, pvzs, 1)
+
+	settings, err := svc.GetSettings(pvzID)
+	assert.NoError(t, err)
+	assert.Equal(t, pvzID, settings.PVZID)
+
+	replica.AssertExpectations(t)
+	primary.AssertNotCalled(t, "GetPVZsWithReceptions", mock.Anything)
+	primary.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestPVZService_CurrentVersion(t *testing.T) {
+	pvzID := uuid.New()
+	primary := new(MockPVZRepository)
+	replica := new(MockPVZRepository)
+	svc := service.NewPVZService(primary, service.WithReadReplica(replica))
+
+	page := []*models.PVZWithReceptions{{
+		PVZ:        &models.PVZ{ID: pvzID, City: models.Moscow, Status: models.PVZActive},
+		Receptions: make([]models.ReceptionWithProducts, 0),
+	}}
+	primary.On("GetPVZsWithReceptions", models.PVZFilter{Limit: 1, IncludeArchived: true, PVZIDs: []uuid.UUID{pvzID}}).Return(page, nil)
+	primary.On("GetSchedules", []uuid.UUID{pvzID}).Return(map[uuid.UUID]*models.PVZSchedule{}, nil)
+
+	version, err := svc.CurrentVersion(pvzID, service.ResourcePVZ)
+	assert.NoError(t, err)
+	assert.Equal(t, page[0].Version(), version)
+	replica.AssertNotCalled(t, "GetPVZsWithReceptions", mock.Anything)
+
+	missingID := uuid.New()
+	primary.On("GetPVZsWithReceptions", models.PVZFilter{Limit: 1, IncludeArchived: true, PVZIDs: []uuid.UUID{missingID}}).
+		Return([]*models.PVZWithReceptions{}, nil)
+	_, err = svc.CurrentVersion(missingID, service.ResourcePVZ)
+	assert.Equal(t, apperrors.ErrPVZNotFound, err)
+}
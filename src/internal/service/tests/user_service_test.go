@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_ListUsers(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userService := service.NewUserService(userRepo, nil)
+
+	role := models.EmployeeRole
+	filter := models.UserFilter{Role: &role, Email: "ivan", Limit: 20}
+	userRepo.On("List", filter).Return([]*models.User{{Email: "ivan@example.com"}}, nil)
+
+	users, err := userService.ListUsers(filter)
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	_, err = userService.ListUsers(models.UserFilter{Limit: 101})
+	assert.Equal(t, apperrors.ErrInvalidPagination, err)
+
+	invalid := models.Role("admin")
+	_, err = userService.ListUsers(models.UserFilter{Role: &invalid, Limit: 10})
+	assert.Equal(t, apperrors.ErrInvalidRole, err)
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	moderatorID := uuid.New()
+	userID := uuid.New()
+	moderator := models.ModeratorRole
+	disabled := true
+
+	t.Run("Role and disabled", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		audit := new(MockAuditRepository)
+		userService := service.NewUserService(userRepo, audit)
+
+		user := &models.User{ID: userID, Email: "user@example.com", Role: "employee", TokenVersion: 4}
+		userRepo.On("GetByID", userID).Return(user, nil)
+		userRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+			return u.Role == "moderator" && u.Disabled && u.TokenVersion == 5
+		})).Return(nil)
+		audit.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+			var details map[string]any
+			json.Unmarshal(entry.Details, &details)
+			return entry.Action == models.AuditUserUpdated && *entry.ActorID == moderatorID &&
+				*entry.UserID == userID && details["disabled"] == true
+		})).Return(nil)
+
+		updated, err := userService.UpdateUser(userID, models.UserUpdate{Role: &moderator, Disabled: &disabled}, moderatorID)
+		require.NoError(t, err)
+		assert.Equal(t, "moderator", updated.Role)
+		userRepo.AssertExpectations(t)
+		audit.AssertExpectations(t)
+	})
+
+	t.Run("No changes", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userService := service.NewUserService(userRepo, nil)
+
+		employee := models.EmployeeRole
+		userRepo.On("GetByID", userID).Return(&models.User{ID: userID, Role: "employee", TokenVersion: 4}, nil)
+
+		updated, err := userService.UpdateUser(userID, models.UserUpdate{Role: &employee}, moderatorID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, updated.TokenVersion, "токены не отзываются без изменений")
+		userRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userService := service.NewUserService(userRepo, nil)
+		userRepo.On("GetByID", mock.Anything).Return(nil, sql.ErrNoRows)
+
+		invalid := models.Role("admin")
+		_, err := userService.UpdateUser(userID, models.UserUpdate{Role: &invalid}, moderatorID)
+		assert.Equal(t, apperrors.ErrInvalidRole, err)
+
+		_, err = userService.UpdateUser(moderatorID, models.UserUpdate{Disabled: &disabled}, moderatorID)
+		assert.Equal(t, apperrors.ErrSelfModification, err)
+
+		_, err = userService.UpdateUser(userID, models.UserUpdate{Disabled: &disabled}, moderatorID)
+		assert.Equal(t, apperrors.ErrUserNotFound, err)
+	})
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	moderatorID := uuid.New()
+	userID := uuid.New()
+
+	userRepo := new(MockUserRepository)
+	audit := new(MockAuditRepository)
+	userService := service.NewUserService(userRepo, audit)
+
+	userRepo.On("GetByID", userID).Return(&models.User{ID: userID, Email: "user@example.com"}, nil).Once()
+	userRepo.On("GetByID", userID).Return(nil, sql.ErrNoRows)
+	userRepo.On("Delete", userID).Return(nil).Once()
+	audit.On("Record", mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditUserDeleted && entry.Email == "user@example.com"
+	})).Return(nil).Once()
+
+	require.NoError(t, userService.DeleteUser(userID, moderatorID))
+	assert.Equal(t, apperrors.ErrUserNotFound, userService.DeleteUser(userID, moderatorID))
+	assert.Equal(t, apperrors.ErrSelfModification, userService.DeleteUser(moderatorID, moderatorID))
+
+	userRepo.AssertExpectations(t)
+	audit.AssertExpectations(t)
+}
@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/service"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetSubscription(id uuid.UUID) (*models.WebhookSubscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListSubscriptions() ([]*models.WebhookSubscription, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(subscriptionID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(subscriptionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FanOutPendingEvents(limit int) (int, error) {
+	args := m.Called(limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.WebhookDispatch, error) {
+	args := m.Called(now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDispatch), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RecordAttempt(delivery *models.WebhookDelivery, attempt models.WebhookAttempt) error {
+	args := m.Called(delivery, attempt)
+	return args.Error(0)
+}
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	pvzID := uuid.New()
+	kazan := models.Kazan
+	unknownCity := models.City("Тверь")
+
+	tests := []struct {
+		name         string
+		input        models.WebhookSubscription
+		mockBehavior func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository)
+		wantErr      error
+	}{
+		{
+			name: "Success With Generated Secret",
+			input: models.WebhookSubscription{
+				URL:        "https://partner.example/hooks",
+				EventTypes: []models.EventType{models.ReceptionClosed, models.ReceptionClosed},
+				PVZID:      &pvzID,
+			},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {
+				pvzRepo.On("GetByID", pvzID).Return(&models.PVZ{ID: pvzID}, nil)
+				webhookRepo.On("CreateSubscription", mock.MatchedBy(func(subscription *models.WebhookSubscription) bool {
+					return len(subscription.Secret) == 64 &&
+						assert.ObjectsAreEqual([]models.EventType{models.ReceptionClosed}, subscription.EventTypes)
+				})).Return(nil)
+			},
+		},
+		{
+			name: "City Filter",
+			input: models.WebhookSubscription{
+				URL:        "http://partner.example/hooks",
+				Secret:     "partner-provided-secret",
+				EventTypes: []models.EventType{models.ProductAdded},
+				City:       &kazan,
+			},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {
+				webhookRepo.On("CreateSubscription", mock.AnythingOfType("*models.WebhookSubscription")).Return(nil)
+			},
+		},
+		{
+			name:         "Invalid URL",
+			input:        models.WebhookSubscription{URL: "ftp://partner.example", EventTypes: []models.EventType{models.ReceptionClosed}},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidWebhook,
+		},
+		{
+			name:         "Unknown Event Type",
+			input:        models.WebhookSubscription{URL: "https://partner.example", EventTypes: []models.EventType{"PVZDeleted"}},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidWebhook,
+		},
+		{
+			name: "Short Secret",
+			input: models.WebhookSubscription{
+				URL:        "https://partner.example",
+				Secret:     "123",
+				EventTypes: []models.EventType{models.ReceptionClosed},
+			},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidWebhook,
+		},
+		{
+			name: "Invalid City",
+			input: models.WebhookSubscription{
+				URL:        "https://partner.example",
+				EventTypes: []models.EventType{models.ReceptionClosed},
+				City:       &unknownCity,
+			},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {},
+			wantErr:      apperrors.ErrInvalidCity,
+		},
+		{
+			name: "PVZ Not Found",
+			input: models.WebhookSubscription{
+				URL:        "https://partner.example",
+				EventTypes: []models.EventType{models.ReceptionClosed},
+				PVZID:      &pvzID,
+			},
+			mockBehavior: func(webhookRepo *MockWebhookRepository, pvzRepo *MockPVZRepository) {
+				pvzRepo.On("GetByID", pvzID).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: apperrors.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := new(MockWebhookRepository)
+			pvzRepo := new(MockPVZRepository)
+			tt.mockBehavior(webhookRepo, pvzRepo)
+			service := service.NewWebhookService(webhookRepo, pvzRepo)
+
+			subscription, err := service.CreateSubscription(tt.input)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, subscription)
+			} else {
+				require.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, subscription.ID)
+				assert.NotEmpty(t, subscription.Secret)
+			}
+			webhookRepo.AssertExpectations(t)
+			pvzRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_ListSubscriptions_HidesSecret(t *testing.T) {
+	webhookRepo := new(MockWebhookRepository)
+	webhookRepo.On("ListSubscriptions").Return([]*models.WebhookSubscription{
+		{ID: uuid.New(), URL: "https://partner.example", Secret: "partner-provided-secret"},
+	}, nil)
+	service := service.NewWebhookService(webhookRepo, new(MockPVZRepository))
+
+	subscriptions, err := service.ListSubscriptions()
+
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Empty(t, subscriptions[0].Secret)
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	subscriptionID := uuid.New()
+
+	t.Run("Default Limit", func(t *testing.T) {
+		webhookRepo := new(MockWebhookRepository)
+		webhookRepo.On("GetSubscription", subscriptionID).Return(&models.WebhookSubscription{ID: subscriptionID}, nil)
+		webhookRepo.On("ListDeliveries", subscriptionID, 50).Return([]*models.WebhookDelivery{}, nil)
+		service := service.NewWebhookService(webhookRepo, new(MockPVZRepository))
+
+		deliveries, err := service.ListDeliveries(subscriptionID, 0)
+
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+		webhookRepo.AssertExpectations(t)
+	})
+
+	t.Run("Subscription Not Found", func(t *testing.T) {
+		webhookRepo := new(MockWebhookRepository)
+		webhookRepo.On("GetSubscription", subscriptionID).Return(nil, sql.ErrNoRows)
+		service := service.NewWebhookService(webhookRepo, new(MockPVZRepository))
+
+		_, err := service.ListDeliveries(subscriptionID, 10)
+
+		assert.Equal(t, apperrors.ErrWebhookNotFound, err)
+	})
+
+	t.Run("Limit Too Large", func(t *testing.T) {
+		service := service.NewWebhookService(new(MockWebhookRepository), new(MockPVZRepository))
+
+		_, err := service.ListDeliveries(subscriptionID, 1000)
+
+		assert.Equal(t, apperrors.ErrInvalidPagination, err)
+	})
+}
+
+func TestWebhookService_DeleteSubscription_NotFound(t *testing.T) {
+	id := uuid.New()
+	webhookRepo := new(MockWebhookRepository)
+	webhookRepo.On("DeleteSubscription", id).Return(sql.ErrNoRows)
+	service := service.NewWebhookService(webhookRepo, new(MockPVZRepository))
+
+	assert.Equal(t, apperrors.ErrWebhookNotFound, service.DeleteSubscription(id))
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxUsersLimit = 100
+
+type UserServiceInterface interface {
+	ListUsers(filter models.UserFilter) ([]*models.User, error)
+	GetUser(id uuid.UUID) (*models.User, error)
+	UpdateUser(id uuid.UUID, update models.UserUpdate, actorID uuid.UUID) (*models.User, error)
+	DeleteUser(id, actorID uuid.UUID) error
+}
+
+type UserService struct {
+	userRepo  repository.UserRepositoryInterface
+	auditRepo repository.AuditRepositoryInterface
+}
+
+// auditRepo может быть nil: тогда изменения не записываются в журнал
+func NewUserService(userRepo repository.UserRepositoryInterface, auditRepo repository.AuditRepositoryInterface) UserServiceInterface {
+	return &UserService{
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *UserService) ListUsers(filter models.UserFilter) ([]*models.User, error) {
+	if filter.Offset < 0 || filter.Limit < 1 || filter.Limit > maxUsersLimit {
+		return nil, apperrors.ErrInvalidPagination
+	}
+	if filter.Role != nil && !filter.Role.IsValid() {
+		return nil, apperrors.ErrInvalidRole
+	}
+
+	return s.userRepo.List(filter)
+}
+
+func (s *UserService) GetUser(id uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrUserNotFound
+	}
+	return user, err
+}
+
+// Смена роли и отключение увеличивают версию токена: роль зашита в JWT, и старые токены
+// иначе действовали бы со старыми правами до истечения срока
+func (s *UserService) UpdateUser(id uuid.UUID, update models.UserUpdate, actorID uuid.UUID) (*models.User, error) {
+	if update.Role != nil && !update.Role.IsValid() {
+		return nil, apperrors.ErrInvalidRole
+	}
+	// Модератор не может снять с себя роль или отключиться и остаться без доступа
+	if id == actorID {
+		return nil, apperrors.ErrSelfModification
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]any)
+	if update.Role != nil && string(*update.Role) != user.Role {
+		changes["role"] = map[string]string{"from": user.Role, "to": string(*update.Role)}
+		user.Role = string(*update.Role)
+	}
+	if update.Disabled != nil && *update.Disabled != user.Disabled {
+		changes["disabled"] = *update.Disabled
+		user.Disabled = *update.Disabled
+	}
+	if len(changes) == 0 {
+		return user, nil
+	}
+
+	user.TokenVersion++
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(changes)
+	s.audit(&models.AuditEntry{Action: models.AuditUserUpdated, ActorID: actorRef(actorID), UserID: &user.ID, Email: user.Email, Details: details})
+	return user, nil
+}
+
+func (s *UserService) DeleteUser(id, actorID uuid.UUID) error {
+	if id == actorID {
+		return apperrors.ErrSelfModification
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.audit(&models.AuditEntry{Action: models.AuditUserDeleted, ActorID: actorRef(actorID), UserID: &user.ID, Email: user.Email})
+	return nil
+}
+
+func (s *UserService) audit(entry *models.AuditEntry) {
+	if s.auditRepo == nil {
+		return
+	}
+	entry.CreatedAt = time.Now()
+	if err := s.auditRepo.Record(entry); err != nil {
+		slog.Error("ошибка записи в журнал аудита", "action", entry.Action, "error", err)
+	}
+}
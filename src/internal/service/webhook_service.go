@@ -0,0 +1,176 @@
+package service
+
+import (
+	"avito-backend/src/internal/apperrors"
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/repository"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minWebhookSecretLength = 16
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type WebhookServiceInterface interface {
+	CreateSubscription(input models.WebhookSubscription) (*models.WebhookSubscription, error)
+	GetSubscription(id uuid.UUID) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]*models.WebhookSubscription, error)
+	DeleteSubscription(id uuid.UUID) error
+	ListDeliveries(subscriptionID uuid.UUID, limit int) ([]*models.WebhookDelivery, error)
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepositoryInterface
+	pvzRepo     repository.PVZRepositoryInterface
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepositoryInterface, pvzRepo repository.PVZRepositoryInterface) WebhookServiceInterface {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		pvzRepo:     pvzRepo,
+	}
+}
+
+// Секрет возвращается только при создании; если он не передан, генерируется случайный
+func (s *WebhookService) CreateSubscription(input models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	if err := validateWebhookURL(input.URL); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeEventTypes(input.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.City != nil && !input.City.IsValid() {
+		return nil, apperrors.ErrInvalidCity
+	}
+
+	if input.PVZID != nil {
+		if _, err := s.pvzRepo.GetByID(*input.PVZID); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, apperrors.ErrPVZNotFound
+			}
+			return nil, err
+		}
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(secret) < minWebhookSecretLength {
+		return nil, apperrors.ErrInvalidWebhook
+	}
+
+	subscription := &models.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        input.URL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		PVZID:      input.PVZID,
+		City:       input.City,
+		CreatedAt:  time.Now(),
+		CreatedBy:  input.CreatedBy,
+	}
+
+	if err := s.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *WebhookService) GetSubscription(id uuid.UUID) (*models.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.GetSubscription(id)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (s *WebhookService) ListSubscriptions() ([]*models.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.ListSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (s *WebhookService) DeleteSubscription(id uuid.UUID) error {
+	err := s.webhookRepo.DeleteSubscription(id)
+	if err == sql.ErrNoRows {
+		return apperrors.ErrWebhookNotFound
+	}
+	return err
+}
+
+func (s *WebhookService) ListDeliveries(subscriptionID uuid.UUID, limit int) ([]*models.WebhookDelivery, error) {
+	if limit == 0 {
+		limit = defaultDeliveriesLimit
+	}
+	if limit < 0 || limit > maxDeliveriesLimit {
+		return nil, apperrors.ErrInvalidPagination
+	}
+
+	if _, err := s.GetSubscription(subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.ListDeliveries(subscriptionID, limit)
+}
+
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return apperrors.ErrInvalidWebhook
+	}
+	return nil
+}
+
+func normalizeEventTypes(eventTypes []models.EventType) ([]models.EventType, error) {
+	if len(eventTypes) == 0 {
+		return nil, apperrors.ErrInvalidWebhook
+	}
+
+	seen := make(map[models.EventType]bool, len(eventTypes))
+	result := make([]models.EventType, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !eventType.IsValid() {
+			return nil, apperrors.ErrInvalidWebhook
+		}
+		if seen[eventType] {
+			continue
+		}
+		seen[eventType] = true
+		result = append(result, eventType)
+	}
+
+	return result, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,200 @@
+package stream
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	pollBatchSize    = 1000
+	subscriberBuffer = 256
+)
+
+type EventSource interface {
+	LatestEventID() (int64, error)
+	ListEventsAfter(afterID int64, filter models.EventFilter, limit int) ([]*models.OutboxEvent, error)
+}
+
+// Раздает события из outbox подписчикам потоков. Таблицу опрашивает каждая реплика сама,
+// поэтому клиент получает события независимо от того, к какой реплике подключен
+type Hub struct {
+	source   EventSource
+	interval time.Duration
+	// Сколько ждать событие с пропущенным идентификатором: транзакция с меньшим id может
+	// зафиксироваться позже, а может откатиться, и тогда id не появится никогда
+	gapTimeout time.Duration
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+
+	started  bool
+	cursor   int64
+	seen     map[int64]bool
+	gapSince time.Time
+}
+
+type Subscription struct {
+	filter models.EventFilter
+	events chan *models.OutboxEvent
+}
+
+// Канал закрывается, если подписчик не успевает читать события; клиент переподключается с Last-Event-ID
+func (s *Subscription) Events() <-chan *models.OutboxEvent {
+	return s.events
+}
+
+func NewHub(source EventSource, interval, gapTimeout time.Duration) *Hub {
+	return &Hub{
+		source:      source,
+		interval:    interval,
+		gapTimeout:  gapTimeout,
+		subscribers: make(map[*Subscription]struct{}),
+		seen:        make(map[int64]bool),
+	}
+}
+
+func (h *Hub) Subscribe(filter models.EventFilter) *Subscription {
+	subscription := &Subscription{
+		filter: filter,
+		events: make(chan *models.OutboxEvent, subscriberBuffer),
+	}
+
+	h.mu.Lock()
+	h.subscribers[subscription] = struct{}{}
+	h.mu.Unlock()
+	metrics.EventStreamsActive.Inc()
+
+	return subscription
+}
+
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(subscription)
+}
+
+func (h *Hub) Run(ctx context.Context) {
+	if h.interval <= 0 {
+		slog.InfoContext(ctx, "потоки событий ПВЗ отключены")
+		return
+	}
+
+	slog.InfoContext(ctx, "запущена раздача событий в потоки", "interval", h.interval.String())
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.Poll(time.Now()); err != nil {
+			slog.ErrorContext(ctx, "ошибка чтения событий для потоков", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			h.closeAll()
+			slog.InfoContext(ctx, "раздача событий в потоки остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Рассылает новые события и сдвигает курсор. Вызывается только из Run, экспортирован для тестов
+func (h *Hub) Poll(now time.Time) error {
+	// Начинаем с конца таблицы: старые события клиенты получают из БД по Last-Event-ID
+	if !h.started {
+		latest, err := h.source.LatestEventID()
+		if err != nil {
+			return err
+		}
+		h.cursor = latest
+		h.started = true
+		return nil
+	}
+
+	events, err := h.source.ListEventsAfter(h.cursor, models.EventFilter{}, pollBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if h.seen[event.ID] {
+			continue
+		}
+		h.seen[event.ID] = true
+		h.broadcast(event)
+	}
+	h.advance(now)
+
+	return nil
+}
+
+func (h *Hub) advance(now time.Time) {
+	for len(h.seen) > 0 {
+		next := h.cursor + 1
+		if h.seen[next] {
+			delete(h.seen, next)
+			h.cursor = next
+			h.gapSince = time.Time{}
+			continue
+		}
+
+		if h.gapSince.IsZero() {
+			h.gapSince = now
+			return
+		}
+		if now.Sub(h.gapSince) < h.gapTimeout {
+			return
+		}
+
+		// Пропуск так и не заполнился, перескакиваем к следующему известному событию
+		lowest := int64(0)
+		for id := range h.seen {
+			if lowest == 0 || id < lowest {
+				lowest = id
+			}
+		}
+		h.cursor = lowest - 1
+		h.gapSince = time.Time{}
+	}
+}
+
+func (h *Hub) broadcast(event *models.OutboxEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			slog.Warn("подписчик потока событий не успевает читать, соединение будет закрыто")
+			h.remove(subscription)
+		}
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers {
+		h.remove(subscription)
+	}
+}
+
+// Вызывается под h.mu
+func (h *Hub) remove(subscription *Subscription) {
+	if _, ok := h.subscribers[subscription]; !ok {
+		return
+	}
+	delete(h.subscribers, subscription)
+	close(subscription.events)
+	metrics.EventStreamsActive.Dec()
+}
@@ -0,0 +1,188 @@
+package stream_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/stream"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Таблица outbox в памяти; события можно добавлять в любом порядке, как при конкурентных транзакциях
+type memorySource struct {
+	mu     sync.Mutex
+	events []*models.OutboxEvent
+	err    error
+}
+
+func (s *memorySource) add(events ...*models.OutboxEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	sort.Slice(s.events, func(i, j int) bool { return s.events[i].ID < s.events[j].ID })
+}
+
+func (s *memorySource) LatestEventID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if len(s.events) == 0 {
+		return 0, nil
+	}
+	return s.events[len(s.events)-1].ID, nil
+}
+
+func (s *memorySource) ListEventsAfter(afterID int64, filter models.EventFilter, limit int) ([]*models.OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	result := make([]*models.OutboxEvent, 0)
+	for _, event := range s.events {
+		if event.ID > afterID && filter.Matches(event) && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func newEvent(id int64, pvzID uuid.UUID, city models.City) *models.OutboxEvent {
+	return &models.OutboxEvent{ID: id, EventID: uuid.New(), Type: models.ProductAdded, PVZID: pvzID, City: city}
+}
+
+func receiveIDs(subscription *stream.Subscription) []int64 {
+	ids := make([]int64, 0)
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHub_StartsFromLatestEvent(t *testing.T) {
+	pvzID := uuid.New()
+	source := &memorySource{}
+	source.add(newEvent(1, pvzID, models.Moscow), newEvent(2, pvzID, models.Moscow))
+
+	hub := stream.NewHub(source, time.Second, 5*time.Second)
+	subscription := hub.Subscribe(models.EventFilter{})
+	defer hub.Unsubscribe(subscription)
+
+	now := time.Now()
+	require.NoError(t, hub.Poll(now))
+	source.add(newEvent(3, pvzID, models.Moscow))
+	require.NoError(t, hub.Poll(now))
+
+	assert.Equal(t, []int64{3}, receiveIDs(subscription))
+}
+
+func TestHub_Filter(t *testing.T) {
+	moscowPVZ := uuid.New()
+	kazanPVZ := uuid.New()
+	source := &memorySource{}
+
+	hub := stream.NewHub(source, time.Second, 5*time.Second)
+	byPVZ := hub.Subscribe(models.EventFilter{PVZID: &moscowPVZ})
+	city := models.Kazan
+	byCity := hub.Subscribe(models.EventFilter{City: &city})
+
+	now := time.Now()
+	require.NoError(t, hub.Poll(now))
+	source.add(newEvent(1, moscowPVZ, models.Moscow), newEvent(2, kazanPVZ, models.Kazan))
+	require.NoError(t, hub.Poll(now))
+
+	assert.Equal(t, []int64{1}, receiveIDs(byPVZ))
+	assert.Equal(t, []int64{2}, receiveIDs(byCity))
+}
+
+func TestHub_LateCommittedEvent(t *testing.T) {
+	pvzID := uuid.New()
+	source := &memorySource{}
+
+	hub := stream.NewHub(source, time.Second, 5*time.Second)
+	subscription := hub.Subscribe(models.EventFilter{})
+	defer hub.Unsubscribe(subscription)
+
+	now := time.Now()
+	require.NoError(t, hub.Poll(now))
+
+	// Событие 1 еще не зафиксировано, 2 уже видно
+	source.add(newEvent(2, pvzID, models.Moscow))
+	require.NoError(t, hub.Poll(now))
+	assert.Equal(t, []int64{2}, receiveIDs(subscription))
+
+	source.add(newEvent(1, pvzID, models.Moscow))
+	require.NoError(t, hub.Poll(now.Add(time.Second)))
+	require.NoError(t, hub.Poll(now.Add(2*time.Second)))
+
+	assert.Equal(t, []int64{1}, receiveIDs(subscription), "событие 2 не должно прийти повторно")
+}
+
+func TestHub_SkipsGapAfterTimeout(t *testing.T) {
+	pvzID := uuid.New()
+	source := &memorySource{}
+
+	hub := stream.NewHub(source, time.Second, 5*time.Second)
+	subscription := hub.Subscribe(models.EventFilter{})
+	defer hub.Unsubscribe(subscription)
+
+	now := time.Now()
+	require.NoError(t, hub.Poll(now))
+
+	// Транзакция с id 1 откатилась
+	source.add(newEvent(2, pvzID, models.Moscow))
+	require.NoError(t, hub.Poll(now))
+	require.NoError(t, hub.Poll(now.Add(6*time.Second)))
+	assert.Equal(t, []int64{2}, receiveIDs(subscription))
+
+	// Даже если событие с пропущенным id появится после таймаута, курсор уже сдвинут
+	source.add(newEvent(1, pvzID, models.Moscow), newEvent(3, pvzID, models.Moscow))
+	require.NoError(t, hub.Poll(now.Add(7*time.Second)))
+	assert.Equal(t, []int64{3}, receiveIDs(subscription))
+}
+
+func TestHub_DropsSlowSubscriber(t *testing.T) {
+	pvzID := uuid.New()
+	source := &memorySource{}
+
+	hub := stream.NewHub(source, time.Second, 5*time.Second)
+	subscription := hub.Subscribe(models.EventFilter{})
+
+	now := time.Now()
+	require.NoError(t, hub.Poll(now))
+	for id := int64(1); id <= 300; id++ {
+		source.add(newEvent(id, pvzID, models.Moscow))
+	}
+	require.NoError(t, hub.Poll(now))
+
+	received := 0
+	for range subscription.Events() {
+		received++
+	}
+	assert.Less(t, received, 300)
+
+	// Повторная отписка после закрытия канала безопасна
+	hub.Unsubscribe(subscription)
+}
+
+func TestHub_SourceError(t *testing.T) {
+	source := &memorySource{err: errors.New("db down")}
+	hub := stream.NewHub(source, time.Second, 5*time.Second)
+
+	assert.Error(t, hub.Poll(time.Now()))
+}
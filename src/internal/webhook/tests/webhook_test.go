@@ -0,0 +1,83 @@
+package webhook_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/webhook"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"ReceptionClosed"}`)
+
+	signature := webhook.Sign("secret", 1700000000, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.True(t, webhook.Verify("secret", 1700000000, body, signature))
+	assert.False(t, webhook.Verify("other-secret", 1700000000, body, signature))
+	assert.False(t, webhook.Verify("secret", 1700000001, body, signature))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := webhook.RetryPolicy{MaxAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 3 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.Delay(1))
+	assert.Equal(t, time.Minute, policy.Delay(2))
+	assert.Equal(t, 2*time.Minute, policy.Delay(3))
+	assert.Equal(t, 3*time.Minute, policy.Delay(4))
+	assert.Equal(t, 3*time.Minute, policy.Delay(50))
+	assert.False(t, policy.Exhausted(4))
+	assert.True(t, policy.Exhausted(5))
+}
+
+func TestSender_Send(t *testing.T) {
+	dispatch := &models.WebhookDispatch{
+		Delivery: &models.WebhookDelivery{
+			ID:        uuid.New(),
+			EventType: models.ReceptionClosed,
+			Payload:   []byte(`{"type":"ReceptionClosed"}`),
+		},
+		Secret: "partner-secret",
+	}
+
+	t.Run("Signed Request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, err := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+			require.NoError(t, err)
+			assert.True(t, webhook.Verify("partner-secret", timestamp, body, r.Header.Get(webhook.SignatureHeader)))
+			assert.Equal(t, dispatch.Delivery.ID.String(), r.Header.Get(webhook.DeliveryHeader))
+			assert.Equal(t, "ReceptionClosed", r.Header.Get(webhook.EventTypeHeader))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+		dispatch.URL = server.URL
+
+		code, err := webhook.NewSender(time.Second).Send(context.Background(), dispatch)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+	})
+
+	t.Run("Partner Error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		dispatch.URL = server.URL
+
+		code, err := webhook.NewSender(time.Second).Send(context.Background(), dispatch)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadGateway, code)
+	})
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/outbox"
+	"avito-backend/src/internal/repository"
+	"avito-backend/src/internal/webhook"
+	"avito-backend/src/pkg/logger"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Переносит события из таблицы outbox в Publisher. Событие помечается доставленным
+// только после успешной публикации, поэтому возможны повторы (at-least-once).
+// Неудачные события повторяются с экспоненциальной задержкой, а после policy.MaxAttempts попыток уходят в dead letter
+type OutboxRelay struct {
+	repo      repository.OutboxRepositoryInterface
+	publisher outbox.Publisher
+	policy    webhook.RetryPolicy
+	interval  time.Duration
+	batchSize int
+	lease     time.Duration
+	now       func() time.Time
+}
+
+// lease должен превышать время отправки всей пачки, иначе события может захватить другая реплика
+func NewOutboxRelay(repo repository.OutboxRepositoryInterface, publisher outbox.Publisher, policy webhook.RetryPolicy, interval time.Duration, batchSize int, lease time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		policy:    policy,
+		interval:  interval,
+		batchSize: batchSize,
+		lease:     lease,
+		now:       time.Now,
+	}
+}
+
+func (w *OutboxRelay) Run(ctx context.Context) {
+	if w.interval <= 0 || w.batchSize <= 0 {
+		slog.InfoContext(ctx, "доставка доменных событий отключена")
+		return
+	}
+
+	slog.InfoContext(ctx, "запущена доставка доменных событий", "interval", w.interval.String(), "batch_size", w.batchSize,
+		"max_attempts", w.policy.MaxAttempts)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// Полная пачка означает, что в очереди могут остаться события, поэтому ждать тикера не нужно
+		if w.RunOnce(ctx) == w.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "доставка доменных событий остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Возвращает количество захваченных событий
+func (w *OutboxRelay) RunOnce(ctx context.Context) int {
+	now := w.now()
+	events, err := w.repo.ClaimPending(now, now.Add(w.lease), w.batchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "ошибка выборки доменных событий", "error", err)
+		return 0
+	}
+
+	// После неудачи остальные события этого ПВЗ из пачки возвращаются в очередь, чтобы не нарушить порядок
+	blocked := make(map[uuid.UUID]bool)
+	released := make([]int64, 0)
+	for _, event := range events {
+		if blocked[event.PVZID] || ctx.Err() != nil {
+			released = append(released, event.ID)
+			continue
+		}
+
+		if err := w.publisher.Publish(ctx, event); err != nil {
+			if ctx.Err() != nil {
+				// Остановка сервиса не считается попыткой
+				released = append(released, event.ID)
+				continue
+			}
+			if !w.fail(ctx, event, err) {
+				blocked[event.PVZID] = true
+			}
+			continue
+		}
+
+		if err := w.repo.MarkPublished(event.ID); err != nil {
+			// Событие вернется после истечения аренды и будет отправлено повторно
+			slog.ErrorContext(ctx, "ошибка отметки доставки доменного события", "event_id", event.EventID, "error", err)
+			blocked[event.PVZID] = true
+			continue
+		}
+		metrics.OutboxEventsPublishedTotal.WithLabelValues(string(event.Type)).Inc()
+	}
+
+	if err := w.repo.Release(released); err != nil {
+		slog.ErrorContext(ctx, "ошибка возврата доменных событий в очередь", "error", err)
+	}
+	return len(events)
+}
+
+// Сохраняет неудачную попытку. Возвращает true, если событие ушло в dead letter и больше не задерживает свой ПВЗ
+func (w *OutboxRelay) fail(ctx context.Context, event *models.OutboxEvent, publishErr error) bool {
+	ctx = logger.WithPVZID(ctx, event.PVZID.String())
+	attempt := event.Attempts + 1
+	logArgs := []any{"event_id", event.EventID, "event_type", event.Type, "attempt", attempt, "error", publishErr}
+	metrics.OutboxPublishFailuresTotal.Inc()
+
+	if w.policy.Exhausted(attempt) {
+		metrics.OutboxEventsDeadTotal.Inc()
+		slog.ErrorContext(ctx, "доменное событие не доставлено, попытки исчерпаны", logArgs...)
+		if err := w.repo.MarkDead(event.ID, publishErr.Error()); err != nil {
+			slog.ErrorContext(ctx, "ошибка сохранения попытки доставки доменного события", "event_id", event.EventID, "error", err)
+			return false
+		}
+		return true
+	}
+
+	nextAttemptAt := w.now().Add(w.policy.Delay(attempt))
+	slog.WarnContext(ctx, "ошибка доставки доменного события, будет повтор", append(logArgs, "next_attempt_at", nextAttemptAt)...)
+	if err := w.repo.MarkFailed(event.ID, publishErr.Error(), nextAttemptAt); err != nil {
+		slog.ErrorContext(ctx, "ошибка сохранения попытки доставки доменного события", "event_id", event.EventID, "error", err)
+	}
+	return false
+}
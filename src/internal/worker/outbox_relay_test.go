@@ -0,0 +1,160 @@
+package worker_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/webhook"
+	"avito-backend/src/internal/worker"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOutbox struct {
+	events     []*models.OutboxEvent
+	leaseUntil time.Time
+	published  []int64
+	failed     map[int64]time.Time
+	dead       []int64
+	released   []int64
+}
+
+func (f *fakeOutbox) ClaimPending(now, leaseUntil time.Time, limit int) ([]*models.OutboxEvent, error) {
+	f.leaseUntil = leaseUntil
+	if len(f.events) > limit {
+		return f.events[:limit], nil
+	}
+	return f.events, nil
+}
+
+func (f *fakeOutbox) MarkPublished(id int64) error {
+	f.published = append(f.published, id)
+	return nil
+}
+
+func (f *fakeOutbox) MarkFailed(id int64, reason string, nextAttemptAt time.Time) error {
+	if f.failed == nil {
+		f.failed = make(map[int64]time.Time)
+	}
+	f.failed[id] = nextAttemptAt
+	return nil
+}
+
+func (f *fakeOutbox) MarkDead(id int64, reason string) error {
+	f.dead = append(f.dead, id)
+	return nil
+}
+
+func (f *fakeOutbox) Release(ids []int64) error {
+	f.released = append(f.released, ids...)
+	return nil
+}
+
+type fakePublisher struct {
+	failing map[int64]bool
+	sent    []int64
+	cancel  context.CancelFunc
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	if p.cancel != nil {
+		p.cancel()
+		return ctx.Err()
+	}
+	if p.failing[event.ID] {
+		return errors.New("receiver unavailable")
+	}
+	p.sent = append(p.sent, event.ID)
+	return nil
+}
+
+var relayPolicy = webhook.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+func TestOutboxRelay_RunOnce_KeepsOrderPerPVZ(t *testing.T) {
+	first := uuid.New()
+	second := uuid.New()
+	repo := &fakeOutbox{
+		events: []*models.OutboxEvent{
+			{ID: 1, PVZID: first, Type: models.ReceptionCreated},
+			{ID: 2, PVZID: second, Type: models.ReceptionCreated},
+			{ID: 3, PVZID: first, Type: models.ProductAdded},
+			{ID: 4, PVZID: second, Type: models.ProductAdded},
+		},
+	}
+	publisher := &fakePublisher{failing: map[int64]bool{1: true}}
+	relay := worker.NewOutboxRelay(repo, publisher, relayPolicy, time.Second, 10, time.Minute)
+
+	started := time.Now()
+	assert.Equal(t, 4, relay.RunOnce(context.Background()))
+
+	// Событие 3 не отправляется, пока не доставлено предшествующее ему событие 1, и возвращается в очередь
+	assert.Equal(t, []int64{2, 4}, publisher.sent)
+	assert.Equal(t, []int64{2, 4}, repo.published)
+	assert.Equal(t, []int64{3}, repo.released)
+	assert.Empty(t, repo.dead)
+	assert.WithinDuration(t, started.Add(time.Second), repo.failed[1], time.Second)
+	assert.WithinDuration(t, started.Add(time.Minute), repo.leaseUntil, time.Second)
+}
+
+func TestOutboxRelay_RunOnce_DeadLetterUnblocksPVZ(t *testing.T) {
+	pvzID := uuid.New()
+	repo := &fakeOutbox{
+		events: []*models.OutboxEvent{
+			{ID: 1, PVZID: pvzID, Type: models.ReceptionCreated, Attempts: 2},
+			{ID: 2, PVZID: pvzID, Type: models.ProductAdded},
+		},
+	}
+	publisher := &fakePublisher{failing: map[int64]bool{1: true}}
+	relay := worker.NewOutboxRelay(repo, publisher, relayPolicy, time.Second, 10, time.Minute)
+
+	relay.RunOnce(context.Background())
+
+	// Третья неудачная попытка исчерпывает лимит, и следующее событие ПВЗ больше не ждет
+	assert.Equal(t, []int64{1}, repo.dead)
+	assert.Empty(t, repo.failed)
+	assert.Equal(t, []int64{2}, repo.published)
+	assert.Empty(t, repo.released)
+}
+
+func TestOutboxRelay_RunOnce_ShutdownReleasesEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pvzID := uuid.New()
+	repo := &fakeOutbox{
+		events: []*models.OutboxEvent{
+			{ID: 1, PVZID: pvzID, Type: models.ReceptionCreated},
+			{ID: 2, PVZID: uuid.New(), Type: models.ReceptionCreated},
+		},
+	}
+	relay := worker.NewOutboxRelay(repo, &fakePublisher{cancel: cancel}, relayPolicy, time.Second, 10, time.Minute)
+
+	relay.RunOnce(ctx)
+
+	// Прерванная остановкой отправка не считается попыткой
+	assert.Empty(t, repo.failed)
+	assert.Empty(t, repo.published)
+	assert.Equal(t, []int64{1, 2}, repo.released)
+}
+
+func TestOutboxRelay_RunOnce_NothingClaimed(t *testing.T) {
+	repo := &fakeOutbox{}
+	publisher := &fakePublisher{}
+	relay := worker.NewOutboxRelay(repo, publisher, relayPolicy, time.Second, 10, time.Minute)
+
+	assert.Equal(t, 0, relay.RunOnce(context.Background()))
+	assert.Empty(t, publisher.sent)
+}
+
+func TestOutboxRelay_Run_Disabled(t *testing.T) {
+	repo := &fakeOutbox{events: []*models.OutboxEvent{{ID: 1, PVZID: uuid.New()}}}
+	publisher := &fakePublisher{}
+	relay := worker.NewOutboxRelay(repo, publisher, relayPolicy, 0, 10, time.Minute)
+
+	relay.Run(context.Background())
+
+	assert.Empty(t, publisher.sent)
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/stream"
+	"context"
+	"log/slog"
+)
+
+type PVZCache interface {
+	InvalidateEvent(event *models.OutboxEvent)
+	InvalidateAll(reason string)
+}
+
+// Инвалидирует кеш списка ПВЗ по событиям outbox. События читает хаб потоков, поэтому кеш
+// узнает и об изменениях, сделанных другими экземплярами сервиса
+type PVZCacheInvalidator struct {
+	hub   *stream.Hub
+	cache PVZCache
+}
+
+func NewPVZCacheInvalidator(hub *stream.Hub, cache PVZCache) *PVZCacheInvalidator {
+	return &PVZCacheInvalidator{hub: hub, cache: cache}
+}
+
+func (w *PVZCacheInvalidator) Run(ctx context.Context) {
+	slog.InfoContext(ctx, "запущена инвалидация кеша ПВЗ по событиям")
+
+	for {
+		subscription := w.hub.Subscribe(models.EventFilter{})
+		if !w.consume(ctx, subscription) {
+			slog.InfoContext(ctx, "инвалидация кеша ПВЗ по событиям остановлена")
+			return
+		}
+
+		// Хаб отключает отстающих подписчиков; пропущенные события могли изменить любую страницу
+		slog.WarnContext(ctx, "подписка кеша ПВЗ на события прервана, кеш сброшен")
+		w.cache.InvalidateAll("resubscribe")
+	}
+}
+
+// Возвращает false, если работа остановлена
+func (w *PVZCacheInvalidator) consume(ctx context.Context, subscription *stream.Subscription) bool {
+	defer w.hub.Unsubscribe(subscription)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return ctx.Err() == nil
+			}
+			w.cache.InvalidateEvent(event)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package worker_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/stream"
+	"avito-backend/src/internal/worker"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventSource struct {
+	mu     sync.Mutex
+	events []*models.OutboxEvent
+}
+
+func (s *eventSource) LatestEventID() (int64, error) {
+	return 0, nil
+}
+
+func (s *eventSource) ListEventsAfter(afterID int64, filter models.EventFilter, limit int) ([]*models.OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]*models.OutboxEvent, 0)
+	for _, event := range s.events {
+		if event.ID > afterID && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+type fakePVZCache struct {
+	mu     sync.Mutex
+	pvzIDs []uuid.UUID
+}
+
+func (c *fakePVZCache) InvalidateEvent(event *models.OutboxEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pvzIDs = append(c.pvzIDs, event.PVZID)
+}
+
+func (c *fakePVZCache) InvalidateAll(reason string) {}
+
+func (c *fakePVZCache) invalidated() []uuid.UUID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uuid.UUID(nil), c.pvzIDs...)
+}
+
+func TestPVZCacheInvalidator_Run(t *testing.T) {
+	source := &eventSource{}
+	hub := stream.NewHub(source, time.Hour, time.Second)
+	require.NoError(t, hub.Poll(time.Now()))
+
+	fake := &fakePVZCache{}
+	streams := testutil.ToFloat64(metrics.EventStreamsActive)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.NewPVZCacheInvalidator(hub, fake).Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.EventStreamsActive) == streams+1
+	}, time.Second, 10*time.Millisecond)
+
+	pvzID := uuid.New()
+	source.mu.Lock()
+	source.events = append(source.events, &models.OutboxEvent{ID: 1, Type: models.ProductAdded, PVZID: pvzID})
+	source.mu.Unlock()
+	require.NoError(t, hub.Poll(time.Now()))
+
+	require.Eventually(t, func() bool { return len(fake.invalidated()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, pvzID, fake.invalidated()[0])
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("инвалидация не остановилась")
+	}
+	assert.Equal(t, streams, testutil.ToFloat64(metrics.EventStreamsActive))
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/pkg/logger"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"log/slog"
+	"time"
+)
+
+type StaleReceptionService interface {
+	CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error)
+}
+
+// Периодически закрывает приемки, которые сотрудники забыли закрыть в конце смены
+type StaleReceptionCloser struct {
+	service  StaleReceptionService
+	idleFor  time.Duration
+	interval time.Duration
+}
+
+func NewStaleReceptionCloser(service StaleReceptionService, idleFor, interval time.Duration) *StaleReceptionCloser {
+	return &StaleReceptionCloser{
+		service:  service,
+		idleFor:  idleFor,
+		interval: interval,
+	}
+}
+
+func (w *StaleReceptionCloser) Run(ctx context.Context) {
+	if w.idleFor <= 0 || w.interval <= 0 {
+		slog.InfoContext(ctx, "автозакрытие приемок отключено")
+		return
+	}
+
+	slog.InfoContext(ctx, "запущено автозакрытие приемок", "idle_for", w.idleFor.String(), "interval", w.interval.String())
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "автозакрытие приемок остановлено")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *StaleReceptionCloser) RunOnce(ctx context.Context) int {
+	closed, err := w.service.CloseStaleReceptions(w.idleFor)
+	for _, reception := range closed {
+		receptionCtx := logger.WithPVZID(ctx, reception.PVZID.String())
+		slog.WarnContext(receptionCtx, "приемка закрыта автоматически по неактивности",
+			"reception_id", reception.ID, "opened_at", reception.DateTime)
+	}
+	metrics.ReceptionsAutoClosedTotal.Add(float64(len(closed)))
+
+	if err != nil {
+		slog.ErrorContext(ctx, "ошибка автозакрытия приемок", "error", err)
+	}
+	return len(closed)
+}
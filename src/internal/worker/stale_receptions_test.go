@@ -0,0 +1,92 @@
+package worker_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/worker"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStaleService struct {
+	calls   atomic.Int32
+	idleFor time.Duration
+	closed  []*models.Reception
+	err     error
+}
+
+func (f *fakeStaleService) CloseStaleReceptions(idleFor time.Duration) ([]*models.Reception, error) {
+	f.calls.Add(1)
+	f.idleFor = idleFor
+	return f.closed, f.err
+}
+
+func TestStaleReceptionCloser_RunOnce(t *testing.T) {
+	fake := &fakeStaleService{
+		closed: []*models.Reception{
+			{ID: uuid.New(), PVZID: uuid.New(), Status: models.Closed, ClosedBySystem: true},
+			{ID: uuid.New(), PVZID: uuid.New(), Status: models.Closed, ClosedBySystem: true},
+		},
+	}
+	closer := worker.NewStaleReceptionCloser(fake, 4*time.Hour, time.Minute)
+	before := testutil.ToFloat64(metrics.ReceptionsAutoClosedTotal)
+
+	count := closer.RunOnce(context.Background())
+
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 4*time.Hour, fake.idleFor)
+	assert.Equal(t, before+2, testutil.ToFloat64(metrics.ReceptionsAutoClosedTotal))
+}
+
+func TestStaleReceptionCloser_RunOnce_PartialFailure(t *testing.T) {
+	fake := &fakeStaleService{
+		closed: []*models.Reception{{ID: uuid.New(), PVZID: uuid.New(), Status: models.Closed}},
+		err:    errors.New("db error"),
+	}
+	closer := worker.NewStaleReceptionCloser(fake, time.Hour, time.Minute)
+	before := testutil.ToFloat64(metrics.ReceptionsAutoClosedTotal)
+
+	count := closer.RunOnce(context.Background())
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.ReceptionsAutoClosedTotal))
+}
+
+func TestStaleReceptionCloser_Run(t *testing.T) {
+	t.Run("Stops On Context Cancel", func(t *testing.T) {
+		fake := &fakeStaleService{}
+		closer := worker.NewStaleReceptionCloser(fake, time.Hour, 10*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			closer.Run(ctx)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool { return fake.calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("воркер не остановился после отмены контекста")
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		fake := &fakeStaleService{}
+		closer := worker.NewStaleReceptionCloser(fake, 0, time.Minute)
+
+		closer.Run(context.Background())
+
+		assert.Equal(t, int32(0), fake.calls.Load())
+	})
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/webhook"
+	"avito-backend/src/pkg/metrics"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type WebhookStore interface {
+	ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.WebhookDispatch, error)
+	RecordAttempt(delivery *models.WebhookDelivery, attempt models.WebhookAttempt) error
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, dispatch *models.WebhookDispatch) (int, error)
+}
+
+// Отправляет доставки вебхуков с экспоненциальными повторами
+type WebhookDispatcher struct {
+	store     WebhookStore
+	sender    WebhookSender
+	policy    webhook.RetryPolicy
+	interval  time.Duration
+	batchSize int
+	lease     time.Duration
+	now       func() time.Time
+}
+
+// lease должен превышать таймаут отправки, иначе доставку может захватить другая реплика
+func NewWebhookDispatcher(store WebhookStore, sender WebhookSender, policy webhook.RetryPolicy, interval time.Duration, batchSize int, lease time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		store:     store,
+		sender:    sender,
+		policy:    policy,
+		interval:  interval,
+		batchSize: batchSize,
+		lease:     lease,
+		now:       time.Now,
+	}
+}
+
+func (w *WebhookDispatcher) Run(ctx context.Context) {
+	if w.interval <= 0 || w.batchSize <= 0 {
+		slog.InfoContext(ctx, "отправка вебхуков отключена")
+		return
+	}
+
+	slog.InfoContext(ctx, "запущена отправка вебхуков", "interval", w.interval.String(), "max_attempts", w.policy.MaxAttempts)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if w.RunOnce(ctx) == w.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "отправка вебхуков остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Возвращает количество обработанных доставок
+func (w *WebhookDispatcher) RunOnce(ctx context.Context) int {
+	now := w.now()
+	dispatches, err := w.store.ClaimDueDeliveries(now, now.Add(w.lease), w.batchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "ошибка выборки доставок вебхуков", "error", err)
+		return 0
+	}
+
+	// Партнеры независимы друг от друга, поэтому медленный получатель не задерживает остальных
+	var wg sync.WaitGroup
+	for _, dispatch := range dispatches {
+		wg.Add(1)
+		go func(dispatch *models.WebhookDispatch) {
+			defer wg.Done()
+			w.deliver(ctx, dispatch)
+		}(dispatch)
+	}
+	wg.Wait()
+
+	return len(dispatches)
+}
+
+func (w *WebhookDispatcher) deliver(ctx context.Context, dispatch *models.WebhookDispatch) {
+	delivery := dispatch.Delivery
+	started := w.now()
+	statusCode, sendErr := w.sender.Send(ctx, dispatch)
+	if sendErr != nil && ctx.Err() != nil {
+		// Остановка сервиса не считается попыткой: доставка вернется после истечения аренды
+		return
+	}
+
+	attempt := models.WebhookAttempt{
+		Number:      delivery.AttemptCount + 1,
+		AttemptedAt: started,
+		DurationMs:  w.now().Sub(started).Milliseconds(),
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	delivery.AttemptCount = attempt.Number
+
+	logArgs := []any{"delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID, "attempt", attempt.Number}
+	switch {
+	case sendErr == nil:
+		deliveredAt := w.now()
+		delivery.Status = models.WebhookDelivered
+		delivery.DeliveredAt = &deliveredAt
+		delivery.NextAttemptAt = nil
+		metrics.WebhookDeliveriesTotal.WithLabelValues("delivered").Inc()
+	case w.policy.Exhausted(attempt.Number):
+		attempt.Error = sendErr.Error()
+		delivery.Status = models.WebhookDead
+		delivery.NextAttemptAt = nil
+		metrics.WebhookDeliveriesTotal.WithLabelValues("dead").Inc()
+		slog.ErrorContext(ctx, "вебхук не доставлен, попытки исчерпаны", append(logArgs, "error", sendErr)...)
+	default:
+		attempt.Error = sendErr.Error()
+		nextAttemptAt := w.now().Add(w.policy.Delay(attempt.Number))
+		delivery.NextAttemptAt = &nextAttemptAt
+		metrics.WebhookDeliveriesTotal.WithLabelValues("retry").Inc()
+		slog.WarnContext(ctx, "ошибка доставки вебхука, будет повтор", append(logArgs, "error", sendErr, "next_attempt_at", nextAttemptAt)...)
+	}
+
+	if err := w.store.RecordAttempt(delivery, attempt); err != nil {
+		slog.ErrorContext(ctx, "ошибка сохранения попытки доставки вебхука", append(logArgs, "error", err)...)
+	}
+}
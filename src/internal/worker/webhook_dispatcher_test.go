@@ -0,0 +1,114 @@
+package worker_test
+
+import (
+	"avito-backend/src/internal/domain/models"
+	"avito-backend/src/internal/webhook"
+	"avito-backend/src/internal/worker"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhookStore struct {
+	mu         sync.Mutex
+	dispatches []*models.WebhookDispatch
+	recorded   map[uuid.UUID]models.WebhookAttempt
+}
+
+func (f *fakeWebhookStore) ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.WebhookDispatch, error) {
+	return f.dispatches, nil
+}
+
+func (f *fakeWebhookStore) RecordAttempt(delivery *models.WebhookDelivery, attempt models.WebhookAttempt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recorded[delivery.ID] = attempt
+	return nil
+}
+
+type fakeWebhookSender struct {
+	responses map[string]int
+}
+
+func (s *fakeWebhookSender) Send(ctx context.Context, dispatch *models.WebhookDispatch) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	code := s.responses[dispatch.URL]
+	if code == 0 {
+		return 0, errors.New("connection refused")
+	}
+	if code >= 300 {
+		return code, errors.New("unexpected status")
+	}
+	return code, nil
+}
+
+func newDispatch(url string, attemptCount int) *models.WebhookDispatch {
+	return &models.WebhookDispatch{
+		Delivery: &models.WebhookDelivery{ID: uuid.New(), Status: models.WebhookPending, AttemptCount: attemptCount},
+		URL:      url,
+	}
+}
+
+func TestWebhookDispatcher_RunOnce(t *testing.T) {
+	delivered := newDispatch("https://ok.example", 0)
+	retried := newDispatch("https://down.example", 1)
+	dead := newDispatch("https://broken.example", 2)
+
+	store := &fakeWebhookStore{
+		dispatches: []*models.WebhookDispatch{delivered, retried, dead},
+		recorded:   make(map[uuid.UUID]models.WebhookAttempt),
+	}
+	sender := &fakeWebhookSender{responses: map[string]int{
+		"https://ok.example":     200,
+		"https://broken.example": 500,
+	}}
+	policy := webhook.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	dispatcher := worker.NewWebhookDispatcher(store, sender, policy, time.Second, 10, time.Minute)
+	before := time.Now()
+
+	processed := dispatcher.RunOnce(context.Background())
+
+	assert.Equal(t, 3, processed)
+
+	assert.Equal(t, models.WebhookDelivered, delivered.Delivery.Status)
+	assert.NotNil(t, delivered.Delivery.DeliveredAt)
+	assert.Equal(t, 200, *store.recorded[delivered.Delivery.ID].StatusCode)
+
+	// Вторая неудача подряд: задержка удваивается
+	assert.Equal(t, models.WebhookPending, retried.Delivery.Status)
+	assert.Equal(t, 2, retried.Delivery.AttemptCount)
+	require.NotNil(t, retried.Delivery.NextAttemptAt)
+	assert.WithinDuration(t, before.Add(2*time.Minute), *retried.Delivery.NextAttemptAt, 5*time.Second)
+	assert.Nil(t, store.recorded[retried.Delivery.ID].StatusCode)
+	assert.Equal(t, "connection refused", store.recorded[retried.Delivery.ID].Error)
+
+	assert.Equal(t, models.WebhookDead, dead.Delivery.Status)
+	assert.Equal(t, 3, dead.Delivery.AttemptCount)
+	assert.Nil(t, dead.Delivery.NextAttemptAt)
+	assert.Equal(t, 500, *store.recorded[dead.Delivery.ID].StatusCode)
+}
+
+func TestWebhookDispatcher_RunOnce_ShutdownIsNotAnAttempt(t *testing.T) {
+	dispatch := newDispatch("https://ok.example", 0)
+	store := &fakeWebhookStore{
+		dispatches: []*models.WebhookDispatch{dispatch},
+		recorded:   make(map[uuid.UUID]models.WebhookAttempt),
+	}
+	sender := &fakeWebhookSender{responses: map[string]int{"https://ok.example": 200}}
+	dispatcher := worker.NewWebhookDispatcher(store, sender, webhook.RetryPolicy{MaxAttempts: 3}, time.Second, 10, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dispatcher.RunOnce(ctx)
+
+	assert.Empty(t, store.recorded)
+	assert.Equal(t, 0, dispatch.Delivery.AttemptCount)
+}
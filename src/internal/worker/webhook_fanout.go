@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type WebhookFanOutStore interface {
+	FanOutPendingEvents(limit int) (int, error)
+}
+
+// Раскладывает доменные события из outbox по подпискам вебхуков. Работает отдельно от OutboxRelay,
+// поэтому недоступность внешнего публикатора не останавливает вебхуки
+type WebhookFanOut struct {
+	store     WebhookFanOutStore
+	interval  time.Duration
+	batchSize int
+}
+
+func NewWebhookFanOut(store WebhookFanOutStore, interval time.Duration, batchSize int) *WebhookFanOut {
+	return &WebhookFanOut{
+		store:     store,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+func (w *WebhookFanOut) Run(ctx context.Context) {
+	if w.interval <= 0 || w.batchSize <= 0 {
+		slog.InfoContext(ctx, "раскладка событий по вебхукам отключена")
+		return
+	}
+
+	slog.InfoContext(ctx, "запущена раскладка событий по вебхукам", "interval", w.interval.String(), "batch_size", w.batchSize)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if w.RunOnce(ctx) == w.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "раскладка событий по вебхукам остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Возвращает количество разложенных событий
+func (w *WebhookFanOut) RunOnce(ctx context.Context) int {
+	count, err := w.store.FanOutPendingEvents(w.batchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "ошибка раскладки событий по вебхукам", "error", err)
+		return 0
+	}
+	return count
+}
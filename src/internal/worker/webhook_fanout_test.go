@@ -0,0 +1,33 @@
+package worker_test
+
+import (
+	"avito-backend/src/internal/worker"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeFanOutStore struct {
+	count int
+	err   error
+	limit int
+}
+
+func (f *fakeFanOutStore) FanOutPendingEvents(limit int) (int, error) {
+	f.limit = limit
+	return f.count, f.err
+}
+
+func TestWebhookFanOut_RunOnce(t *testing.T) {
+	store := &fakeFanOutStore{count: 3}
+	fanOut := worker.NewWebhookFanOut(store, time.Second, 50)
+
+	assert.Equal(t, 3, fanOut.RunOnce(context.Background()))
+	assert.Equal(t, 50, store.limit)
+
+	store.err = errors.New("db unavailable")
+	assert.Equal(t, 0, fanOut.RunOnce(context.Background()))
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Запись кеша. Значение хранится сериализованным: так LRU в памяти и внешнее хранилище
+// ведут себя одинаково, а вызывающий не может изменить закешированные данные
+type Entry struct {
+	Value    []byte
+	Tags     []string
+	StoredAt time.Time
+	// Данные могли измениться; запись отдается, только если источник недоступен
+	Stale bool
+}
+
+// Хранилище кеша. Внешняя реализация (например, Redis) должна так же хранить теги записи
+// и помечать устаревшими, а не удалять, записи по тегу
+type Store interface {
+	Get(key string) (Entry, bool)
+	Set(key string, entry Entry)
+	Delete(key string)
+	// Помечает устаревшими записи хотя бы с одним из тегов
+	Invalidate(tags ...string)
+	InvalidateAll()
+}
+
+type lruItem struct {
+	key   string
+	entry Entry
+}
+
+// Хранилище в памяти процесса; при переполнении вытесняется запись, которую дольше всех не читали
+type LRU struct {
+	capacity int
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+	tags  map[string]map[string]struct{}
+}
+
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		tags:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *LRU) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruItem).entry, true
+}
+
+func (c *LRU) Set(key string, entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem{key: key, entry: entry})
+	for _, tag := range entry.Tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+}
+
+func (c *LRU) Invalidate(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.items[key].Value.(*lruItem).entry.Stale = true
+		}
+	}
+}
+
+func (c *LRU) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		element.Value.(*lruItem).entry.Stale = true
+	}
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Вызывается под c.mu
+func (c *LRU) remove(element *list.Element) {
+	item := element.Value.(*lruItem)
+	for _, tag := range item.entry.Tags {
+		delete(c.tags[tag], item.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+	delete(c.items, item.key)
+	c.order.Remove(element)
+}
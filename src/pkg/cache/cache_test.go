@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	lru := NewLRU(2)
+	lru.Set("a", Entry{Value: []byte("1"), Tags: []string{"pvz:1"}})
+	lru.Set("b", Entry{Value: []byte("2")})
+
+	_, ok := lru.Get("a")
+	require.True(t, ok)
+
+	lru.Set("c", Entry{Value: []byte("3")})
+	assert.Equal(t, 2, lru.Len())
+
+	_, ok = lru.Get("b")
+	assert.False(t, ok, "вытесняется запись, которую дольше всех не читали")
+	_, ok = lru.Get("a")
+	assert.True(t, ok)
+}
+
+func TestLRU_Invalidate(t *testing.T) {
+	storedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	lru := NewLRU(10)
+	lru.Set("page-1", Entry{Value: []byte("1"), Tags: []string{"pvz:1", "pvz:2"}, StoredAt: storedAt})
+	lru.Set("page-2", Entry{Value: []byte("2"), Tags: []string{"pvz:3"}, StoredAt: storedAt})
+
+	lru.Invalidate("pvz:2", "pvz:unknown")
+
+	entry, ok := lru.Get("page-1")
+	require.True(t, ok, "устаревшая запись остается как запасная")
+	assert.True(t, entry.Stale)
+	assert.Equal(t, storedAt, entry.StoredAt)
+
+	entry, _ = lru.Get("page-2")
+	assert.False(t, entry.Stale)
+
+	lru.Set("page-1", Entry{Value: []byte("1"), Tags: []string{"pvz:1"}})
+	lru.Invalidate("pvz:2")
+	entry, _ = lru.Get("page-1")
+	assert.False(t, entry.Stale, "после перезаписи старые теги записи не действуют")
+
+	lru.InvalidateAll()
+	entry, _ = lru.Get("page-2")
+	assert.True(t, entry.Stale)
+
+	lru.Delete("page-2")
+	_, ok = lru.Get("page-2")
+	assert.False(t, ok)
+}
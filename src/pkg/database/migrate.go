@@ -1,10 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"regexp"
+	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -37,3 +41,36 @@ func RunMigrations(db *sql.DB, migrationsPath string) error {
 	log.Println("Миграции успешно применены")
 	return nil
 }
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.*\.up\.sql$`)
+
+// Номер последней миграции в каталоге, до которого должна быть накатана база
+func LatestMigrationVersion(migrationsPath string) (uint, error) {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось прочитать каталог миграций: %w", err)
+	}
+
+	var latest uint64
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("неверный номер миграции %s: %w", entry.Name(), err)
+		}
+		latest = max(latest, version)
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("в каталоге %s нет миграций", migrationsPath)
+	}
+	return uint(latest), nil
+}
+
+// Текущая версия схемы из таблицы golang-migrate; dirty означает, что миграция упала посередине
+func MigrationVersion(ctx context.Context, db *sql.DB) (version uint, dirty bool, err error) {
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return version, dirty, err
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestMigrationVersion(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"000001_init.up.sql", "000001_init.down.sql",
+		"000012_add_index.up.sql", "000012_add_index.down.sql",
+		"000003_add_column.up.sql", "README.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	version, err := LatestMigrationVersion(dir)
+	require.NoError(t, err)
+	assert.Equal(t, uint(12), version)
+
+	_, err = LatestMigrationVersion(t.TempDir())
+	assert.Error(t, err, "пустой каталог")
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// Только для pgx: сколько соединений держать открытыми и сколько подготовленных запросов кешировать на соединении
+	MinConns               int
+	StatementCacheCapacity int
+}
+
+func ConfigurePool(db *sql.DB, cfg PoolConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+// Открывает пул pgx и проверяет соединение с базой
+func OpenPgxPool(ctx context.Context, databaseURL string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	pool, err := NewPgxPool(databaseURL, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
+	}
+
+	return pool, nil
+}
+
+// Создает пул pgx, в котором запросы подготавливаются один раз на соединение и дальше берутся из кеша.
+// Соединения открываются при первом запросе, поэтому недоступная реплика не мешает запуску
+func NewPgxPool(databaseURL string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось разобрать адрес базы данных: %w", err)
+	}
+
+	// У pgxpool нет пула без ограничения, поэтому 0 оставляет его значение по умолчанию
+	if cfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	}
+	poolConfig.MinConns = int32(cfg.MinConns)
+	if cfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	}
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать пул соединений: %w", err)
+	}
+	return pool, nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"avito-backend/src/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Общая часть pgxpool.Pool и pgx.Tx
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var ErrReplicaNotStreaming = errors.New("replica is not streaming from primary")
+
+// Подключена ли реплика к primary и ее отставание в секундах. Если все полученные изменения
+// уже применены, подключенная реплика считается актуальной, даже если на primary давно не было
+// записи. У отключенной реплики полученное и примененное совпадают, поэтому без проверки
+// WAL receiver она выглядела бы актуальной. Статус виден только с правами pg_read_all_stats,
+// без них достаточно того, что процесс WAL receiver запущен
+const replicaLagSQL = `SELECT EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE COALESCE(status, 'streaming') = 'streaming'), ` +
+	`CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 ` +
+	`ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END::float8`
+
+type Replica struct {
+	Name string
+	DB   Querier
+}
+
+type replicaState struct {
+	Replica
+	available atomic.Bool
+}
+
+// Распределяет чтение по репликам, отставание которых не больше maxLag. Реплика недоступна
+// до первой успешной проверки; если доступных нет, чтение идет в primary
+type ReplicaRouter struct {
+	primary  Querier
+	replicas []*replicaState
+	maxLag   time.Duration
+	timeout  time.Duration
+	next     atomic.Uint64
+}
+
+func NewReplicaRouter(primary Querier, replicas []Replica, maxLag time.Duration) *ReplicaRouter {
+	router := &ReplicaRouter{
+		primary: primary,
+		maxLag:  maxLag,
+		timeout: 2 * time.Second,
+	}
+	for _, replica := range replicas {
+		router.replicas = append(router.replicas, &replicaState{Replica: replica})
+	}
+	return router
+}
+
+// Доступные реплики выбираются по очереди
+func (r *ReplicaRouter) Reader() Querier {
+	available := make([]*replicaState, 0, len(r.replicas))
+	for _, replica := range r.replicas {
+		if replica.available.Load() {
+			available = append(available, replica)
+		}
+	}
+	if len(available) == 0 {
+		return r.primary
+	}
+	return available[r.next.Add(1)%uint64(len(available))].DB
+}
+
+// Измеряет отставание всех реплик и обновляет их доступность
+func (r *ReplicaRouter) Check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		lag, err := r.lag(ctx, replica.DB)
+		available := err == nil && lag <= r.maxLag
+
+		if err == nil {
+			metrics.DBReplicaLagSeconds.WithLabelValues(replica.Name).Set(lag.Seconds())
+		}
+		if available {
+			metrics.DBReplicaAvailable.WithLabelValues(replica.Name).Set(1)
+		} else {
+			metrics.DBReplicaAvailable.WithLabelValues(replica.Name).Set(0)
+		}
+
+		if replica.available.Swap(available) == available {
+			continue
+		}
+		switch {
+		case available:
+			log.Printf("Реплика %s снова используется для чтения, отставание %s", replica.Name, lag)
+		case err != nil:
+			log.Printf("Реплика %s исключена из чтения: %v", replica.Name, err)
+		default:
+			log.Printf("Реплика %s исключена из чтения: отставание %s больше %s", replica.Name, lag, r.maxLag)
+		}
+	}
+}
+
+func (r *ReplicaRouter) lag(ctx context.Context, db Querier) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var streaming bool
+	var seconds float64
+	if err := db.QueryRow(ctx, replicaLagSQL).Scan(&streaming, &seconds); err != nil {
+		return 0, err
+	}
+	if !streaming {
+		return 0, ErrReplicaNotStreaming
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Проверяет реплики сразу и затем каждые interval, пока не отменен ctx
+func (r *ReplicaRouter) Run(ctx context.Context, interval time.Duration) {
+	r.Check(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Check(ctx)
+		}
+	}
+}
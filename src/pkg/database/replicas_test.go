@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lagQuery = regexp.QuoteMeta(`pg_stat_wal_receiver`)
+
+func newMockPool(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(mock.Close)
+	return mock
+}
+
+func expectLag(mock pgxmock.PgxPoolIface, seconds float64) {
+	mock.ExpectQuery(lagQuery).WillReturnRows(pgxmock.NewRows([]string{"streaming", "lag"}).AddRow(true, seconds))
+}
+
+func TestReplicaRouter(t *testing.T) {
+	primary := newMockPool(t)
+	fresh := newMockPool(t)
+	lagging := newMockPool(t)
+	broken := newMockPool(t)
+
+	router := NewReplicaRouter(primary, []Replica{
+		{Name: "fresh", DB: fresh},
+		{Name: "lagging", DB: lagging},
+		{Name: "broken", DB: broken},
+	}, 5*time.Second)
+
+	assert.True(t, router.Reader() == primary, "до первой проверки реплики не используются")
+
+	expectLag(fresh, 1.5)
+	expectLag(lagging, 30)
+	broken.ExpectQuery(lagQuery).WillReturnError(errors.New("connection refused"))
+	router.Check(context.Background())
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, router.Reader() == fresh)
+	}
+
+	expectLag(fresh, 6)
+	expectLag(lagging, 30)
+	broken.ExpectQuery(lagQuery).WillReturnError(errors.New("connection refused"))
+	router.Check(context.Background())
+
+	assert.True(t, router.Reader() == primary, "без доступных реплик чтение идет в primary")
+
+	for _, mock := range []pgxmock.PgxPoolIface{fresh, lagging, broken} {
+		require.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestReplicaRouter_RoundRobin(t *testing.T) {
+	primary := newMockPool(t)
+	first := newMockPool(t)
+	second := newMockPool(t)
+
+	router := NewReplicaRouter(primary, []Replica{{Name: "first", DB: first}, {Name: "second", DB: second}}, time.Second)
+	expectLag(first, 0)
+	expectLag(second, 0)
+	router.Check(context.Background())
+
+	readers := map[Querier]int{}
+	for i := 0; i < 4; i++ {
+		readers[router.Reader()]++
+	}
+	assert.Equal(t, map[Querier]int{first: 2, second: 2}, readers)
+}
+
+func TestReplicaRouter_Disconnected(t *testing.T) {
+	primary := newMockPool(t)
+	replica := newMockPool(t)
+
+	router := NewReplicaRouter(primary, []Replica{{Name: "replica", DB: replica}}, 5*time.Second)
+	expectLag(replica, 0)
+	router.Check(context.Background())
+	require.True(t, router.Reader() == replica)
+
+	// Реплика потеряла связь с primary: новых изменений нет, полученное совпадает с примененным
+	replica.ExpectQuery(lagQuery).WillReturnRows(pgxmock.NewRows([]string{"streaming", "lag"}).AddRow(false, 0.0))
+	router.Check(context.Background())
+
+	assert.True(t, router.Reader() == primary, "отключенная реплика не считается актуальной")
+	require.NoError(t, replica.ExpectationsWereMet())
+}
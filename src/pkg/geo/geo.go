@@ -0,0 +1,64 @@
+package geo
+
+import "math"
+
+const EarthRadiusKm = 6371.0
+
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+type BoundingBox struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
+}
+
+func (p Point) IsValid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// Расстояние по большому кругу (формула гаверсинусов) в километрах
+func DistanceKm(a, b Point) float64 {
+	lat1 := toRadians(a.Lat)
+	lat2 := toRadians(b.Lat)
+	dLat := toRadians(b.Lat - a.Lat)
+	dLon := toRadians(b.Lon - a.Lon)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Прямоугольник, гарантированно содержащий круг радиуса radiusKm. Нужен для
+// грубого отбора по индексу, точное расстояние считается через DistanceKm.
+// Рядом с полюсами и линией перемены дат долгота не ограничивается.
+func BoundingBoxAround(center Point, radiusKm float64) BoundingBox {
+	dLat := radiusKm / EarthRadiusKm * 180 / math.Pi
+
+	box := BoundingBox{
+		MinLat: math.Max(-90, center.Lat-dLat),
+		MaxLat: math.Min(90, center.Lat+dLat),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+
+	cosLat := math.Cos(toRadians(center.Lat))
+	if cosLat < 1e-6 {
+		return box
+	}
+
+	dLon := dLat / cosLat
+	if center.Lon-dLon < -180 || center.Lon+dLon > 180 {
+		return box
+	}
+
+	box.MinLon = center.Lon - dLon
+	box.MaxLon = center.Lon + dLon
+	return box
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,69 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        Point
+		b        Point
+		expected float64
+		delta    float64
+	}{
+		{
+			name:     "Same point",
+			a:        Point{Lat: 55.7558, Lon: 37.6173},
+			b:        Point{Lat: 55.7558, Lon: 37.6173},
+			expected: 0,
+			delta:    1e-9,
+		},
+		{
+			name:     "Moscow to Saint Petersburg",
+			a:        Point{Lat: 55.7558, Lon: 37.6173},
+			b:        Point{Lat: 59.9343, Lon: 30.3351},
+			expected: 634,
+			delta:    2,
+		},
+		{
+			name:     "Few metres apart",
+			a:        Point{Lat: 55.7558, Lon: 37.6173},
+			b:        Point{Lat: 55.75589, Lon: 37.6173},
+			expected: 0.01,
+			delta:    0.001,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, DistanceKm(tt.a, tt.b), tt.delta)
+		})
+	}
+}
+
+func TestBoundingBoxAround(t *testing.T) {
+	center := Point{Lat: 55.7558, Lon: 37.6173}
+	box := BoundingBoxAround(center, 10)
+
+	assert.Less(t, box.MinLat, center.Lat)
+	assert.Greater(t, box.MaxLat, center.Lat)
+	assert.Less(t, box.MinLon, center.Lon)
+	assert.Greater(t, box.MaxLon, center.Lon)
+
+	edge := Point{Lat: center.Lat, Lon: box.MaxLon}
+	assert.InDelta(t, 10, DistanceKm(center, edge), 0.1)
+
+	polar := BoundingBoxAround(Point{Lat: 90, Lon: 0}, 10)
+	assert.Equal(t, -180.0, polar.MinLon)
+	assert.Equal(t, 180.0, polar.MaxLon)
+	assert.Equal(t, 90.0, polar.MaxLat)
+}
+
+func TestPoint_IsValid(t *testing.T) {
+	assert.True(t, Point{Lat: 0, Lon: 0}.IsValid())
+	assert.False(t, Point{Lat: 91, Lon: 0}.IsValid())
+	assert.False(t, Point{Lat: 0, Lon: -181}.IsValid())
+}
@@ -8,55 +8,118 @@ import (
 )
 
 type Claims struct {
-	Role string `json:"role"`
+	Role   string `json:"role"`
+	UserID string `json:"userId,omitempty"`
+	// Версия токенов пользователя; смена пароля увеличивает ее и отзывает выданные ранее токены
+	TokenVersion int `json:"ver,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Подписывает токены общим секретом HS256 или, если задан набор ключей, RS256/EdDSA с kid
 type TokenManager struct {
 	signingKey string
-	duration   string
+	duration   time.Duration
+	keys       *KeySet
+	now        func() time.Time
 }
 
-func NewTokenManager(signingKey string, duration string) *TokenManager {
+// Устаревший режим HS256: проверить токен может только тот, кто знает секрет
+func NewTokenManager(signingKey string, duration time.Duration) *TokenManager {
 	return &TokenManager{
 		signingKey: signingKey,
 		duration:   duration,
+		now:        time.Now,
 	}
 }
 
-func (m *TokenManager) GenerateToken(role string) (string, error) {
-	duration, err := time.ParseDuration(m.duration)
-	if err != nil {
-		return "", err
+func NewKeySetTokenManager(keys *KeySet, duration time.Duration) *TokenManager {
+	return &TokenManager{
+		duration: duration,
+		keys:     keys,
+		now:      time.Now,
 	}
+}
+
+func (m *TokenManager) GenerateToken(role string) (string, error) {
+	return m.GenerateUserToken("", role, 0)
+}
 
+// Токен с идентификатором пользователя, выдается при обычном логине
+func (m *TokenManager) GenerateUserToken(userID string, role string, tokenVersion int) (string, error) {
+	now := m.now()
 	claims := Claims{
-		Role: role,
+		Role:         role,
+		UserID:       userID,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.duration)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.signingKey))
+	if m.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(m.signingKey))
+	}
+
+	key, ok := m.keys.signingKey(now)
+	if !ok {
+		return "", fmt.Errorf("no active jwt signing key")
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
 }
 
 func (m *TokenManager) ValidateToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+	claims, err := m.ParseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.Role, nil
+}
+
+func (m *TokenManager) ParseToken(tokenString string) (*Claims, error) {
+	// Сроки проверяются по часам менеджера, а не глобальным jwt.TimeFunc
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, m.verificationKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	now := m.now()
+	if !claims.VerifyExpiresAt(now, false) {
+		return nil, fmt.Errorf("token is expired")
+	}
+	if !claims.VerifyIssuedAt(now, false) || !claims.VerifyNotBefore(now, false) {
+		return nil, fmt.Errorf("token used before issued")
+	}
+
+	return claims, nil
+}
+
+func (m *TokenManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if m.keys == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(m.signingKey), nil
-	})
-
-	if err != nil {
-		return "", err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims.Role, nil
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys.verificationKey(kid, m.now())
+	if !ok {
+		return nil, fmt.Errorf("unknown or retired jwt kid %q", kid)
 	}
-
-	return "", fmt.Errorf("invalid token claims")
+	// Алгоритм берется из ключа, а не из заголовка токена
+	if token.Method.Alg() != key.Algorithm() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
 }
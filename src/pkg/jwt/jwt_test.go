@@ -8,34 +8,27 @@ import (
 )
 
 func TestNewTokenManager(t *testing.T) {
-	manager := NewTokenManager("test-key", "1h")
+	manager := NewTokenManager("test-key", time.Hour)
 	assert.NotNil(t, manager)
 	assert.Equal(t, "test-key", manager.signingKey)
-	assert.Equal(t, "1h", manager.duration)
+	assert.Equal(t, time.Hour, manager.duration)
 }
 
 func TestTokenManager_GenerateToken(t *testing.T) {
 	tests := []struct {
 		name       string
 		signingKey string
-		duration   string
+		duration   time.Duration
 		role       string
 		wantErr    bool
 	}{
 		{
 			name:       "Success generate token",
 			signingKey: "test-key",
-			duration:   "1h",
+			duration:   time.Hour,
 			role:       "admin",
 			wantErr:    false,
 		},
-		{
-			name:       "Invalid duration",
-			signingKey: "test-key",
-			duration:   "invalid",
-			role:       "admin",
-			wantErr:    true,
-		},
 	}
 
 	for _, tt := range tests {
@@ -56,7 +49,7 @@ func TestTokenManager_GenerateToken(t *testing.T) {
 
 func TestTokenManager_ValidateToken(t *testing.T) {
 	signingKey := "test-key"
-	duration := "1h"
+	duration := time.Hour
 	manager := NewTokenManager(signingKey, duration)
 
 	tests := []struct {
@@ -78,7 +71,7 @@ func TestTokenManager_ValidateToken(t *testing.T) {
 		{
 			name: "Expired token",
 			setupToken: func() string {
-				expiredManager := NewTokenManager(signingKey, "-1h")
+				expiredManager := NewTokenManager(signingKey, -time.Hour)
 				token, _ := expiredManager.GenerateToken("admin")
 				return token
 			},
@@ -133,8 +126,28 @@ func TestTokenManager_ValidateToken(t *testing.T) {
 	}
 }
 
+func TestTokenManager_ParseToken(t *testing.T) {
+	manager := NewTokenManager("test-key", time.Hour)
+
+	token, err := manager.GenerateUserToken("user-1", "employee", 3)
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "employee", claims.Role)
+	assert.Equal(t, 3, claims.TokenVersion)
+
+	token, err = manager.GenerateToken("moderator")
+	assert.NoError(t, err)
+
+	claims, err = manager.ParseToken(token)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.UserID)
+}
+
 func TestTokenManager_TokenExpiration(t *testing.T) {
-	manager := NewTokenManager("test-key", "1s")
+	manager := NewTokenManager("test-key", time.Second)
 	token, err := manager.GenerateToken("admin")
 	assert.NoError(t, err)
 
@@ -0,0 +1,180 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/yaml.v3"
+)
+
+const minRSAKeyBits = 2048
+
+// Ключ подписи; алгоритм определяется типом ключа: RSA — RS256, Ed25519 — EdDSA
+type Key struct {
+	ID         string
+	ActiveFrom time.Time
+	method     jwt.SigningMethod
+	private    crypto.PrivateKey
+	public     crypto.PublicKey
+}
+
+func (k Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Набор ключей с плановой ротацией. Подписывает ключ с самым поздним ActiveFrom,
+// который уже наступил. Предыдущий ключ принимается еще grace после активации
+// следующего, чтобы выданные им токены успели истечь
+type KeySet struct {
+	keys  []Key
+	grace time.Duration
+}
+
+func NewKeySet(keys []Key, grace time.Duration) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwt key set is empty")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("jwt key without kid")
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate jwt kid %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	sorted := append([]Key(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom) })
+	return &KeySet{keys: sorted, grace: grace}, nil
+}
+
+func (s *KeySet) signingKey(now time.Time) (Key, bool) {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActiveFrom.After(now) {
+			return s.keys[i], true
+		}
+	}
+	return Key{}, false
+}
+
+// Ключи, которыми можно проверить токен: текущий, еще не активированные
+// и замененные, у которых не истек grace
+func (s *KeySet) verificationKeys(now time.Time) []Key {
+	keys := make([]Key, 0, len(s.keys))
+	for i, key := range s.keys {
+		if i+1 < len(s.keys) && !now.Before(s.keys[i+1].ActiveFrom.Add(s.grace)) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *KeySet) verificationKey(kid string, now time.Time) (Key, bool) {
+	for _, key := range s.verificationKeys(now) {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Разбирает закрытый ключ в PEM: PKCS#8 (RSA или Ed25519) или PKCS#1 (RSA)
+func ParsePrivateKeyPEM(kid string, data []byte, activeFrom time.Time) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("jwt key %q: no PEM block", kid)
+	}
+
+	var private any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("jwt key %q: unsupported PEM block %q", kid, block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("jwt key %q: %w", kid, err)
+	}
+
+	key := Key{ID: kid, ActiveFrom: activeFrom, private: private}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < minRSAKeyBits {
+			return Key{}, fmt.Errorf("jwt key %q: RSA key must be at least %d bits", kid, minRSAKeyBits)
+		}
+		key.method = jwt.SigningMethodRS256
+		key.public = &private.PublicKey
+	case ed25519.PrivateKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.public = private.Public()
+	default:
+		return Key{}, fmt.Errorf("jwt key %q: unsupported key type %T", kid, private)
+	}
+
+	return key, nil
+}
+
+// Формат файла:
+//
+//	keys:
+//	  - kid: 2025-01
+//	    file: keys/2025-01.pem
+//	    activeFrom: 2025-01-01T00:00:00Z
+//
+// Пути к ключам считаются от каталога файла
+type keySetFile struct {
+	Keys []struct {
+		KID        string    `yaml:"kid"`
+		File       string    `yaml:"file"`
+		ActiveFrom time.Time `yaml:"activeFrom"`
+	} `yaml:"keys"`
+}
+
+func LoadKeySet(path string, grace time.Duration) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt keys: %w", err)
+	}
+
+	var file keySetFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse jwt keys: %w", err)
+	}
+
+	keys := make([]Key, 0, len(file.Keys))
+	for _, entry := range file.Keys {
+		keyPath := entry.File
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+
+		pemData, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("read jwt key %q: %w", entry.KID, err)
+		}
+
+		key, err := ParsePrivateKeyPEM(entry.KID, pemData, entry.ActiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(keys, grace)
+}
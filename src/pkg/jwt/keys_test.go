@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ed25519PEM(t *testing.T) []byte {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func rsaPEM(t *testing.T, bits int) []byte {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	key, err := ParsePrivateKeyPEM("ed", ed25519PEM(t), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", key.Algorithm())
+
+	key, err = ParsePrivateKeyPEM("rsa", rsaPEM(t, 2048), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", key.Algorithm())
+
+	_, err = ParsePrivateKeyPEM("weak", rsaPEM(t, 1024), time.Time{})
+	assert.Error(t, err, "короткий RSA-ключ")
+
+	_, err = ParsePrivateKeyPEM("garbage", []byte("not a key"), time.Time{})
+	assert.Error(t, err)
+}
+
+func TestKeySetTokenManager_Rotation(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldKey, err := ParsePrivateKeyPEM("2025-01", ed25519PEM(t), start)
+	require.NoError(t, err)
+	newKey, err := ParsePrivateKeyPEM("2025-02", rsaPEM(t, 2048), start.AddDate(0, 1, 0))
+	require.NoError(t, err)
+
+	keySet, err := NewKeySet([]Key{newKey, oldKey}, 24*time.Hour)
+	require.NoError(t, err)
+
+	now := start.Add(time.Hour)
+	manager := NewKeySetTokenManager(keySet, 1000*time.Hour)
+	manager.now = func() time.Time { return now }
+
+	oldToken, err := manager.GenerateUserToken("user-1", "employee", 0)
+	require.NoError(t, err)
+	assert.Len(t, manager.JWKS().Keys, 2, "будущий ключ публикуется заранее")
+
+	// После активации нового ключа старые токены принимаются в течение grace
+	now = newKey.ActiveFrom.Add(time.Hour)
+	newToken, err := manager.GenerateUserToken("user-1", "employee", 0)
+	require.NoError(t, err)
+	claims, err := manager.ParseToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	_, err = manager.ParseToken(oldToken)
+	assert.NoError(t, err)
+
+	now = newKey.ActiveFrom.Add(25 * time.Hour)
+	_, err = manager.ParseToken(oldToken)
+	assert.Error(t, err, "grace истек")
+	jwks := manager.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "2025-02", jwks.Keys[0].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+}
+
+func TestKeySetTokenManager_RejectsLegacyToken(t *testing.T) {
+	key, err := ParsePrivateKeyPEM("k1", ed25519PEM(t), time.Time{})
+	require.NoError(t, err)
+	keySet, err := NewKeySet([]Key{key}, time.Hour)
+	require.NoError(t, err)
+
+	legacyToken, err := NewTokenManager("secret", time.Hour).GenerateToken("employee")
+	require.NoError(t, err)
+
+	_, err = NewKeySetTokenManager(keySet, time.Hour).ParseToken(legacyToken)
+	assert.Error(t, err)
+	assert.Empty(t, NewTokenManager("secret", time.Hour).JWKS().Keys, "секрет HS256 не публикуется")
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "k1.pem"), ed25519PEM(t), 0o600))
+	manifest := filepath.Join(dir, "keys.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("keys:\n  - kid: k1\n    file: k1.pem\n    activeFrom: 2025-01-01T00:00:00Z\n"), 0o600))
+
+	keySet, err := LoadKeySet(manifest, time.Hour)
+	require.NoError(t, err)
+	key, ok := keySet.signingKey(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, "k1", key.ID)
+
+	_, ok = keySet.signingKey(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok, "до активации подписывать нечем")
+
+	_, err = NewKeySet([]Key{key, key}, time.Hour)
+	assert.Error(t, err, "повторяющийся kid")
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"io"
 	"log"
 	"log/slog"
 	"os"
@@ -137,15 +138,25 @@ func (h *MultiHandler) WithGroup(name string) slog.Handler {
 	return &MultiHandler{handlers: handlers}
 }
 
-func InitLogger() {
-	file, err := os.OpenFile("logs/app.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		log.Fatal(err)
+// Пишет логи в stdout и, если задан путь, в файл; format - json или text
+func InitLogger(level slog.Level, format, path string) {
+	options := &slog.HandlerOptions{Level: level}
+	newHandler := func(w io.Writer) slog.Handler {
+		if format == "text" {
+			return slog.NewTextHandler(w, options)
+		}
+		return slog.NewJSONHandler(w, options)
 	}
 
-	fileHandler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})
-	stdoutHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+	handlers := []slog.Handler{newHandler(os.Stdout)}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handlers = append(handlers, newHandler(file))
+	}
 
-	handler := NewHandlerMiddleware(NewMultiHandler(fileHandler, stdoutHandler))
+	handler := NewHandlerMiddleware(NewMultiHandler(handlers...))
 	slog.SetDefault(slog.New(handler))
 }
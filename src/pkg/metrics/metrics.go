@@ -43,4 +43,96 @@ var (
 			Help: "Общее количество добавленных товаров",
 		},
 	)
+
+	ReceptionsAutoClosedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "receptions_auto_closed_total",
+			Help: "Общее количество приёмок, закрытых автоматически по неактивности",
+		},
+	)
+
+	OutboxEventsPublishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Общее количество доставленных доменных событий",
+		},
+		[]string{"event_type"},
+	)
+
+	OutboxPublishFailuresTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Общее количество неудачных попыток доставки доменных событий",
+		},
+	)
+
+	OutboxEventsDeadTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "outbox_events_dead_total",
+			Help: "Общее количество доменных событий, переведенных в dead letter после исчерпания попыток",
+		},
+	)
+
+	WebhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Общее количество попыток доставки вебхуков по результату",
+		},
+		[]string{"result"},
+	)
+
+	EventStreamsActive = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "event_streams_active",
+			Help: "Количество открытых потоков событий ПВЗ",
+		},
+	)
+
+	RateLimitRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limit_rejected_total",
+			Help: "Общее количество запросов, отклоненных из-за превышения лимита",
+		},
+		[]string{"group"},
+	)
+
+	LoginLockoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_lockouts_total",
+			Help: "Общее количество блокировок входа после неудачных попыток",
+		},
+		[]string{"scope"},
+	)
+
+	DBReplicaLagSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_lag_seconds",
+			Help: "Отставание реплики БД при последней проверке",
+		},
+		[]string{"replica"},
+	)
+
+	DBReplicaAvailable = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_available",
+			Help: "Используется ли реплика БД для чтения (1) или исключена из-за отставания или ошибки (0)",
+		},
+		[]string{"replica"},
+	)
+
+	PVZCacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pvz_cache_requests_total",
+			Help: "Общее количество запросов к кешу списка ПВЗ по результату: hit, miss или stale",
+		},
+		[]string{"result"},
+	)
+
+	PVZCacheInvalidationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pvz_cache_invalidations_total",
+			Help: "Общее количество инвалидаций кеша списка ПВЗ по причине",
+		},
+		[]string{"reason"},
+	)
 )
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Снимает статистику пула pgx при каждом сборе метрик; pool различает пулы, если их несколько
+type PgxPoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	newConnsCount        *prometheus.Desc
+	maxLifetimeDestroys  *prometheus.Desc
+	maxIdleDestroys      *prometheus.Desc
+}
+
+func NewPgxPoolCollector(name string, pool *pgxpool.Pool) *PgxPoolCollector {
+	labels := prometheus.Labels{"pool": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+metric, help, nil, labels)
+	}
+
+	return &PgxPoolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Количество соединений, занятых запросами"),
+		idleConns:            desc("idle_conns", "Количество свободных соединений"),
+		totalConns:           desc("total_conns", "Общее количество открытых соединений"),
+		maxConns:             desc("max_conns", "Максимальный размер пула"),
+		acquireCount:         desc("acquire_total", "Общее количество выданных соединений"),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Суммарное время ожидания соединений"),
+		canceledAcquireCount: desc("canceled_acquire_total", "Количество ожиданий соединения, прерванных отменой контекста"),
+		emptyAcquireCount:    desc("empty_acquire_total", "Количество выдач, которым пришлось ждать, потому что свободных соединений не было"),
+		newConnsCount:        desc("new_conns_total", "Общее количество открытых соединений за время работы"),
+		maxLifetimeDestroys:  desc("max_lifetime_destroys_total", "Количество соединений, закрытых по DB_CONN_MAX_LIFETIME"),
+		maxIdleDestroys:      desc("max_idle_destroys_total", "Количество соединений, закрытых по DB_CONN_MAX_IDLE_TIME"),
+	}
+}
+
+func (c *PgxPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PgxPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConnsCount, prometheus.CounterValue, float64(stat.NewConnsCount()))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeDestroys, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleDestroys, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Лимит вида "Requests запросов за Per"; нулевой лимит означает отсутствие ограничения
+type Rule struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (r Rule) Enabled() bool {
+	return r.Requests > 0 && r.Per > 0
+}
+
+func (r Rule) String() string {
+	if !r.Enabled() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", r.Requests, r.Per)
+}
+
+// Разбирает лимит в формате "100/1m"; "0" и пустая строка отключают ограничение
+func ParseRule(value string) (Rule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return Rule{}, nil
+	}
+
+	countPart, perPart, found := strings.Cut(value, "/")
+	if !found {
+		return Rule{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<duration>", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(countPart))
+	if err != nil || requests < 0 {
+		return Rule{}, fmt.Errorf("invalid rate limit %q: bad request count", value)
+	}
+
+	per, err := time.ParseDuration(strings.TrimSpace(perPart))
+	if err != nil || per <= 0 {
+		return Rule{}, fmt.Errorf("invalid rate limit %q: bad duration", value)
+	}
+
+	return Rule{Requests: requests, Per: per}, nil
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// Token bucket для каждого ключа: емкость Requests, пополняется равномерно за Per
+type Limiter struct {
+	rule Rule
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewLimiter(rule Rule) *Limiter {
+	return NewLimiterWithClock(rule, time.Now)
+}
+
+func NewLimiterWithClock(rule Rule, now func() time.Time) *Limiter {
+	return &Limiter{
+		rule:    rule,
+		now:     now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *Limiter) Rule() Rule {
+	return l.rule
+}
+
+// Списывает токен для ключа. Если токенов нет, возвращает время до появления следующего
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if !l.rule.Enabled() {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(l.rule.Requests)
+	refillPerSecond := capacity / l.rule.Per.Seconds()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		l.buckets[key] = b
+	} else {
+		elapsed := now.Sub(b.updatedAt).Seconds()
+		b.tokens = math.Min(capacity, b.tokens+elapsed*refillPerSecond)
+		b.updatedAt = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / refillPerSecond * float64(time.Second))
+	return false, wait
+}
+
+// Удаляет ведра, которые успели заполниться полностью: они ничем не отличаются от новых
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.rule.Per {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.updatedAt) >= l.rule.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Значение заголовка Retry-After в целых секундах, не меньше одной
+func RetryAfterSeconds(wait time.Duration) int {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
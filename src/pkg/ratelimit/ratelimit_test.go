@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected Rule
+		wantErr  bool
+	}{
+		{name: "Per minute", value: "100/1m", expected: Rule{Requests: 100, Per: time.Minute}},
+		{name: "With spaces", value: " 5 / 10s ", expected: Rule{Requests: 5, Per: 10 * time.Second}},
+		{name: "Disabled", value: "0", expected: Rule{}},
+		{name: "Empty", value: "", expected: Rule{}},
+		{name: "Missing duration", value: "100", wantErr: true},
+		{name: "Bad count", value: "many/1m", wantErr: true},
+		{name: "Negative count", value: "-1/1m", wantErr: true},
+		{name: "Zero duration", value: "10/0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rule)
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiterWithClock(Rule{Requests: 3, Per: 3 * time.Second}, func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("client")
+		assert.True(t, allowed, "запрос %d должен пройти", i+1)
+	}
+
+	allowed, wait := limiter.Allow("client")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, wait)
+
+	allowed, _ = limiter.Allow("other")
+	assert.True(t, allowed, "у другого ключа собственный лимит")
+
+	now = now.Add(time.Second)
+	allowed, _ = limiter.Allow("client")
+	assert.True(t, allowed, "за секунду восстанавливается один токен")
+
+	allowed, _ = limiter.Allow("client")
+	assert.False(t, allowed)
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	limiter := NewLimiter(Rule{})
+
+	for i := 0; i < 1000; i++ {
+		allowed, _ := limiter.Allow("client")
+		require.True(t, allowed)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, RetryAfterSeconds(0))
+	assert.Equal(t, 1, RetryAfterSeconds(200*time.Millisecond))
+	assert.Equal(t, 2, RetryAfterSeconds(1500*time.Millisecond))
+}
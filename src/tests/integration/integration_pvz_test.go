@@ -5,6 +5,7 @@ import (
 	"avito-backend/src/internal/domain/models"
 	"avito-backend/src/internal/repository"
 	"avito-backend/src/internal/service"
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -53,10 +54,16 @@ func TestPVZFullCycle(t *testing.T) {
 		t.Fatalf("Ошибка при очистке базы данных: %v", err)
 	}
 
-	pvzRepo := repository.NewPVZRepository(db)
+	pool, err := database.OpenPgxPool(context.Background(), dbURL, database.PoolConfig{StatementCacheCapacity: 512})
+	if err != nil {
+		t.Fatalf("Ошибка подключения к БД: %v", err)
+	}
+	defer pool.Close()
+
+	pvzRepo := repository.NewPVZRepository(pool)
 	pvzService := service.NewPVZService(pvzRepo)
 
-	pvz, err := pvzService.Create(string(models.Moscow))
+	pvz, err := pvzService.Create(models.PVZCreate{City: models.Moscow})
 	require.NoError(t, err)
 	require.NotNil(t, pvz)
 	assert.Equal(t, models.Moscow, pvz.City)
@@ -83,7 +90,7 @@ func TestPVZFullCycle(t *testing.T) {
 	assert.Equal(t, models.Closed, closedReception.Status)
 	assert.Equal(t, reception.ID, closedReception.ID)
 
-	pvzs, err := pvzService.GetPVZsWithReceptions(time.Time{}, time.Time{}, 0, 10)
+	pvzs, err := pvzService.GetPVZsWithReceptions(models.PVZFilter{Limit: 10})
 	require.NoError(t, err)
 	require.Len(t, pvzs, 1)
 